@@ -27,6 +27,10 @@ type BartApp struct {
 	multiClass            bool
 	serverTimeoutSeconds  int
 	serverMaxRequestBytes int
+	calibrationFile       string
+	quantizedModelFile    string
+	maxQuantizationError  float64
+	summaryJSON           bool
 }
 
 // NewBartApp returns a new BartApp object, which can be used as either client or server.
@@ -40,6 +44,8 @@ func NewBartApp() *BartApp {
 	app.Commands = []*cli.Command{
 		newServerCommandFor(app),
 		newClientCommandFor(app),
+		newQuantizeCommandFor(app),
+		newSummaryCommandFor(app),
 	}
 	return app
 }
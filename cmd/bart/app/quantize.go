@@ -0,0 +1,164 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"log"
+	"os/user"
+	"path"
+	"path/filepath"
+
+	"github.com/nlpodyssey/spago/cmd/quantizeutils"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/quantization"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/bpetokenizer"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/sentencepiece"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/conditionalgeneration"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/sequenceclassification"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/loader"
+	"github.com/nlpodyssey/spago/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultQuantizedModelFile = "spago_model.int8.bin"
+
+func newQuantizeCommandFor(app *BartApp) *cli.Command {
+	return &cli.Command{
+		Name:  "quantize",
+		Usage: "Quantize the linear layers of a BART model to int8.",
+		Description: "Calibrate the quantization running the model on the sample text (one sentence per line), " +
+			"then write the quantized model to a new file. Rename it to `" + defaultModelFile + "` to use it.",
+		Flags:  newQuantizeCommandFlagsFor(app),
+		Action: newQuantizeCommandActionFor(app),
+	}
+}
+
+func newQuantizeCommandFlagsFor(app *BartApp) []cli.Flag {
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "repo",
+			Usage:       "Specifies the path to the models.",
+			Value:       path.Join(usr.HomeDir, ".spago"),
+			Destination: &app.repo,
+		},
+		&cli.StringFlag{
+			Name:        "model, m",
+			Required:    true,
+			Usage:       "Specifies the model name.",
+			Destination: &app.model,
+		},
+		&cli.StringFlag{
+			Name:        "calibration",
+			Required:    true,
+			Usage:       "Specifies the text file used for the calibration, with one sentence per line.",
+			Destination: &app.calibrationFile,
+		},
+		&cli.StringFlag{
+			Name:        "quantized-model",
+			Usage:       "Specifies the output file, relative to the model path.",
+			Value:       defaultQuantizedModelFile,
+			Destination: &app.quantizedModelFile,
+		},
+		&cli.Float64Flag{
+			Name:        "max-error",
+			Usage:       "Leaves in float the layers whose relative output error exceeds this value (0 disables it).",
+			Destination: &app.maxQuantizationError,
+		},
+	}
+}
+
+func newQuantizeCommandActionFor(app *BartApp) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		if err := pullModel(app); err != nil {
+			return err
+		}
+
+		modelPath := filepath.Join(app.repo, app.model)
+		model, err := loader.Load(modelPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer model.Close()
+
+		run, err := newCalibrationRunner(model, modelPath)
+		if err != nil {
+			return err
+		}
+
+		sentences, err := quantizeutils.ReadLines(app.calibrationFile)
+		if err != nil {
+			return err
+		}
+
+		q := quantization.New(model, quantization.Config{
+			MaxRelativeError: mat.Float(app.maxQuantizationError),
+		})
+		for i, text := range sentences {
+			fmt.Printf("\rCalibrating... %d/%d", i+1, len(sentences))
+			g := ag.NewGraph()
+			if err := run(g, text); err != nil {
+				return err
+			}
+			q.Observe(g)
+			g.Clear()
+		}
+		fmt.Println()
+
+		quantizeutils.PrintReports(q.Quantize())
+
+		outputFile := filepath.Join(modelPath, app.quantizedModelFile)
+		fmt.Printf("Writing the quantized model to `%s`...\n", outputFile)
+		return utils.SerializeToFile(outputFile, model)
+	}
+}
+
+// newCalibrationRunner returns a function which runs the model on the given
+// text, using the tokenizer expected by the model architecture.
+func newCalibrationRunner(model nn.Model, modelPath string) (func(g *ag.Graph, text string) error, error) {
+	switch model.(type) {
+	case *conditionalgeneration.Model:
+		tokenizer, err := sentencepiece.NewFromModelFolder(modelPath, false)
+		if err != nil {
+			return nil, err
+		}
+		return func(g *ag.Graph, text string) error {
+			proc := nn.ReifyForInference(model, g).(*conditionalgeneration.Model)
+			inputIDs := tokenizer.TokensToIDs(tokenizer.Tokenize(text))
+			inputIDs = append(inputIDs, proc.BART.Config.EosTokenID)
+			proc.Projection.Forward(proc.BART.Process(inputIDs)...)
+			return nil
+		}, nil
+	case *sequenceclassification.Model, *bart.Model:
+		tokenizer, err := bpetokenizer.NewFromModelFolder(modelPath)
+		if err != nil {
+			return nil, err
+		}
+		return func(g *ag.Graph, text string) error {
+			encoded, err := tokenizer.Encode(text)
+			if err != nil {
+				return err
+			}
+			inputIDs := append(append([]int{0}, encoded.IDs...), 2) // <s> ... </s>
+			switch proc := nn.ReifyForInference(model, g).(type) {
+			case *sequenceclassification.Model:
+				proc.Classify(inputIDs)
+			case *bart.Model:
+				proc.Process(inputIDs)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("bart: invalid model type %T", model)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"log"
+	"os/user"
+	"path"
+	"path/filepath"
+
+	"github.com/nlpodyssey/spago/cmd/summaryutils"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/loader"
+	"github.com/urfave/cli/v2"
+)
+
+func newSummaryCommandFor(app *BartApp) *cli.Command {
+	return &cli.Command{
+		Name:        "summary",
+		Usage:       "Print the sub-models and the parameters of a BART model.",
+		Description: "Print the tree of the sub-models, the shape, type and size of each parameter, and the totals.",
+		Flags:       newSummaryCommandFlagsFor(app),
+		Action:      newSummaryCommandActionFor(app),
+	}
+}
+
+func newSummaryCommandFlagsFor(app *BartApp) []cli.Flag {
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "repo",
+			Usage:       "Specifies the path to the models.",
+			Value:       path.Join(usr.HomeDir, ".spago"),
+			Destination: &app.repo,
+		},
+		&cli.StringFlag{
+			Name:        "model, m",
+			Required:    true,
+			Usage:       "Specifies the model name.",
+			Destination: &app.model,
+		},
+		summaryutils.JSONFlag(&app.summaryJSON),
+	}
+}
+
+func newSummaryCommandActionFor(app *BartApp) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		if err := pullModel(app); err != nil {
+			return err
+		}
+
+		modelPath := filepath.Join(app.repo, app.model)
+		return summaryutils.Print(func() (nn.Model, error) {
+			return loader.Load(modelPath)
+		}, app.summaryJSON)
+	}
+}
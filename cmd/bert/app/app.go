@@ -26,6 +26,10 @@ type BertApp struct {
 	question              string
 	serverTimeoutSeconds  int
 	serverMaxRequestBytes int
+	calibrationFile       string
+	quantizedModelFile    string
+	maxQuantizationError  float64
+	summaryJSON           bool
 }
 
 // NewBertApp returns BertApp objects. The app can be used as both a client and a server.
@@ -39,6 +43,8 @@ func NewBertApp() *BertApp {
 	app.Commands = []*cli.Command{
 		newClientCommandFor(app),
 		newServerCommandFor(app),
+		newQuantizeCommandFor(app),
+		newSummaryCommandFor(app),
 	}
 	return app
 }
@@ -0,0 +1,117 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"log"
+	"os/user"
+	"path"
+	"path/filepath"
+	"runtime"
+
+	"github.com/nlpodyssey/spago/cmd/quantizeutils"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/quantization"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
+	"github.com/nlpodyssey/spago/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultQuantizedModelFile = "spago_model.int8.bin"
+
+func newQuantizeCommandFor(app *BertApp) *cli.Command {
+	return &cli.Command{
+		Name:  "quantize",
+		Usage: "Quantize the linear layers of a BERT model to int8.",
+		Description: "Calibrate the quantization running the model on the sample text (one sentence per line), " +
+			"then write the quantized model to a new file. Rename it to `" + defaultModelFile + "` to use it.",
+		Flags:  newQuantizeCommandFlagsFor(app),
+		Action: newQuantizeCommandActionFor(app),
+	}
+}
+
+func newQuantizeCommandFlagsFor(app *BertApp) []cli.Flag {
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "repo",
+			Usage:       "Specifies the path to the models.",
+			Value:       path.Join(usr.HomeDir, ".spago"),
+			Destination: &app.repo,
+		},
+		&cli.StringFlag{
+			Name:        "model, m",
+			Required:    true,
+			Usage:       "Specifies the model name.",
+			Destination: &app.model,
+		},
+		&cli.StringFlag{
+			Name:        "calibration",
+			Required:    true,
+			Usage:       "Specifies the text file used for the calibration, with one sentence per line.",
+			Destination: &app.calibrationFile,
+		},
+		&cli.StringFlag{
+			Name:        "quantized-model",
+			Usage:       "Specifies the output file, relative to the model path.",
+			Value:       defaultQuantizedModelFile,
+			Destination: &app.quantizedModelFile,
+		},
+		&cli.Float64Flag{
+			Name:        "max-error",
+			Usage:       "Leaves in float the layers whose relative output error exceeds this value (0 disables it).",
+			Destination: &app.maxQuantizationError,
+		},
+	}
+}
+
+func newQuantizeCommandActionFor(app *BertApp) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		modelPath := filepath.Join(app.repo, app.model)
+		model, err := bert.LoadModel(modelPath)
+		if err != nil {
+			log.Fatalf("error during model loading (%v)\n", err)
+		}
+		defer model.Close()
+
+		sentences, err := quantizeutils.ReadLines(app.calibrationFile)
+		if err != nil {
+			return err
+		}
+
+		q := quantization.New(model, quantization.Config{
+			MaxRelativeError: mat.Float(app.maxQuantizationError),
+		})
+		tokenizer := wordpiecetokenizer.New(model.Vocabulary)
+		for i, text := range sentences {
+			fmt.Printf("\rCalibrating... %d/%d", i+1, len(sentences))
+			tokens := tokenizers.GetStrings(tokenizer.Tokenize(text))
+			tokens = append([]string{wordpiecetokenizer.DefaultClassToken}, tokens...)
+			tokens = append(tokens, wordpiecetokenizer.DefaultSequenceSeparator)
+
+			g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
+			proc := nn.ReifyForInference(model, g).(*bert.Model)
+			proc.Pool(proc.Encode(tokens))
+			q.Observe(g)
+			g.Clear()
+		}
+		fmt.Println()
+
+		quantizeutils.PrintReports(q.Quantize())
+
+		outputFile := filepath.Join(modelPath, app.quantizedModelFile)
+		fmt.Printf("Writing the quantized model to `%s`...\n", outputFile)
+		return utils.SerializeToFile(outputFile, model)
+	}
+}
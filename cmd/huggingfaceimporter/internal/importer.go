@@ -6,6 +6,7 @@ package internal
 
 import (
 	"fmt"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/huggingface"
 	"github.com/nlpodyssey/spago/pkg/utils/homedir"
 	"github.com/urfave/cli/v2"
@@ -34,6 +35,7 @@ type ImporterArgs struct {
 	Model     string
 	ModelsURL string
 	Overwrite bool
+	DType     string
 }
 
 // NewImporterArgs builds args object.
@@ -43,6 +45,7 @@ func NewImporterArgs(repo, model, modelsURL string, overwrite bool) *ImporterArg
 		Model:     model,
 		ModelsURL: modelsURL,
 		Overwrite: overwrite,
+		DType:     mat.DTypeFloat.String(),
 	}
 }
 
@@ -76,6 +79,12 @@ func (a *ImporterArgs) BuildFlags() []cli.Flag {
 			Usage:       "overwrite files if they exist already",
 			Destination: &a.Overwrite,
 		},
+		&cli.StringFlag{
+			Name:        "dtype",
+			Usage:       "numeric type of the converted parameters (float32, float16 or bfloat16)",
+			Value:       a.DType,
+			Destination: &a.DType,
+		},
 	}
 }
 
@@ -85,6 +94,10 @@ func (a *ImporterArgs) RunImporter() error {
 	if err != nil {
 		return err
 	}
+	dtype, err := mat.ParseDType(a.DType)
+	if err != nil {
+		return err
+	}
 
 	// Run interactive model selection if a model is not already set.
 	if a.Model == "" {
@@ -110,7 +123,7 @@ func (a *ImporterArgs) RunImporter() error {
 	}
 
 	fmt.Printf("Converting `%s` model...\n", a.Model)
-	return huggingface.NewConverter(a.Repo, a.Model).Convert()
+	return huggingface.NewConverterWithDType(a.Repo, a.Model, dtype).Convert()
 }
 
 // RunImporterCli runs the importer from the command line.
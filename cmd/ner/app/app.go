@@ -35,6 +35,7 @@ type NERApp struct {
 	filterNonEntities     bool
 	serverTimeoutSeconds  int
 	serverMaxRequestBytes int
+	summaryJSON           bool
 }
 
 // NewNERApp returns NerApp objects.
@@ -49,6 +50,7 @@ func NewNERApp() *NERApp {
 		newClientCommandFor(app),
 		newServerCommandFor(app),
 		newConvertCommandFor(app),
+		newSummaryCommandFor(app),
 	}
 	return app
 }
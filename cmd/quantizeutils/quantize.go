@@ -0,0 +1,46 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quantizeutils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/ml/quantization"
+)
+
+// PrintReports prints the reports of the quantization of the linear layers
+// to the standard output, one per line.
+func PrintReports(reports []quantization.Report) {
+	for _, r := range reports {
+		status := "int8"
+		if r.Skipped {
+			status = "float (skipped)"
+		}
+		fmt.Printf("%-60s %5dx%-5d samples=%-4d clip=%.3f error=%.5f %s\n",
+			r.Path, r.Rows, r.Columns, r.Samples, r.MeanClipRatio, r.RelativeError, status)
+	}
+}
+
+// ReadLines returns the non-empty lines of a text file, e.g. the sentences
+// used for the calibration.
+func ReadLines(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
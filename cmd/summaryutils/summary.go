@@ -0,0 +1,42 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package summaryutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/urfave/cli/v2"
+)
+
+// JSONFlag returns the CLI flag to print the summary in JSON format.
+func JSONFlag(destination *bool) cli.Flag {
+	return &cli.BoolFlag{
+		Name:        "json",
+		Usage:       "Prints the summary in JSON format.",
+		Destination: destination,
+	}
+}
+
+// Print loads a model with the given function and prints its summary (see
+// nn.Summary) to the standard output, as an indented table or, if jsonFormat
+// is true, in JSON format. The model is closed before returning.
+func Print(load func() (nn.Model, error), jsonFormat bool) error {
+	model, err := load()
+	if err != nil {
+		return fmt.Errorf("error during model loading: %w", err)
+	}
+	defer model.Close()
+
+	summary := nn.Summary(model)
+	if jsonFormat {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+	return summary.Write(os.Stdout)
+}
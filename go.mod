@@ -14,6 +14,7 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/urfave/cli/v2 v2.3.0
 	golang.org/x/exp v0.0.0-20211111183329-cb5df436b1a8
+	golang.org/x/sys v0.0.0-20211111213525-f221eed1c01e
 	golang.org/x/text v0.3.7
 	google.golang.org/grpc v1.42.0
 	google.golang.org/protobuf v1.27.1
@@ -40,7 +41,6 @@ require (
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	go.opencensus.io v0.23.0 // indirect
 	golang.org/x/net v0.0.0-20211111160137-58aab5ef257a // indirect
-	golang.org/x/sys v0.0.0-20211111213525-f221eed1c01e // indirect
 	google.golang.org/genproto v0.0.0-20211111162719-482062a4217b // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 )
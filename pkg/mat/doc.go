@@ -0,0 +1,21 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:generate go run gen.go
+
+// Package mat re-exports the API of either mat32 or mat64, making the
+// floating-point precision of the whole library selectable at build time.
+//
+// By default, mat.Float is float32 (mat32). Building with the "float64" tag
+// (e.g. "go build -tags float64") switches to float64 (mat64):
+//
+//  go test -tags float64 ./...
+//
+// The sub-packages floatutils, sort and rand follow the same rule.
+//
+// Dense and Sparse matrices can unmarshal values encoded with either
+// precision, so the parameters of a model serialized with float32 can be
+// loaded by a float64 build (and vice versa), and serialized again to convert
+// the model.
+package mat
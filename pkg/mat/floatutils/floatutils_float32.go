@@ -0,0 +1,82 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package floatutils
+
+import (
+	mat32floatutils "github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+)
+
+// EqualApprox returns true if a and b are equal to within reasonable
+// absolute tolerance (hardcoded as 1.0e-04).
+func EqualApprox(a, b float32) bool {
+	return mat32floatutils.EqualApprox(a, b)
+}
+
+// SliceEqualApprox returns true if a and b have the same length and EqualApprox
+// is true for each element pair from a and b.
+func SliceEqualApprox(a, b []float32) bool {
+	return mat32floatutils.SliceEqualApprox(a, b)
+}
+
+// Copy creates and return a copy of the given slice.
+func Copy(in []float32) []float32 {
+	return mat32floatutils.Copy(in)
+}
+
+// FillFloatSlice fills the given slice's elements with value.
+func FillFloatSlice(slice []float32, value float32) {
+	mat32floatutils.FillFloatSlice(slice, value)
+}
+
+// Sign returns +1 if a is positive, -1 if a is negative, or 0 if a is 0.
+func Sign(a float32) int {
+	return mat32floatutils.Sign(a)
+}
+
+// Max returns the maximum value from the given slice, which MUST NOT be empty.
+func Max(v []float32) (m float32) {
+	return mat32floatutils.Max(v)
+}
+
+// Sum returns the sum of all values from the given slice.
+func Sum(v []float32) (s float32) {
+	return mat32floatutils.Sum(v)
+}
+
+// ArgMinMax finds the indices of min and max arguments.
+func ArgMinMax(v []float32) (imin, imax int) {
+	return mat32floatutils.ArgMinMax(v)
+}
+
+// ArgMax finds the index of the max argument.
+func ArgMax(v []float32) int {
+	return mat32floatutils.ArgMax(v)
+}
+
+// ArgMin finds the index of the min argument.
+func ArgMin(v []float32) int {
+	return mat32floatutils.ArgMin(v)
+}
+
+// MakeFloatMatrix returns a new 2-dimensional slice.
+func MakeFloatMatrix(rows, cols int) [][]float32 {
+	return mat32floatutils.MakeFloatMatrix(rows, cols)
+}
+
+// StrToFloatSlice parses a string representation of a slice of float32 values.
+func StrToFloatSlice(str string) ([]float32, error) {
+	return mat32floatutils.StrToFloatSlice(str)
+}
+
+// SoftMax returns the results of the softmax function.
+func SoftMax(v []float32) (sm []float32) {
+	return mat32floatutils.SoftMax(v)
+}
+
+// CumSum computes the cumulative sum of src into dst, and returns dst.
+func CumSum(dst, src []float32) []float32 {
+	return mat32floatutils.CumSum(dst, src)
+}
@@ -0,0 +1,82 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package floatutils
+
+import (
+	mat64floatutils "github.com/nlpodyssey/spago/pkg/mat64/floatutils"
+)
+
+// EqualApprox returns true if a and b are equal to within reasonable
+// absolute tolerance (hardcoded as 1.0e-04).
+func EqualApprox(a, b float64) bool {
+	return mat64floatutils.EqualApprox(a, b)
+}
+
+// SliceEqualApprox returns true if a and b have the same length and EqualApprox
+// is true for each element pair from a and b.
+func SliceEqualApprox(a, b []float64) bool {
+	return mat64floatutils.SliceEqualApprox(a, b)
+}
+
+// Copy creates and return a copy of the given slice.
+func Copy(in []float64) []float64 {
+	return mat64floatutils.Copy(in)
+}
+
+// FillFloatSlice fills the given slice's elements with value.
+func FillFloatSlice(slice []float64, value float64) {
+	mat64floatutils.FillFloatSlice(slice, value)
+}
+
+// Sign returns +1 if a is positive, -1 if a is negative, or 0 if a is 0.
+func Sign(a float64) int {
+	return mat64floatutils.Sign(a)
+}
+
+// Max returns the maximum value from the given slice, which MUST NOT be empty.
+func Max(v []float64) (m float64) {
+	return mat64floatutils.Max(v)
+}
+
+// Sum returns the sum of all values from the given slice.
+func Sum(v []float64) (s float64) {
+	return mat64floatutils.Sum(v)
+}
+
+// ArgMinMax finds the indices of min and max arguments.
+func ArgMinMax(v []float64) (imin, imax int) {
+	return mat64floatutils.ArgMinMax(v)
+}
+
+// ArgMax finds the index of the max argument.
+func ArgMax(v []float64) int {
+	return mat64floatutils.ArgMax(v)
+}
+
+// ArgMin finds the index of the min argument.
+func ArgMin(v []float64) int {
+	return mat64floatutils.ArgMin(v)
+}
+
+// MakeFloatMatrix returns a new 2-dimensional slice.
+func MakeFloatMatrix(rows, cols int) [][]float64 {
+	return mat64floatutils.MakeFloatMatrix(rows, cols)
+}
+
+// StrToFloatSlice parses a string representation of a slice of float64 values.
+func StrToFloatSlice(str string) ([]float64, error) {
+	return mat64floatutils.StrToFloatSlice(str)
+}
+
+// SoftMax returns the results of the softmax function.
+func SoftMax(v []float64) (sm []float64) {
+	return mat64floatutils.SoftMax(v)
+}
+
+// CumSum computes the cumulative sum of src into dst, and returns dst.
+func CumSum(dst, src []float64) []float64 {
+	return mat64floatutils.CumSum(dst, src)
+}
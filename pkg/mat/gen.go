@@ -0,0 +1,272 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// This program generates the precision-dependent files of package mat and
+// of its sub-packages, which re-export the API of the corresponding mat32
+// or mat64 package, depending on the "float64" build tag.
+//
+// Run it with "go generate" from the directory of package mat.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const modulePath = "github.com/nlpodyssey/spago/pkg/"
+
+// packages are the sub-packages of mat32 (and mat64) to re-export, relative
+// to the package directory.
+var packages = []string{
+	"",
+	"floatutils",
+	"sort",
+	"rand",
+	"rand/bernulli",
+	"rand/categorical",
+	"rand/dirichlet",
+	"rand/gamma",
+	"rand/gumbel",
+	"rand/normal",
+	"rand/poisson",
+	"rand/reservoir",
+	"rand/truncnormal",
+	"rand/uniform",
+}
+
+func main() {
+	for _, pkg := range packages {
+		for _, precision := range []struct {
+			src, tag string
+		}{
+			{src: "mat32", tag: "!float64"},
+			{src: "mat64", tag: "float64"},
+		} {
+			if err := generate(pkg, precision.src, precision.tag); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func generate(pkg, src, tag string) error {
+	srcDir := filepath.Join("..", src, pkg)
+	bp, err := build.ImportDir(srcDir, 0)
+	if err != nil {
+		return err
+	}
+
+	// e.g. mat32 or mat32rand, so that it doesn't clash with other imports
+	alias := src
+	if pkg != "" {
+		alias += filepath.Base(pkg)
+	}
+
+	fset := token.NewFileSet()
+	var decls []string
+	imports := map[string]string{} // name -> path
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(srcDir, name), nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		fileImports := map[string]string{}
+		for _, spec := range f.Imports {
+			path := strings.Trim(spec.Path.Value, `"`)
+			name := filepath.Base(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			fileImports[name] = path
+		}
+		for _, decl := range f.Decls {
+			d, err := reexport(fset, alias, decl, fileImports, imports)
+			if err != nil {
+				return fmt.Errorf("%s/%s: %w", srcDir, name, err)
+			}
+			decls = append(decls, d...)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "//go:build %s\n// +build %s\n\n", tag, tag)
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", packageName(pkg))
+	fmt.Fprintf(&buf, "\t%s %q\n", alias, modulePath+filepath.ToSlash(filepath.Join(src, pkg)))
+	var names []string
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if path := imports[name]; filepath.Base(path) == name {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, path)
+		}
+	}
+	fmt.Fprintf(&buf, ")\n")
+	for _, d := range decls {
+		fmt.Fprintf(&buf, "\n%s\n", d)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %w\n%s", srcDir, err, buf.Bytes())
+	}
+	suffix := "float32"
+	if src == "mat64" {
+		suffix = "float64"
+	}
+	filename := filepath.Join(pkg, fmt.Sprintf("%s_%s.go", packageName(pkg), suffix))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, out, 0644)
+}
+
+func packageName(pkg string) string {
+	if pkg == "" {
+		return "mat"
+	}
+	return filepath.Base(pkg)
+}
+
+// reexport returns the declarations re-exporting the exported identifiers
+// declared by decl, in the package imported as srcName. The packages referenced by function
+// signatures are added to imports, translating the paths of mat32 and mat64
+// to the ones of package mat.
+func reexport(fset *token.FileSet, srcName string, decl ast.Decl, fileImports, imports map[string]string) ([]string, error) {
+	var out []string
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.IsExported() {
+					out = append(out, docOf(s.Doc, d)+fmt.Sprintf("type %s = %s.%s", s.Name, srcName, s.Name))
+				}
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					if !name.IsExported() {
+						continue
+					}
+					if d.Tok != token.CONST {
+						return nil, fmt.Errorf("exported variable %s is not supported", name)
+					}
+					out = append(out, docOf(s.Doc, d)+fmt.Sprintf("const %s = %s.%s", name, srcName, name))
+				}
+			}
+		}
+	case *ast.FuncDecl:
+		if d.Recv != nil || !d.Name.IsExported() {
+			return nil, nil
+		}
+		var err error
+		ast.Inspect(d.Type, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.SelectorExpr:
+				if id, ok := x.X.(*ast.Ident); ok {
+					path, ok := fileImports[id.Name]
+					if !ok {
+						err = fmt.Errorf("%s: unknown package %s", d.Name, id.Name)
+						return false
+					}
+					imports[id.Name] = translate(path)
+				}
+				return false
+			case *ast.Ident:
+				if x.Obj != nil && x.Obj.Kind == ast.Typ && !x.IsExported() {
+					err = fmt.Errorf("%s: unexported type %s", d.Name, x.Name)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		params, args := fieldList(fset, d.Type.Params, true)
+		results, _ := fieldList(fset, d.Type.Results, false)
+		if d.Type.Results != nil && (len(d.Type.Results.List) > 1 || len(d.Type.Results.List[0].Names) > 0) {
+			results = "(" + results + ")"
+		}
+		call := fmt.Sprintf("%s.%s(%s)", srcName, d.Name, args)
+		if d.Type.Results != nil {
+			call = "return " + call
+		}
+		out = append(out, docOf(d.Doc, nil)+fmt.Sprintf("func %s(%s) %s {\n\t%s\n}", d.Name, params, results, call))
+	}
+	return out, nil
+}
+
+// fieldList returns the source of a parameter (or result) list, naming the
+// unnamed parameters, and the corresponding call arguments.
+func fieldList(fset *token.FileSet, fields *ast.FieldList, nameParams bool) (string, string) {
+	if fields == nil {
+		return "", ""
+	}
+	var list, args []string
+	for i, field := range fields.List {
+		var typ bytes.Buffer
+		_ = printer.Fprint(&typ, fset, field.Type)
+		names := field.Names
+		if len(names) == 0 && nameParams {
+			names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("p%d", i))}
+		}
+		var ns []string
+		for _, n := range names {
+			ns = append(ns, n.Name)
+			arg := n.Name
+			if _, ok := field.Type.(*ast.Ellipsis); ok {
+				arg += "..."
+			}
+			args = append(args, arg)
+		}
+		if len(ns) > 0 {
+			list = append(list, strings.Join(ns, ", ")+" "+typ.String())
+		} else {
+			list = append(list, typ.String())
+		}
+	}
+	return strings.Join(list, ", "), strings.Join(args, ", ")
+}
+
+func translate(path string) string {
+	for _, src := range []string{"mat32", "mat64"} {
+		prefix := modulePath + src
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return modulePath + "mat" + strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
+
+func docOf(doc *ast.CommentGroup, d *ast.GenDecl) string {
+	if doc == nil && d != nil && len(d.Specs) == 1 {
+		doc = d.Doc
+	}
+	if doc == nil {
+		return ""
+	}
+	var s strings.Builder
+	for _, c := range doc.List {
+		s.WriteString(c.Text)
+		s.WriteString("\n")
+	}
+	return s.String()
+}
@@ -0,0 +1,403 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package mat
+
+import (
+	mat64 "github.com/nlpodyssey/spago/pkg/mat64"
+	"io"
+)
+
+// Dense is a Matrix implementation that uses Float as data type.
+type Dense = mat64.Dense
+
+// NewDense returns a new rows x cols dense matrix populated with a copy of the elements.
+// The elements cannot be nil, panic otherwise. Use NewEmptyDense to initialize an empty matrix.
+func NewDense(rows, cols int, elements []Float) *Dense {
+	return mat64.NewDense(rows, cols, elements)
+}
+
+// NewVecDense returns a new column vector populated with a copy of the elements.
+// The elements cannot be nil, panic otherwise. Use NewEmptyVecDense to initialize an empty matrix.
+func NewVecDense(elements []Float) *Dense {
+	return mat64.NewVecDense(elements)
+}
+
+// NewScalar returns a new 1x1 matrix containing the input value.
+func NewScalar(n Float) *Dense {
+	return mat64.NewScalar(n)
+}
+
+// NewEmptyVecDense returns a new vector of the given size, initialized to zeros.
+func NewEmptyVecDense(size int) *Dense {
+	return mat64.NewEmptyVecDense(size)
+}
+
+// NewEmptyDense returns a new rows x cols matrix initialized to zeros.
+func NewEmptyDense(rows, cols int) *Dense {
+	return mat64.NewEmptyDense(rows, cols)
+}
+
+// OneHotVecDense returns a new one-hot vector of the given size.
+func OneHotVecDense(size int, oneAt int) *Dense {
+	return mat64.OneHotVecDense(size, oneAt)
+}
+
+// NewInitDense returns a new rows x cols dense matrix initialized with a constant value.
+func NewInitDense(rows, cols int, val Float) *Dense {
+	return mat64.NewInitDense(rows, cols, val)
+}
+
+// NewInitVecDense returns a new size x 1 dense matrix initialized with a constant value.
+func NewInitVecDense(size int, val Float) *Dense {
+	return mat64.NewInitVecDense(size, val)
+}
+
+// I a.k.a identity returns square matrix with ones on the diagonal and zeros elsewhere.
+func I(size int) *Dense {
+	return mat64.I(size)
+}
+
+// GetDenseWorkspace returns a *Dense of size r×c and a data slice with a cap that is less than 2*r*c.
+// Warning, the values may not be at zero. If you need a ready-to-use matrix you can call GetEmptyDenseWorkspace().
+func GetDenseWorkspace(r, c int) *Dense {
+	return mat64.GetDenseWorkspace(r, c)
+}
+
+// GetEmptyDenseWorkspace returns a *Dense of size r×c and a data slice with a cap that is less than 2*r*c.
+// The returned matrix is ready-to-use (with all the values set to zeros).
+func GetEmptyDenseWorkspace(r, c int) *Dense {
+	return mat64.GetEmptyDenseWorkspace(r, c)
+}
+
+// ReleaseMatrix checks whether m is a Dense matrix, and, if so, it
+// releases is, otherwise no operation is performed.
+func ReleaseMatrix(m Matrix) {
+	mat64.ReleaseMatrix(m)
+}
+
+// ReleaseDense replaces a used *Dense into the appropriate size
+// workspace pool. ReleaseDense must not be called with a matrix
+// where references to the underlying data slice have been kept.
+func ReleaseDense(w *Dense) {
+	mat64.ReleaseDense(w)
+}
+
+// MarshalBinaryMatrix encodes a Matrix into binary form.
+func MarshalBinaryMatrix(m Matrix, w io.Writer) error {
+	return mat64.MarshalBinaryMatrix(m, w)
+}
+
+// UnmarshalBinaryMatrix decodes a Matrix from binary form.
+func UnmarshalBinaryMatrix(r io.Reader) (Matrix, error) {
+	return mat64.UnmarshalBinaryMatrix(r)
+}
+
+// DType identifies the numeric type used to store the values of a matrix.
+type DType = mat64.DType
+
+// DTypeFloat is the native Float type of the package.
+const DTypeFloat = mat64.DTypeFloat
+
+// DTypeFloat16 is the IEEE 754 half-precision binary floating-point format.
+const DTypeFloat16 = mat64.DTypeFloat16
+
+// DTypeBFloat16 is the bfloat16 (brain floating point) format, that is
+// a float32 truncated to its 16 most significant bits.
+const DTypeBFloat16 = mat64.DTypeBFloat16
+
+// ParseDType returns the DType corresponding to the given name.
+func ParseDType(s string) (DType, error) {
+	return mat64.ParseDType(s)
+}
+
+// Float16bits returns the IEEE 754 half-precision binary representation of f,
+// rounding to the nearest even value. Values too large in magnitude become
+// infinities, values too small become (signed) zeros.
+// The value is first converted to float32.
+func Float16bits(f Float) uint16 {
+	return mat64.Float16bits(f)
+}
+
+// Float16frombits returns the Float value corresponding to the IEEE 754
+// half-precision binary representation b.
+func Float16frombits(b uint16) Float {
+	return mat64.Float16frombits(b)
+}
+
+// BFloat16bits returns the bfloat16 binary representation of f,
+// rounding to the nearest even value.
+// The value is first converted to float32.
+func BFloat16bits(f Float) uint16 {
+	return mat64.BFloat16bits(f)
+}
+
+// BFloat16frombits returns the Float value corresponding to the bfloat16
+// binary representation b.
+func BFloat16frombits(b uint16) Float {
+	return mat64.BFloat16frombits(b)
+}
+
+// HalfDense is a compact representation of a dense matrix, whose values are
+// stored with 16 bits each, according to a half-precision DType.
+//
+// HalfDense is not a Matrix: it is meant to hold values at rest (for example
+// the weights of a model used for inference), which are upcast to a Dense
+// matrix of Float when they are actually needed for computation.
+type HalfDense = mat64.HalfDense
+
+// NewHalfDense returns a new HalfDense, converting the values of the given
+// matrix to the half-precision dtype.
+// It panics if dtype is not a 16-bit floating-point format.
+func NewHalfDense(m Matrix, dtype DType) *HalfDense {
+	return mat64.NewHalfDense(m, dtype)
+}
+
+// Float is the main float type for the mat64 package. It is an alias for float64.
+type Float = mat64.Float
+
+// SmallestNonzeroFloat corresponds to math.SmallestNonzeroFloat64.
+const SmallestNonzeroFloat = mat64.SmallestNonzeroFloat
+
+// Pi mathematical constant.
+const Pi = mat64.Pi
+
+// Pow returns x**y, the base-x exponential of y.
+func Pow(x, y Float) Float {
+	return mat64.Pow(x, y)
+}
+
+// Cos returns the cosine of the radian argument x.
+func Cos(x Float) Float {
+	return mat64.Cos(x)
+}
+
+// Sin returns the sine of the radian argument x.
+func Sin(x Float) Float {
+	return mat64.Sin(x)
+}
+
+// Cosh returns the hyperbolic cosine of x.
+func Cosh(x Float) Float {
+	return mat64.Cosh(x)
+}
+
+// Sinh returns the hyperbolic sine of x.
+func Sinh(x Float) Float {
+	return mat64.Sinh(x)
+}
+
+// Exp returns e**x, the base-e exponential of x.
+func Exp(x Float) Float {
+	return mat64.Exp(x)
+}
+
+// Abs returns the absolute value of x.
+func Abs(x Float) Float {
+	return mat64.Abs(x)
+}
+
+// Sqrt returns the square root of x.
+func Sqrt(x Float) Float {
+	return mat64.Sqrt(x)
+}
+
+// Log returns the natural logarithm of x.
+func Log(x Float) Float {
+	return mat64.Log(x)
+}
+
+// Tan returns the tangent of the radian argument x.
+func Tan(x Float) Float {
+	return mat64.Tan(x)
+}
+
+// Tanh returns the hyperbolic tangent of x.
+func Tanh(x Float) Float {
+	return mat64.Tanh(x)
+}
+
+// Max returns the larger of x or y.
+func Max(x, y Float) Float {
+	return mat64.Max(x, y)
+}
+
+// Inf returns positive infinity if sign >= 0, negative infinity if sign < 0.
+func Inf(sign int) Float {
+	return mat64.Inf(sign)
+}
+
+// IsInf reports whether f is an infinity, according to sign.
+func IsInf(f Float, sign int) bool {
+	return mat64.IsInf(f, sign)
+}
+
+// NaN returns an IEEE 754 “not-a-number” value.
+func NaN() Float {
+	return mat64.NaN()
+}
+
+// Ceil returns the least integer value greater than or equal to x.
+func Ceil(x Float) Float {
+	return mat64.Ceil(x)
+}
+
+// Floor returns the greatest integer value less than or equal to x.
+func Floor(x Float) Float {
+	return mat64.Floor(x)
+}
+
+// Round returns the nearest integer, rounding half away from zero.
+func Round(x Float) Float {
+	return mat64.Round(x)
+}
+
+// The Matrix interface defines set and get methods to access its elements plus a few variants to perform linear algebra
+// operations with other matrices, such as element-wise addition, subtraction, product and matrix-matrix multiplication.
+type Matrix = mat64.Matrix
+
+// ConcatV returns a new Matrix created concatenating the input matrices vertically.
+func ConcatV(vs ...Matrix) Matrix {
+	return mat64.ConcatV(vs...)
+}
+
+// ConcatH returns a new Matrix created concatenating the input matrices horizontally.
+func ConcatH(ms ...Matrix) *Dense {
+	return mat64.ConcatH(ms...)
+}
+
+// Stack returns a new Matrix created concatenating the input vectors horizontally.
+func Stack(vs ...Matrix) Matrix {
+	return mat64.Stack(vs...)
+}
+
+// SameDims returns whether the two matrices have the same number of rows and columns (so also of the same size).
+func SameDims(a, b Matrix) bool {
+	return mat64.SameDims(a, b)
+}
+
+// SameSize returns whether the two matrices have the same size (number of elements).
+func SameSize(a, b Matrix) bool {
+	return mat64.SameSize(a, b)
+}
+
+// VectorsOfSameSize returns whether the two matrices are vector of the same size.
+func VectorsOfSameSize(a, b Matrix) bool {
+	return mat64.VectorsOfSameSize(a, b)
+}
+
+// SqrtMatrix returns a new matrix filled with the sqrt of the values of the input matrix.
+func SqrtMatrix(m Matrix) Matrix {
+	return mat64.SqrtMatrix(m)
+}
+
+// Print performs a simple print of the matrix.
+func Print(a Matrix) {
+	mat64.Print(a)
+}
+
+// Cosine returns the cosine similarity between two not normalized vectors.
+func Cosine(x, y Matrix) Float {
+	return mat64.Cosine(x, y)
+}
+
+// DefaultParallelThreshold is the default minimum number of elements for
+// which the element-wise operations of a Dense matrix are split across
+// multiple goroutines.
+const DefaultParallelThreshold = mat64.DefaultParallelThreshold
+
+// ParallelThreshold returns the minimum number of elements for which the
+// element-wise operations of a Dense matrix are split across multiple
+// goroutines (see SetParallelThreshold).
+func ParallelThreshold() int {
+	return mat64.ParallelThreshold()
+}
+
+// SetParallelThreshold sets the minimum number of elements for which the
+// element-wise operations of a Dense matrix, such as Apply, AddInPlace or
+// ProdInPlace, are split across multiple goroutines (up to GOMAXPROCS).
+// A value lower than or equal to zero disables the parallel execution.
+//
+// Please note that, above the threshold, the functions passed to Apply and
+// ApplyWithAlpha are invoked concurrently.
+func SetParallelThreshold(n int) {
+	mat64.SetParallelThreshold(n)
+}
+
+// TuneParallelThreshold measures the execution time of some element-wise
+// operations on matrices of increasing size, both serially and in parallel,
+// then sets (and returns) the parallel threshold to the smallest size for
+// which the parallel execution turns out to be faster.
+// If the parallel execution is never faster, it is disabled.
+//
+// It takes a few hundred milliseconds, and it is meant to be called once at
+// the beginning of the program, on an otherwise idle machine.
+func TuneParallelThreshold() int {
+	return mat64.TuneParallelThreshold()
+}
+
+// Sparse is the implementation of a sparse matrix that uses Float as data type.
+type Sparse = mat64.Sparse
+
+// NewSparse returns a new rows x cols sparse matrix populated with a copy of the non-zero elements.
+// The elements cannot be nil, panic otherwise. Use NewEmptySparse to initialize an empty matrix.
+func NewSparse(rows, cols int, elements []Float) *Sparse {
+	return mat64.NewSparse(rows, cols, elements)
+}
+
+// NewVecSparse returns a new column sparse vector populated with the non-zero elements.
+// The elements cannot be nil, panic otherwise. Use NewEmptyVecSparse to initialize an empty matrix.
+func NewVecSparse(elements []Float) *Sparse {
+	return mat64.NewVecSparse(elements)
+}
+
+// NewEmptyVecSparse returns a new sparse vector of the given size.
+func NewEmptyVecSparse(size int) *Sparse {
+	return mat64.NewEmptyVecSparse(size)
+}
+
+// NewEmptySparse returns a new rows x cols Sparse matrix.
+func NewEmptySparse(rows, cols int) *Sparse {
+	return mat64.NewEmptySparse(rows, cols)
+}
+
+// Coordinate represents the row I and column J of a Sparse matrix.
+type Coordinate = mat64.Coordinate
+
+// NewSparseFromMap creates a new Sparse matrix from a raw map of values.
+func NewSparseFromMap(rows, cols int, elements map[Coordinate]Float) *Sparse {
+	return mat64.NewSparseFromMap(rows, cols, elements)
+}
+
+// OneHotSparse creates a new one-hot Sparse vector. It panics if oneAt is an
+// invalid index.
+func OneHotSparse(size int, oneAt int) *Sparse {
+	return mat64.OneHotSparse(size, oneAt)
+}
+
+// ExpTo sets dst[i] = Exp(x[i]).
+func ExpTo(dst, x []Float) {
+	mat64.ExpTo(dst, x)
+}
+
+// LogTo sets dst[i] = Log(x[i]).
+func LogTo(dst, x []Float) {
+	mat64.LogTo(dst, x)
+}
+
+// TanhTo sets dst[i] = Tanh(x[i]).
+func TanhTo(dst, x []Float) {
+	mat64.TanhTo(dst, x)
+}
+
+// SigmoidTo sets dst[i] = 1 / (1 + Exp(-x[i])).
+func SigmoidTo(dst, x []Float) {
+	mat64.SigmoidTo(dst, x)
+}
+
+// GELUTo sets dst[i] = 0.5 * x[i] * (1 + Tanh(Sqrt(2/Pi) * (x[i] + 0.044715*x[i]^3))).
+func GELUTo(dst, x []Float) {
+	mat64.GELUTo(dst, x)
+}
@@ -0,0 +1,17 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package bernulli
+
+import (
+	mat32 "github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32bernulli "github.com/nlpodyssey/spago/pkg/mat32/rand/bernulli"
+)
+
+// Distribution creates a new matrix initialized with Bernoulli distribution.
+func Distribution(r, c int, prob float32, generator *rand.LockedRand) mat32.Matrix {
+	return mat32bernulli.Distribution(r, c, prob, generator)
+}
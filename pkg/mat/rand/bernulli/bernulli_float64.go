@@ -0,0 +1,17 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package bernulli
+
+import (
+	mat64 "github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64bernulli "github.com/nlpodyssey/spago/pkg/mat64/rand/bernulli"
+)
+
+// Distribution creates a new matrix initialized with Bernoulli distribution.
+func Distribution(r, c int, prob float64, generator *rand.LockedRand) mat64.Matrix {
+	return mat64bernulli.Distribution(r, c, prob, generator)
+}
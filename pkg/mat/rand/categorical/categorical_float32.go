@@ -0,0 +1,24 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package categorical
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32categorical "github.com/nlpodyssey/spago/pkg/mat32/rand/categorical"
+)
+
+// Categorical is a source of random indices drawn from a discrete probability
+// distribution over the categories [0, len(Probs)).
+// See: https://en.wikipedia.org/wiki/Categorical_distribution.
+type Categorical = mat32categorical.Categorical
+
+// New returns a new Categorical, initialized with the given weights.
+// The weights do not need to sum to one, since they are normalized here;
+// however, they must be non-negative and their sum must be positive,
+// otherwise New panics.
+func New(weights []float32, generator *rand.LockedRand) *Categorical {
+	return mat32categorical.New(weights, generator)
+}
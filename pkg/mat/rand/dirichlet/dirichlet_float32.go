@@ -0,0 +1,22 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package dirichlet
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32dirichlet "github.com/nlpodyssey/spago/pkg/mat32/rand/dirichlet"
+)
+
+// Dirichlet is a source of random probability vectors following the Dirichlet
+// distribution.
+// See: https://en.wikipedia.org/wiki/Dirichlet_distribution.
+type Dirichlet = mat32dirichlet.Dirichlet
+
+// New returns a new Dirichlet, initialized with the given concentration parameters.
+// It panics if alpha is empty or if any of its values is not positive.
+func New(alpha []float32, generator *rand.LockedRand) *Dirichlet {
+	return mat32dirichlet.New(alpha, generator)
+}
@@ -0,0 +1,21 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package gamma
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32gamma "github.com/nlpodyssey/spago/pkg/mat32/rand/gamma"
+)
+
+// Gamma is a source of random numbers following the Gamma distribution.
+// See: https://en.wikipedia.org/wiki/Gamma_distribution.
+type Gamma = mat32gamma.Gamma
+
+// New returns a new Gamma, initialized with the given shape (k) and scale (theta)
+// parameters. It panics if any of them is not positive.
+func New(shape, scale float32, generator *rand.LockedRand) *Gamma {
+	return mat32gamma.New(shape, scale, generator)
+}
@@ -0,0 +1,21 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package gamma
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64gamma "github.com/nlpodyssey/spago/pkg/mat64/rand/gamma"
+)
+
+// Gamma is a source of random numbers following the Gamma distribution.
+// See: https://en.wikipedia.org/wiki/Gamma_distribution.
+type Gamma = mat64gamma.Gamma
+
+// New returns a new Gamma, initialized with the given shape (k) and scale (theta)
+// parameters. It panics if any of them is not positive.
+func New(shape, scale float64, generator *rand.LockedRand) *Gamma {
+	return mat64gamma.New(shape, scale, generator)
+}
@@ -0,0 +1,28 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package gumbel
+
+import (
+	mat32 "github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32gumbel "github.com/nlpodyssey/spago/pkg/mat32/rand/gumbel"
+)
+
+// Gumbel is a source of random numbers following the Gumbel (type-I extreme value)
+// distribution, as used for instance by the Gumbel-Max trick and the Gumbel-Softmax.
+// See: https://en.wikipedia.org/wiki/Gumbel_distribution.
+type Gumbel = mat32gumbel.Gumbel
+
+// New returns a new Gumbel, initialized with the given location (mu) and scale (beta)
+// parameters.
+func New(mu, beta float32, generator *rand.LockedRand) *Gumbel {
+	return mat32gumbel.New(mu, beta, generator)
+}
+
+// Distribution creates a new matrix initialized with Gumbel distribution.
+func Distribution(r, c int, mu, beta float32, generator *rand.LockedRand) mat32.Matrix {
+	return mat32gumbel.Distribution(r, c, mu, beta, generator)
+}
@@ -0,0 +1,28 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package gumbel
+
+import (
+	mat64 "github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64gumbel "github.com/nlpodyssey/spago/pkg/mat64/rand/gumbel"
+)
+
+// Gumbel is a source of random numbers following the Gumbel (type-I extreme value)
+// distribution, as used for instance by the Gumbel-Max trick and the Gumbel-Softmax.
+// See: https://en.wikipedia.org/wiki/Gumbel_distribution.
+type Gumbel = mat64gumbel.Gumbel
+
+// New returns a new Gumbel, initialized with the given location (mu) and scale (beta)
+// parameters.
+func New(mu, beta float64, generator *rand.LockedRand) *Gumbel {
+	return mat64gumbel.New(mu, beta, generator)
+}
+
+// Distribution creates a new matrix initialized with Gumbel distribution.
+func Distribution(r, c int, mu, beta float64, generator *rand.LockedRand) mat64.Matrix {
+	return mat64gumbel.Distribution(r, c, mu, beta, generator)
+}
@@ -0,0 +1,20 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package normal
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64normal "github.com/nlpodyssey/spago/pkg/mat64/rand/normal"
+)
+
+// Normal is a source of normally distributed random numbers.
+type Normal = mat64normal.Normal
+
+// New returns a new Normal, initialized with the given standard deviation and
+// mean parameters.
+func New(std, mean float64, generator *rand.LockedRand) *Normal {
+	return mat64normal.New(std, mean, generator)
+}
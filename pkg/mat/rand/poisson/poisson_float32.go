@@ -0,0 +1,21 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package poisson
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32poisson "github.com/nlpodyssey/spago/pkg/mat32/rand/poisson"
+)
+
+// Poisson is a source of random numbers following the Poisson distribution.
+// See: https://en.wikipedia.org/wiki/Poisson_distribution.
+type Poisson = mat32poisson.Poisson
+
+// New returns a new Poisson, initialized with the given rate (lambda) parameter.
+// It panics if lambda is negative.
+func New(lambda float32, generator *rand.LockedRand) *Poisson {
+	return mat32poisson.New(lambda, generator)
+}
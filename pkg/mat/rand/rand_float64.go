@@ -0,0 +1,51 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package rand
+
+import (
+	mat64 "github.com/nlpodyssey/spago/pkg/mat"
+	mat64rand "github.com/nlpodyssey/spago/pkg/mat64/rand"
+)
+
+// LockedRand is an implementation of rand.Rand that is concurrency-safe.
+// It is just a wrap of the standard rand.Rand with its operations protected by a sync.Mutex.
+type LockedRand = mat64rand.LockedRand
+
+// NewLockedRand creates a new LockedRand that implements all Rand functions that is safe
+// for concurrent use.
+func NewLockedRand(seed uint64) *LockedRand {
+	return mat64rand.NewLockedRand(seed)
+}
+
+// ShuffleInPlace pseudo-randomizes the order of elements, modifying the
+// given slice in-place.
+func ShuffleInPlace(xs []int, generator *LockedRand) []int {
+	return mat64rand.ShuffleInPlace(xs, generator)
+}
+
+// WeightedChoice performs a random generation of the indices based of the probability distribution itself.
+// Please note that it uses the global random.
+func WeightedChoice(dist []float64) int {
+	return mat64rand.WeightedChoice(dist)
+}
+
+// GetUniqueRandomInt generates n mutually exclusive integers up to max, using the default random source.
+// The callback checks whether a generated number can be accepted, or not.
+func GetUniqueRandomInt(n, max int, valid func(r int) bool) []int {
+	return mat64rand.GetUniqueRandomInt(n, max, valid)
+}
+
+// GetUniqueRandomIndices select n mutually exclusive indices, using the global random.
+// The callback checks whether an extracted index can be accepted, or not.
+func GetUniqueRandomIndices(n int, indices []int, valid func(r int) bool) []int {
+	return mat64rand.GetUniqueRandomIndices(n, indices, valid)
+}
+
+// Float returns, as a mat64.Float, a pseudo-random number in [0.0,1.0)
+// from the default Source.
+func Float() mat64.Float {
+	return mat64rand.Float()
+}
@@ -0,0 +1,28 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package reservoir
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64reservoir "github.com/nlpodyssey/spago/pkg/mat64/rand/reservoir"
+)
+
+// Reservoir selects a simple random sample of K items, without replacement,
+// from a stream of unknown length, in a single pass.
+// See: https://en.wikipedia.org/wiki/Reservoir_sampling.
+type Reservoir = mat64reservoir.Reservoir
+
+// New returns a new Reservoir, which keeps at most k items.
+func New(k int, generator *rand.LockedRand) *Reservoir {
+	return mat64reservoir.New(k, generator)
+}
+
+// Sample selects k distinct indices out of [0, n) with reservoir sampling,
+// without allocating the whole range of indices. The indices are returned in
+// no particular order. If k >= n, all the indices are returned.
+func Sample(n, k int, generator *rand.LockedRand) []int {
+	return mat64reservoir.Sample(n, k, generator)
+}
@@ -0,0 +1,29 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package truncnormal
+
+import (
+	mat32 "github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32truncnormal "github.com/nlpodyssey/spago/pkg/mat32/rand/truncnormal"
+)
+
+// TruncatedNormal is a source of normally distributed random numbers,
+// bounded within the interval [Min, Max].
+// See: https://en.wikipedia.org/wiki/Truncated_normal_distribution.
+type TruncatedNormal = mat32truncnormal.TruncatedNormal
+
+// New returns a new TruncatedNormal, initialized with the given standard deviation,
+// mean, and the lower and upper bounds of the distribution.
+// It panics if min is not lower than max.
+func New(std, mean, min, max float32, generator *rand.LockedRand) *TruncatedNormal {
+	return mat32truncnormal.New(std, mean, min, max, generator)
+}
+
+// Distribution creates a new matrix initialized with a truncated normal distribution.
+func Distribution(r, c int, std, mean, min, max float32, generator *rand.LockedRand) mat32.Matrix {
+	return mat32truncnormal.Distribution(r, c, std, mean, min, max, generator)
+}
@@ -0,0 +1,29 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package truncnormal
+
+import (
+	mat64 "github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64truncnormal "github.com/nlpodyssey/spago/pkg/mat64/rand/truncnormal"
+)
+
+// TruncatedNormal is a source of normally distributed random numbers,
+// bounded within the interval [Min, Max].
+// See: https://en.wikipedia.org/wiki/Truncated_normal_distribution.
+type TruncatedNormal = mat64truncnormal.TruncatedNormal
+
+// New returns a new TruncatedNormal, initialized with the given standard deviation,
+// mean, and the lower and upper bounds of the distribution.
+// It panics if min is not lower than max.
+func New(std, mean, min, max float64, generator *rand.LockedRand) *TruncatedNormal {
+	return mat64truncnormal.New(std, mean, min, max, generator)
+}
+
+// Distribution creates a new matrix initialized with a truncated normal distribution.
+func Distribution(r, c int, std, mean, min, max float64, generator *rand.LockedRand) mat64.Matrix {
+	return mat64truncnormal.Distribution(r, c, std, mean, min, max, generator)
+}
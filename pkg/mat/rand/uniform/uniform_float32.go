@@ -0,0 +1,20 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package uniform
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat32uniform "github.com/nlpodyssey/spago/pkg/mat32/rand/uniform"
+)
+
+// Uniform is a source of uniformly distributed random numbers.
+// See: https://en.wikipedia.org/wiki/Continuous_uniform_distribution.
+type Uniform = mat32uniform.Uniform
+
+// New returns a new Normal, initialized with the given min and max parameters.
+func New(min, max float32, generator *rand.LockedRand) *Uniform {
+	return mat32uniform.New(min, max, generator)
+}
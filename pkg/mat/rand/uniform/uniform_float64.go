@@ -0,0 +1,20 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package uniform
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	mat64uniform "github.com/nlpodyssey/spago/pkg/mat64/rand/uniform"
+)
+
+// Uniform is a source of uniformly distributed random numbers.
+// See: https://en.wikipedia.org/wiki/Continuous_uniform_distribution.
+type Uniform = mat64uniform.Uniform
+
+// New returns a new Normal, initialized with the given min and max parameters.
+func New(min, max float64, generator *rand.LockedRand) *Uniform {
+	return mat64uniform.New(min, max, generator)
+}
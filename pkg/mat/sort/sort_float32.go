@@ -0,0 +1,39 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build !float64
+// +build !float64
+
+package sort
+
+import (
+	mat32sort "github.com/nlpodyssey/spago/pkg/mat32/sort"
+	"sort"
+)
+
+// FloatSlice attaches the methods of sort.Interface to []float32, sorting in increasing order
+// (not-a-number values are treated as less than other values).
+type FloatSlice = mat32sort.FloatSlice
+
+// Slice is an extension of sort.Interface which keeps track of the
+// original indices of the elements of a slice after sorting.
+type Slice = mat32sort.Slice
+
+// NewSlice returns a new Slice.
+func NewSlice(n sort.Interface) *Slice {
+	return mat32sort.NewSlice(n)
+}
+
+// NewIntSlice returns a new Slice for the given sequence of int values.
+func NewIntSlice(n ...int) *Slice {
+	return mat32sort.NewIntSlice(n...)
+}
+
+// NewFloatSlice returns a new Slice for the given sequence of float32 values.
+func NewFloatSlice(n ...float32) *Slice {
+	return mat32sort.NewFloatSlice(n...)
+}
+
+// NewStringSlice returns a new Slice for the given sequence of string values.
+func NewStringSlice(n ...string) *Slice {
+	return mat32sort.NewStringSlice(n...)
+}
@@ -0,0 +1,38 @@
+// Code generated by gen.go. DO NOT EDIT.
+
+//go:build float64
+// +build float64
+
+package sort
+
+import (
+	mat64sort "github.com/nlpodyssey/spago/pkg/mat64/sort"
+	"sort"
+)
+
+// FloatSlice is an alias of sort.Float64Slice.
+type FloatSlice = mat64sort.FloatSlice
+
+// Slice is an extension of sort.Interface which keeps track of the
+// original indices of the elements of a slice after sorting.
+type Slice = mat64sort.Slice
+
+// NewSlice returns a new Slice.
+func NewSlice(n sort.Interface) *Slice {
+	return mat64sort.NewSlice(n)
+}
+
+// NewIntSlice returns a new Slice for the given sequence of int values.
+func NewIntSlice(n ...int) *Slice {
+	return mat64sort.NewIntSlice(n...)
+}
+
+// NewFloatSlice returns a new Slice for the given sequence of float32 values.
+func NewFloatSlice(n ...float64) *Slice {
+	return mat64sort.NewFloatSlice(n...)
+}
+
+// NewStringSlice returns a new Slice for the given sequence of string values.
+func NewStringSlice(n ...string) *Slice {
+	return mat64sort.NewStringSlice(n...)
+}
@@ -7,6 +7,7 @@ package mat32
 import (
 	"bytes"
 	"encoding/gob"
+	"github.com/nlpodyssey/spago/pkg/mat64"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -104,3 +105,33 @@ func TestMatrixBinaryMarshaling(t *testing.T) {
 		require.Nil(t, decodedMatrix)
 	})
 }
+
+func TestMatrixBinaryMarshaling_mat64(t *testing.T) {
+	t.Run("Dense matrix", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.Nil(t, mat64.MarshalBinaryMatrix(mat64.NewDense(2, 2, []float64{1.5, -2, 0.25, 1e-3}), buf))
+
+		decodedMatrix, err := UnmarshalBinaryMatrix(buf)
+		require.Nil(t, err)
+		require.IsType(t, &Dense{}, decodedMatrix)
+		rows, cols := decodedMatrix.Dims()
+		assert.Equal(t, 2, rows)
+		assert.Equal(t, 2, cols)
+		assert.InDeltaSlice(t, []Float{1.5, -2, 0.25, 1e-3}, decodedMatrix.Data(), 1e-7)
+	})
+
+	t.Run("Sparse matrix", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.Nil(t, mat64.MarshalBinaryMatrix(mat64.NewVecSparse([]float64{0, 42, 0}), buf))
+
+		decodedMatrix, err := UnmarshalBinaryMatrix(buf)
+		require.Nil(t, err)
+		require.IsType(t, &Sparse{}, decodedMatrix)
+		assert.Equal(t, []Float{0, 42, 0}, decodedMatrix.Data())
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		d := new(Dense)
+		assert.Error(t, d.UnmarshalBinary([]byte{1, 0, 0, 0, 1, 0, 0, 0, 42}))
+	})
+}
@@ -0,0 +1,258 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DType identifies the numeric type used to store the values of a matrix.
+type DType uint8
+
+const (
+	// DTypeFloat is the native Float type of the package.
+	DTypeFloat DType = iota
+	// DTypeFloat16 is the IEEE 754 half-precision binary floating-point format.
+	DTypeFloat16
+	// DTypeBFloat16 is the bfloat16 (brain floating point) format, that is
+	// a float32 truncated to its 16 most significant bits.
+	DTypeBFloat16
+)
+
+// String returns the name of the DType.
+func (t DType) String() string {
+	switch t {
+	case DTypeFloat:
+		return "float32"
+	case DTypeFloat16:
+		return "float16"
+	case DTypeBFloat16:
+		return "bfloat16"
+	default:
+		return fmt.Sprintf("DType(%d)", t)
+	}
+}
+
+// IsHalf reports whether the DType is a 16-bit floating-point format.
+func (t DType) IsHalf() bool {
+	return t == DTypeFloat16 || t == DTypeBFloat16
+}
+
+// ParseDType returns the DType corresponding to the given name.
+func ParseDType(s string) (DType, error) {
+	switch strings.ToLower(s) {
+	case "", "float", "float32", "fp32":
+		return DTypeFloat, nil
+	case "float16", "fp16", "half":
+		return DTypeFloat16, nil
+	case "bfloat16", "bf16":
+		return DTypeBFloat16, nil
+	default:
+		return DTypeFloat, fmt.Errorf("mat32: unknown dtype %q", s)
+	}
+}
+
+// Float16bits returns the IEEE 754 half-precision binary representation of f,
+// rounding to the nearest even value. Values too large in magnitude become
+// infinities, values too small become (signed) zeros.
+func Float16bits(f Float) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int32(b>>23) & 0xff
+	mant := b & 0x7fffff
+
+	if exp == 0xff { // Inf or NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return sign | 0x7c00 // overflow
+	}
+	if e <= 0 { // subnormal or zero
+		if e < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint32(14 - e)
+		h := mant >> shift
+		rem := mant & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rem > halfway || (rem == halfway && h&1 == 1) {
+			h++
+		}
+		return sign | uint16(h)
+	}
+
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++ // a carry into the exponent correctly produces the next power of two (or Inf)
+	}
+	return sign | uint16(h)
+}
+
+// Float16frombits returns the Float value corresponding to the IEEE 754
+// half-precision binary representation b.
+func Float16frombits(b uint16) Float {
+	sign := uint32(b&0x8000) << 16
+	exp := uint32(b>>10) & 0x1f
+	mant := uint32(b & 0x3ff)
+
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | e<<23 | mant<<13)
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	}
+}
+
+// BFloat16bits returns the bfloat16 binary representation of f,
+// rounding to the nearest even value.
+func BFloat16bits(f Float) uint16 {
+	b := math.Float32bits(f)
+	if b&0x7fffffff > 0x7f800000 {
+		return uint16(b>>16) | 0x40 // quiet NaN
+	}
+	b += 0x7fff + (b>>16)&1
+	return uint16(b >> 16)
+}
+
+// BFloat16frombits returns the Float value corresponding to the bfloat16
+// binary representation b.
+func BFloat16frombits(b uint16) Float {
+	return math.Float32frombits(uint32(b) << 16)
+}
+
+// HalfDense is a compact representation of a dense matrix, whose values are
+// stored with 16 bits each, according to a half-precision DType.
+//
+// HalfDense is not a Matrix: it is meant to hold values at rest (for example
+// the weights of a model used for inference), which are upcast to a Dense
+// matrix of Float when they are actually needed for computation.
+type HalfDense struct {
+	dtype DType
+	rows  int
+	cols  int
+	data  []uint16
+}
+
+// NewHalfDense returns a new HalfDense, converting the values of the given
+// matrix to the half-precision dtype.
+// It panics if dtype is not a 16-bit floating-point format.
+func NewHalfDense(m Matrix, dtype DType) *HalfDense {
+	if !dtype.IsHalf() {
+		panic(fmt.Sprintf("mat32: %s is not a half-precision dtype", dtype))
+	}
+	data := m.Data()
+	h := &HalfDense{
+		dtype: dtype,
+		rows:  m.Rows(),
+		cols:  m.Columns(),
+		data:  make([]uint16, len(data)),
+	}
+	if dtype == DTypeFloat16 {
+		for i, v := range data {
+			h.data[i] = Float16bits(v)
+		}
+	} else {
+		for i, v := range data {
+			h.data[i] = BFloat16bits(v)
+		}
+	}
+	return h
+}
+
+// DType returns the numeric type of the stored values.
+func (h *HalfDense) DType() DType {
+	return h.dtype
+}
+
+// Rows returns the number of rows of the matrix.
+func (h *HalfDense) Rows() int {
+	return h.rows
+}
+
+// Columns returns the number of columns of the matrix.
+func (h *HalfDense) Columns() int {
+	return h.cols
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (h *HalfDense) Dims() (r, c int) {
+	return h.rows, h.cols
+}
+
+// Size returns the size of the matrix (rows × columns).
+func (h *HalfDense) Size() int {
+	return len(h.data)
+}
+
+// Dense returns a new Dense matrix, with the values upcast to Float.
+func (h *HalfDense) Dense() *Dense {
+	out := GetDenseWorkspace(h.rows, h.cols)
+	data := out.data
+	if h.dtype == DTypeFloat16 {
+		for i, v := range h.data {
+			data[i] = Float16frombits(v)
+		}
+	} else {
+		for i, v := range h.data {
+			data[i] = BFloat16frombits(v)
+		}
+	}
+	return out
+}
+
+// MarshalBinary marshals a HalfDense matrix into binary form.
+func (h HalfDense) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 9+len(h.data)*2)
+	data[0] = byte(h.dtype)
+	binary.LittleEndian.PutUint32(data[1:], uint32(h.rows))
+	binary.LittleEndian.PutUint32(data[5:], uint32(h.cols))
+	for i, v := range h.data {
+		binary.LittleEndian.PutUint16(data[9+i*2:], v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary unmarshals a binary representation of a HalfDense matrix.
+func (h *HalfDense) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return fmt.Errorf("mat32: invalid HalfDense binary data")
+	}
+	h.dtype = DType(data[0])
+	if !h.dtype.IsHalf() {
+		return fmt.Errorf("mat32: %s is not a half-precision dtype", h.dtype)
+	}
+	h.rows = int(binary.LittleEndian.Uint32(data[1:]))
+	h.cols = int(binary.LittleEndian.Uint32(data[5:]))
+	size := h.rows * h.cols
+	if len(data) != 9+size*2 {
+		return fmt.Errorf("mat32: invalid HalfDense binary data size")
+	}
+	h.data = make([]uint16, size)
+	for i := range h.data {
+		h.data[i] = binary.LittleEndian.Uint16(data[9+i*2:])
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestFloat16bits(t *testing.T) {
+	testCases := []struct {
+		f Float
+		b uint16
+	}{
+		{0, 0x0000},
+		{Float(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},                       // max half
+		{65520, 0x7c00},                       // rounds to +Inf
+		{Float(math.Pow(2, -14)), 0x0400},     // min positive normal
+		{Float(math.Pow(2, -24)), 0x0001},     // min positive subnormal
+		{Float(math.Pow(2, -26)), 0x0000},     // underflow
+		{1 + Float(math.Pow(2, -11)), 0x3c00}, // tie, round to even
+		{Float(math.Inf(1)), 0x7c00},
+		{Float(math.Inf(-1)), 0xfc00},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.b, Float16bits(tc.f), "%g", tc.f)
+	}
+	assert.True(t, math.IsNaN(float64(Float16frombits(Float16bits(NaN())))))
+}
+
+func TestFloat16frombits(t *testing.T) {
+	for b := 0; b <= 0xffff; b++ {
+		f := Float16frombits(uint16(b))
+		if math.IsNaN(float64(f)) {
+			continue
+		}
+		assert.Equal(t, uint16(b), Float16bits(f))
+	}
+}
+
+func TestBFloat16bits(t *testing.T) {
+	assert.Equal(t, uint16(0x3f80), BFloat16bits(1))
+	assert.Equal(t, uint16(0xc000), BFloat16bits(-2))
+	assert.Equal(t, Float(1), BFloat16frombits(BFloat16bits(1.001)))
+	assert.Equal(t, Float(3.140625), BFloat16frombits(BFloat16bits(3.14159)))
+	assert.True(t, math.IsNaN(float64(BFloat16frombits(BFloat16bits(NaN())))))
+}
+
+func TestParseDType(t *testing.T) {
+	for _, dtype := range []DType{DTypeFloat, DTypeFloat16, DTypeBFloat16} {
+		parsed, err := ParseDType(dtype.String())
+		require.Nil(t, err)
+		assert.Equal(t, dtype, parsed)
+	}
+	_, err := ParseDType("foo")
+	assert.NotNil(t, err)
+}
+
+func TestHalfDense(t *testing.T) {
+	m := NewDense(2, 3, []Float{
+		1, 2, 3,
+		-0.1, 0.2, 1000.5,
+	})
+	for _, dtype := range []DType{DTypeFloat16, DTypeBFloat16} {
+		t.Run(dtype.String(), func(t *testing.T) {
+			h := NewHalfDense(m, dtype)
+			assert.Equal(t, dtype, h.DType())
+			assert.Equal(t, 6, h.Size())
+			r, c := h.Dims()
+			assert.Equal(t, 2, r)
+			assert.Equal(t, 3, c)
+
+			d := h.Dense()
+			assert.Equal(t, 2, d.Rows())
+			assert.Equal(t, 3, d.Columns())
+			for i, v := range m.Data() {
+				assert.InEpsilon(t, v, d.Data()[i], 0.01)
+			}
+
+			bin, err := h.MarshalBinary()
+			require.Nil(t, err)
+			decoded := new(HalfDense)
+			require.Nil(t, decoded.UnmarshalBinary(bin))
+			assert.Equal(t, h, decoded)
+		})
+	}
+	assert.Panics(t, func() { NewHalfDense(m, DTypeFloat) })
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package f32
+
+import "math"
+
+// The scalar implementations below use the same range reductions and
+// polynomial approximations (from the Cephes library) as the vectorized
+// kernels, so that the results do not depend on the length of the input
+// (the tail of a slice is always processed by the scalar code).
+
+const (
+	expHi  = 88.72283935546875   // ln(MaxFloat32)
+	expLo  = -87.33654022216797  // ln(SmallestNormalFloat32)
+	log2e  = 1.44269504088896341 // 1/ln(2)
+	ln2Hi  = 0.693359375
+	ln2Lo  = -2.12194440e-4
+	sqrtHf = 0.707106781186547524 // sqrt(0.5)
+
+	// geluK0 and geluK1 are such that 2*sqrt(2/pi)*(x+0.044715*x^3) = x*(geluK0+geluK1*x^2)
+	geluK0 = 1.5957691216057308
+	geluK1 = 0.07135481627159809
+
+	minNormal = 1.17549435082228750797e-38 // 2**-126
+)
+
+// exp returns e**x. Denormal results are flushed to zero.
+func exp(x float32) float32 {
+	switch {
+	case x > expHi:
+		return float32(math.Inf(1))
+	case x < expLo:
+		return 0
+	case x != x:
+		return x
+	}
+	n := float32(math.RoundToEven(float64(x * log2e)))
+	if n > 127 {
+		n = 127
+	}
+	r := x - n*ln2Hi - n*ln2Lo
+	p := float32(1.9875691500e-4)
+	p = p*r + 1.3981999507e-3
+	p = p*r + 8.3334519073e-3
+	p = p*r + 4.1665795894e-2
+	p = p*r + 1.6666665459e-1
+	p = p*r + 5.0000001201e-1
+	p = p*(r*r) + r + 1
+	return p * math.Float32frombits(uint32(int32(n)+127)<<23)
+}
+
+// log returns the natural logarithm of x. Denormal inputs are treated as the
+// smallest normal number.
+func log(x float32) float32 {
+	switch {
+	case x < 0 || x != x:
+		return float32(math.NaN())
+	case x == 0:
+		return float32(math.Inf(-1))
+	case math.IsInf(float64(x), 1):
+		return x
+	case x < minNormal:
+		x = minNormal
+	}
+	bits := math.Float32bits(x)
+	e := float32(int32(bits>>23) - 126)
+	m := math.Float32frombits(bits&0x007fffff | 0x3f000000) // in [0.5, 1)
+	if m < sqrtHf {
+		e--
+		m = m - 1 + m
+	} else {
+		m = m - 1
+	}
+	z := m * m
+	y := float32(7.0376836292e-2)
+	y = y*m - 1.1514610310e-1
+	y = y*m + 1.1676998740e-1
+	y = y*m - 1.2420140846e-1
+	y = y*m + 1.4249322787e-1
+	y = y*m - 1.6668057665e-1
+	y = y*m + 2.0000714765e-1
+	y = y*m - 2.4999993993e-1
+	y = y*m + 3.3333331174e-1
+	y = y * m * z
+	y += e * ln2Lo
+	y += -0.5 * z
+	return m + y + e*ln2Hi
+}
+
+// tanh returns the hyperbolic tangent of x.
+func tanh(x float32) float32 {
+	if x > -0.625 && x < 0.625 {
+		z := x * x
+		p := float32(-5.70498872745e-3)
+		p = p*z + 2.06390887954e-2
+		p = p*z - 5.37397155531e-2
+		p = p*z + 1.33314422036e-1
+		p = p*z - 3.33332819422e-1
+		return p*z*x + x
+	}
+	return 1 - 2/(exp(x+x)+1)
+}
+
+// sigmoid returns the logistic function of x.
+func sigmoid(x float32) float32 {
+	return 1 / (1 + exp(-x))
+}
+
+// gelu returns the Gaussian Error Linear Unit of x, using the tanh
+// approximation 0.5*x*(1+tanh(sqrt(2/pi)*(x+0.044715*x^3))), computed
+// as x*sigmoid(2*sqrt(2/pi)*(x+0.044715*x^3)).
+func gelu(x float32) float32 {
+	return x / (1 + exp(-x*(geluK0+geluK1*x*x)))
+}
+
+func expGo(dst, x []float32) {
+	for i, v := range x {
+		dst[i] = exp(v)
+	}
+}
+
+func logGo(dst, x []float32) {
+	for i, v := range x {
+		dst[i] = log(v)
+	}
+}
+
+func tanhGo(dst, x []float32) {
+	for i, v := range x {
+		dst[i] = tanh(v)
+	}
+}
+
+func sigmoidGo(dst, x []float32) {
+	for i, v := range x {
+		dst[i] = sigmoid(v)
+	}
+}
+
+func geluGo(dst, x []float32) {
+	for i, v := range x {
+		dst[i] = gelu(v)
+	}
+}
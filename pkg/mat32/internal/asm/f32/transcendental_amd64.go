@@ -0,0 +1,65 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !noasm && !gccgo && !safe
+// +build !noasm,!gccgo,!safe
+
+package f32
+
+import "golang.org/x/sys/cpu"
+
+// hasAVX2 reports whether the vectorized kernels can be used.
+var hasAVX2 = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// The following kernels process 8 elements at a time, up to the largest
+// multiple of 8 not greater than len(x). The remaining elements are left
+// untouched.
+
+func expAVX2(dst, x []float32)
+func logAVX2(dst, x []float32)
+func tanhAVX2(dst, x []float32)
+func sigmoidAVX2(dst, x []float32)
+func geluAVX2(dst, x []float32)
+
+// ExpTo is
+//  for i, v := range x {
+//  	dst[i] = exp(v)
+//  }
+func ExpTo(dst, x []float32) { vectorized(dst, x, expAVX2, expGo) }
+
+// LogTo is
+//  for i, v := range x {
+//  	dst[i] = log(v)
+//  }
+func LogTo(dst, x []float32) { vectorized(dst, x, logAVX2, logGo) }
+
+// TanhTo is
+//  for i, v := range x {
+//  	dst[i] = tanh(v)
+//  }
+func TanhTo(dst, x []float32) { vectorized(dst, x, tanhAVX2, tanhGo) }
+
+// SigmoidTo is
+//  for i, v := range x {
+//  	dst[i] = 1 / (1 + exp(-v))
+//  }
+func SigmoidTo(dst, x []float32) { vectorized(dst, x, sigmoidAVX2, sigmoidGo) }
+
+// GELUTo is
+//  for i, v := range x {
+//  	dst[i] = 0.5 * v * (1 + tanh(sqrt(2/pi)*(v+0.044715*v*v*v)))
+//  }
+func GELUTo(dst, x []float32) { vectorized(dst, x, geluAVX2, geluGo) }
+
+// vectorized applies the kernel to the largest multiple of 8 elements, and
+// the scalar function to the rest.
+func vectorized(dst, x []float32, kernel, scalar func(dst, x []float32)) {
+	dst = dst[:len(x)]
+	if hasAVX2 {
+		n := len(x) &^ 7
+		kernel(dst[:n], x[:n])
+		dst, x = dst[n:], x[n:]
+	}
+	scalar(dst, x)
+}
@@ -0,0 +1,323 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !noasm && !gccgo && !safe
+// +build !noasm,!gccgo,!safe
+
+#include "textflag.h"
+
+// Constants broadcast to the YMM registers. See transcendental.go for
+// their meaning.
+DATA expHi<>+0(SB)/4, $0x42b17218
+GLOBL expHi<>(SB), RODATA|NOPTR, $4
+DATA expLo<>+0(SB)/4, $0xc2aeac4f
+GLOBL expLo<>(SB), RODATA|NOPTR, $4
+DATA log2e<>+0(SB)/4, $0x3fb8aa3b
+GLOBL log2e<>(SB), RODATA|NOPTR, $4
+DATA ln2Hi<>+0(SB)/4, $0x3f318000
+GLOBL ln2Hi<>(SB), RODATA|NOPTR, $4
+DATA ln2Lo<>+0(SB)/4, $0xb95e8083
+GLOBL ln2Lo<>(SB), RODATA|NOPTR, $4
+DATA expP0<>+0(SB)/4, $0x39506967
+GLOBL expP0<>(SB), RODATA|NOPTR, $4
+DATA expP1<>+0(SB)/4, $0x3ab743ce
+GLOBL expP1<>(SB), RODATA|NOPTR, $4
+DATA expP2<>+0(SB)/4, $0x3c088908
+GLOBL expP2<>(SB), RODATA|NOPTR, $4
+DATA expP3<>+0(SB)/4, $0x3d2aa9c1
+GLOBL expP3<>(SB), RODATA|NOPTR, $4
+DATA expP4<>+0(SB)/4, $0x3e2aaaaa
+GLOBL expP4<>(SB), RODATA|NOPTR, $4
+DATA expP5<>+0(SB)/4, $0x3f000000
+GLOBL expP5<>(SB), RODATA|NOPTR, $4
+DATA one<>+0(SB)/4, $0x3f800000
+GLOBL one<>(SB), RODATA|NOPTR, $4
+DATA two<>+0(SB)/4, $0x40000000
+GLOBL two<>(SB), RODATA|NOPTR, $4
+DATA half<>+0(SB)/4, $0x3f000000
+GLOBL half<>(SB), RODATA|NOPTR, $4
+DATA negHalf<>+0(SB)/4, $0xbf000000
+GLOBL negHalf<>(SB), RODATA|NOPTR, $4
+DATA f127<>+0(SB)/4, $0x42fe0000
+GLOBL f127<>(SB), RODATA|NOPTR, $4
+DATA i127<>+0(SB)/4, $0x0000007f
+GLOBL i127<>(SB), RODATA|NOPTR, $4
+DATA i126<>+0(SB)/4, $0x0000007e
+GLOBL i126<>(SB), RODATA|NOPTR, $4
+DATA sqrtHf<>+0(SB)/4, $0x3f3504f3
+GLOBL sqrtHf<>(SB), RODATA|NOPTR, $4
+DATA minNormal<>+0(SB)/4, $0x00800000
+GLOBL minNormal<>(SB), RODATA|NOPTR, $4
+DATA mantMask<>+0(SB)/4, $0x007fffff
+GLOBL mantMask<>(SB), RODATA|NOPTR, $4
+DATA signMask<>+0(SB)/4, $0x80000000
+GLOBL signMask<>(SB), RODATA|NOPTR, $4
+DATA absMask<>+0(SB)/4, $0x7fffffff
+GLOBL absMask<>(SB), RODATA|NOPTR, $4
+DATA logP0<>+0(SB)/4, $0x3d9021bb
+GLOBL logP0<>(SB), RODATA|NOPTR, $4
+DATA logP1<>+0(SB)/4, $0xbdebd1b8
+GLOBL logP1<>(SB), RODATA|NOPTR, $4
+DATA logP2<>+0(SB)/4, $0x3def251a
+GLOBL logP2<>(SB), RODATA|NOPTR, $4
+DATA logP3<>+0(SB)/4, $0xbdfe5d4f
+GLOBL logP3<>(SB), RODATA|NOPTR, $4
+DATA logP4<>+0(SB)/4, $0x3e11e9bf
+GLOBL logP4<>(SB), RODATA|NOPTR, $4
+DATA logP5<>+0(SB)/4, $0xbe2aae50
+GLOBL logP5<>(SB), RODATA|NOPTR, $4
+DATA logP6<>+0(SB)/4, $0x3e4cceac
+GLOBL logP6<>(SB), RODATA|NOPTR, $4
+DATA logP7<>+0(SB)/4, $0xbe7ffffc
+GLOBL logP7<>(SB), RODATA|NOPTR, $4
+DATA logP8<>+0(SB)/4, $0x3eaaaaaa
+GLOBL logP8<>(SB), RODATA|NOPTR, $4
+DATA tanhP0<>+0(SB)/4, $0xbbbaf0ea
+GLOBL tanhP0<>(SB), RODATA|NOPTR, $4
+DATA tanhP1<>+0(SB)/4, $0x3ca9134e
+GLOBL tanhP1<>(SB), RODATA|NOPTR, $4
+DATA tanhP2<>+0(SB)/4, $0xbd5c1e2d
+GLOBL tanhP2<>(SB), RODATA|NOPTR, $4
+DATA tanhP3<>+0(SB)/4, $0x3e088393
+GLOBL tanhP3<>(SB), RODATA|NOPTR, $4
+DATA tanhP4<>+0(SB)/4, $0xbeaaaa99
+GLOBL tanhP4<>(SB), RODATA|NOPTR, $4
+DATA tanhSmall<>+0(SB)/4, $0x3f200000
+GLOBL tanhSmall<>(SB), RODATA|NOPTR, $4
+DATA geluK0<>+0(SB)/4, $0x3fcc422a
+GLOBL geluK0<>(SB), RODATA|NOPTR, $4
+DATA geluK1<>+0(SB)/4, $0x3d922279
+GLOBL geluK1<>(SB), RODATA|NOPTR, $4
+DATA inf<>+0(SB)/4, $0x7f800000
+GLOBL inf<>(SB), RODATA|NOPTR, $4
+DATA negInf<>+0(SB)/4, $0xff800000
+GLOBL negInf<>(SB), RODATA|NOPTR, $4
+DATA nan<>+0(SB)/4, $0x7fc00000
+GLOBL nan<>(SB), RODATA|NOPTR, $4
+
+// EXP_Y0 computes Y0 = exp(Y0), clobbering Y1, Y2, Y3 and Y5.
+#define EXP_Y0 \
+	VMOVAPS      Y0, Y5;            \
+	VBROADCASTSS expHi<>(SB), Y1;   \
+	VMINPS       Y0, Y1, Y0;        \
+	VBROADCASTSS expLo<>(SB), Y1;   \
+	VMAXPS       Y0, Y1, Y0;        \
+	VBROADCASTSS log2e<>(SB), Y1;   \
+	VMULPS       Y1, Y0, Y1;        \
+	VROUNDPS     $0, Y1, Y1;        \
+	VBROADCASTSS f127<>(SB), Y2;    \
+	VMINPS       Y2, Y1, Y1;        \
+	VBROADCASTSS ln2Hi<>(SB), Y2;   \
+	VFNMADD231PS Y2, Y1, Y0;        \
+	VBROADCASTSS ln2Lo<>(SB), Y2;   \
+	VFNMADD231PS Y2, Y1, Y0;        \
+	VBROADCASTSS expP0<>(SB), Y2;   \
+	VBROADCASTSS expP1<>(SB), Y3;   \
+	VFMADD213PS  Y3, Y0, Y2;        \
+	VBROADCASTSS expP2<>(SB), Y3;   \
+	VFMADD213PS  Y3, Y0, Y2;        \
+	VBROADCASTSS expP3<>(SB), Y3;   \
+	VFMADD213PS  Y3, Y0, Y2;        \
+	VBROADCASTSS expP4<>(SB), Y3;   \
+	VFMADD213PS  Y3, Y0, Y2;        \
+	VBROADCASTSS expP5<>(SB), Y3;   \
+	VFMADD213PS  Y3, Y0, Y2;        \
+	VMULPS       Y0, Y0, Y3;        \
+	VFMADD213PS  Y0, Y3, Y2;        \
+	VBROADCASTSS one<>(SB), Y3;     \
+	VADDPS       Y3, Y2, Y2;        \
+	VCVTPS2DQ    Y1, Y1;            \
+	VPBROADCASTD i127<>(SB), Y3;    \
+	VPADDD       Y3, Y1, Y1;        \
+	VPSLLD       $23, Y1, Y1;       \
+	VMULPS       Y1, Y2, Y0;        \
+	VBROADCASTSS expHi<>(SB), Y1;   \
+	VCMPPS       $0x1e, Y1, Y5, Y2; \
+	VBROADCASTSS inf<>(SB), Y3;     \
+	VBLENDVPS    Y2, Y3, Y0, Y0;    \
+	VBROADCASTSS expLo<>(SB), Y1;   \
+	VCMPPS       $0x11, Y1, Y5, Y2; \
+	VANDNPS      Y0, Y2, Y0
+
+// LOOP_HEAD loads the arguments and jumps to end if there is nothing to do.
+#define LOOP_HEAD(end) \
+	MOVQ dst_base+0(FP), DI; \
+	MOVQ x_base+24(FP), SI;  \
+	MOVQ x_len+32(FP), CX;   \
+	SHRQ $3, CX;             \
+	JZ   end
+
+// LOOP_TAIL stores Y0, then moves to the next 8 elements.
+#define LOOP_TAIL(loop) \
+	VMOVUPS Y0, (DI); \
+	ADDQ    $32, SI;  \
+	ADDQ    $32, DI;  \
+	DECQ    CX;       \
+	JNZ     loop
+
+// func expAVX2(dst, x []float32)
+TEXT ·expAVX2(SB), NOSPLIT, $0
+	LOOP_HEAD(exp_end)
+
+exp_loop:
+	VMOVUPS (SI), Y0
+	EXP_Y0
+	LOOP_TAIL(exp_loop)
+
+exp_end:
+	VZEROUPPER
+	RET
+
+// func sigmoidAVX2(dst, x []float32)
+TEXT ·sigmoidAVX2(SB), NOSPLIT, $0
+	LOOP_HEAD(sigmoid_end)
+
+sigmoid_loop:
+	VMOVUPS      (SI), Y0
+	VBROADCASTSS signMask<>(SB), Y1
+	VXORPS       Y1, Y0, Y0         // Y0 = -x
+	EXP_Y0
+	VBROADCASTSS one<>(SB), Y1
+	VADDPS       Y1, Y0, Y0
+	VDIVPS       Y0, Y1, Y0         // Y0 = 1 / (1 + exp(-x))
+	LOOP_TAIL(sigmoid_loop)
+
+sigmoid_end:
+	VZEROUPPER
+	RET
+
+// func tanhAVX2(dst, x []float32)
+TEXT ·tanhAVX2(SB), NOSPLIT, $0
+	LOOP_HEAD(tanh_end)
+
+tanh_loop:
+	VMOVUPS      (SI), Y4
+	VADDPS       Y4, Y4, Y0
+	EXP_Y0
+	VBROADCASTSS one<>(SB), Y1
+	VADDPS       Y1, Y0, Y0
+	VBROADCASTSS two<>(SB), Y2
+	VDIVPS       Y0, Y2, Y0
+	VSUBPS       Y0, Y1, Y0            // Y0 = 1 - 2/(exp(2x)+1)
+
+	VMULPS       Y4, Y4, Y6            // z = x*x
+	VBROADCASTSS tanhP0<>(SB), Y7
+	VBROADCASTSS tanhP1<>(SB), Y8
+	VFMADD213PS  Y8, Y6, Y7
+	VBROADCASTSS tanhP2<>(SB), Y8
+	VFMADD213PS  Y8, Y6, Y7
+	VBROADCASTSS tanhP3<>(SB), Y8
+	VFMADD213PS  Y8, Y6, Y7
+	VBROADCASTSS tanhP4<>(SB), Y8
+	VFMADD213PS  Y8, Y6, Y7
+	VMULPS       Y6, Y7, Y7
+	VFMADD213PS  Y4, Y4, Y7            // Y7 = p(z)*z*x + x
+
+	VBROADCASTSS absMask<>(SB), Y8
+	VANDPS       Y8, Y4, Y8
+	VBROADCASTSS tanhSmall<>(SB), Y9
+	VCMPPS       $0x11, Y9, Y8, Y8     // |x| < 0.625
+	VBLENDVPS    Y8, Y7, Y0, Y0
+	LOOP_TAIL(tanh_loop)
+
+tanh_end:
+	VZEROUPPER
+	RET
+
+// func geluAVX2(dst, x []float32)
+TEXT ·geluAVX2(SB), NOSPLIT, $0
+	LOOP_HEAD(gelu_end)
+
+gelu_loop:
+	VMOVUPS      (SI), Y4
+	VMULPS       Y4, Y4, Y1
+	VBROADCASTSS geluK1<>(SB), Y2
+	VBROADCASTSS geluK0<>(SB), Y3
+	VFMADD213PS  Y3, Y1, Y2            // Y2 = k0 + k1*x*x
+	VMULPS       Y4, Y2, Y0
+	VBROADCASTSS signMask<>(SB), Y1
+	VXORPS       Y1, Y0, Y0
+	EXP_Y0
+	VBROADCASTSS one<>(SB), Y1
+	VADDPS       Y1, Y0, Y0
+	VDIVPS       Y0, Y4, Y0            // Y0 = x / (1 + exp(-x*(k0+k1*x*x)))
+	LOOP_TAIL(gelu_loop)
+
+gelu_end:
+	VZEROUPPER
+	RET
+
+// func logAVX2(dst, x []float32)
+TEXT ·logAVX2(SB), NOSPLIT, $0
+	LOOP_HEAD(log_end)
+
+log_loop:
+	VMOVUPS      (SI), Y4
+	VBROADCASTSS minNormal<>(SB), Y1
+	VMAXPS       Y4, Y1, Y0
+
+	// frexp: Y1 = exponent, Y0 = mantissa in [0.5, 1)
+	VPSRLD       $23, Y0, Y1
+	VPBROADCASTD i126<>(SB), Y2
+	VPSUBD       Y2, Y1, Y1
+	VCVTDQ2PS    Y1, Y1
+	VBROADCASTSS mantMask<>(SB), Y2
+	VANDPS       Y2, Y0, Y0
+	VBROADCASTSS half<>(SB), Y2
+	VORPS        Y2, Y0, Y0
+
+	// if m < sqrt(0.5) { e--; m = m - 1 + m } else { m = m - 1 }
+	VBROADCASTSS sqrtHf<>(SB), Y2
+	VCMPPS       $0x11, Y2, Y0, Y3
+	VANDPS       Y3, Y0, Y5
+	VBROADCASTSS one<>(SB), Y2
+	VANDPS       Y2, Y3, Y6
+	VSUBPS       Y6, Y1, Y1
+	VSUBPS       Y2, Y0, Y0
+	VADDPS       Y5, Y0, Y0
+
+	VMULPS       Y0, Y0, Y2            // z = m*m
+	VBROADCASTSS logP0<>(SB), Y3
+	VBROADCASTSS logP1<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP2<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP3<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP4<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP5<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP6<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP7<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VBROADCASTSS logP8<>(SB), Y6
+	VFMADD213PS  Y6, Y0, Y3
+	VMULPS       Y0, Y3, Y3
+	VMULPS       Y2, Y3, Y3            // y = p(m)*m*z
+	VBROADCASTSS ln2Lo<>(SB), Y6
+	VFMADD231PS  Y6, Y1, Y3            // y += e*ln2Lo
+	VBROADCASTSS negHalf<>(SB), Y6
+	VFMADD231PS  Y6, Y2, Y3            // y -= 0.5*z
+	VADDPS       Y3, Y0, Y0
+	VBROADCASTSS ln2Hi<>(SB), Y6
+	VFMADD231PS  Y6, Y1, Y0            // Y0 = m + y + e*ln2Hi
+
+	// special cases: log(NaN) = NaN, log(+Inf) = +Inf, log(x < 0) = NaN, log(0) = -Inf
+	VXORPS       Y6, Y6, Y6
+	VBROADCASTSS inf<>(SB), Y7
+	VCMPPS       $0x05, Y7, Y4, Y8
+	VBLENDVPS    Y8, Y4, Y0, Y0
+	VCMPPS       $0x11, Y6, Y4, Y8
+	VBROADCASTSS nan<>(SB), Y9
+	VBLENDVPS    Y8, Y9, Y0, Y0
+	VCMPPS       $0x00, Y6, Y4, Y8
+	VBROADCASTSS negInf<>(SB), Y9
+	VBLENDVPS    Y8, Y9, Y0, Y0
+	LOOP_TAIL(log_loop)
+
+log_end:
+	VZEROUPPER
+	RET
@@ -0,0 +1,38 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !amd64 || noasm || gccgo || safe
+// +build !amd64 noasm gccgo safe
+
+package f32
+
+// ExpTo is
+//  for i, v := range x {
+//  	dst[i] = exp(v)
+//  }
+func ExpTo(dst, x []float32) { expGo(dst[:len(x)], x) }
+
+// LogTo is
+//  for i, v := range x {
+//  	dst[i] = log(v)
+//  }
+func LogTo(dst, x []float32) { logGo(dst[:len(x)], x) }
+
+// TanhTo is
+//  for i, v := range x {
+//  	dst[i] = tanh(v)
+//  }
+func TanhTo(dst, x []float32) { tanhGo(dst[:len(x)], x) }
+
+// SigmoidTo is
+//  for i, v := range x {
+//  	dst[i] = 1 / (1 + exp(-v))
+//  }
+func SigmoidTo(dst, x []float32) { sigmoidGo(dst[:len(x)], x) }
+
+// GELUTo is
+//  for i, v := range x {
+//  	dst[i] = 0.5 * v * (1 + tanh(sqrt(2/pi)*(v+0.044715*v*v*v)))
+//  }
+func GELUTo(dst, x []float32) { geluGo(dst[:len(x)], x) }
@@ -0,0 +1,107 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package f32_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	. "github.com/nlpodyssey/spago/pkg/mat32/internal/asm/f32"
+)
+
+var transcendentalTests = []struct {
+	name   string
+	fn     func(dst, x []float32)
+	want   func(x float64) float64
+	lo, hi float64
+	tol    float64 // relative tolerance, or absolute below 1
+}{
+	{name: "Exp", fn: ExpTo, want: math.Exp, lo: -80, hi: 80, tol: 2e-6},
+	{name: "Log", fn: LogTo, want: math.Log, lo: 1e-6, hi: 1e6, tol: 2e-6},
+	{name: "Tanh", fn: TanhTo, want: math.Tanh, lo: -12, hi: 12, tol: 2e-6},
+	{name: "Sigmoid", fn: SigmoidTo, want: sigmoid, lo: -40, hi: 40, tol: 2e-6},
+	{name: "GELU", fn: GELUTo, want: gelu, lo: -12, hi: 12, tol: 2e-6},
+}
+
+func TestTranscendental(t *testing.T) {
+	for _, test := range transcendentalTests {
+		for _, n := range []int{0, 1, 7, 8, 9, 100, 4099} {
+			t.Run(fmt.Sprintf("%s/n=%d", test.name, n), func(t *testing.T) {
+				x := make([]float32, n)
+				for i := range x {
+					if test.name == "Log" {
+						x[i] = float32(test.lo * math.Pow(test.hi/test.lo, float64(i)/float64(n)))
+					} else {
+						x[i] = float32(test.lo + (test.hi-test.lo)*float64(i)/float64(n))
+					}
+				}
+				dst := make([]float32, n+1)
+				dst[n] = -42 // guard
+				test.fn(dst, x)
+				for i, v := range x {
+					want := test.want(float64(v))
+					if diff := math.Abs(float64(dst[i]) - want); diff > test.tol*math.Max(1, math.Abs(want)) {
+						t.Errorf("%s(%v) = %v, want %v", test.name, v, dst[i], want)
+					}
+				}
+				if dst[n] != -42 {
+					t.Errorf("unexpected write beyond len(x)")
+				}
+			})
+		}
+	}
+}
+
+func TestTranscendental_SpecialValues(t *testing.T) {
+	inf, nan := float32(math.Inf(1)), float32(math.NaN())
+	x := []float32{0, float32(math.Copysign(0, -1)), -1, inf, -inf, nan, 100, -100}
+	for _, test := range []struct {
+		name string
+		fn   func(dst, x []float32)
+		want []float64
+	}{
+		{"Exp", ExpTo, []float64{1, 1, math.Exp(-1), math.Inf(1), 0, math.NaN(), math.Inf(1), 0}},
+		{"Log", LogTo, []float64{math.Inf(-1), math.Inf(-1), math.NaN(), math.Inf(1), math.NaN(), math.NaN(), math.Log(100), math.NaN()}},
+		{"Tanh", TanhTo, []float64{0, 0, math.Tanh(-1), 1, -1, math.NaN(), 1, -1}},
+		{"Sigmoid", SigmoidTo, []float64{0.5, 0.5, sigmoid(-1), 1, 0, math.NaN(), 1, 0}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// repeated to exercise both the vectorized and the scalar code
+			xs := append(append([]float32{}, x...), x...)
+			xs = append(xs, x...)
+			dst := make([]float32, len(xs))
+			test.fn(dst, xs)
+			for i, v := range dst {
+				want := test.want[i%len(x)]
+				if math.IsNaN(want) != math.IsNaN(float64(v)) || !math.IsNaN(want) && math.Abs(float64(v)-want) > 1e-6*math.Max(1, math.Abs(want)) {
+					t.Errorf("%s(%v) = %v, want %v", test.name, xs[i], v, want)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTranscendental(b *testing.B) {
+	x := make([]float32, 1<<12)
+	for i := range x {
+		x[i] = float32(i%200)/20 - 5
+	}
+	dst := make([]float32, len(x))
+	for _, test := range transcendentalTests {
+		b.Run(test.name, func(b *testing.B) {
+			b.SetBytes(int64(4 * len(x)))
+			for i := 0; i < b.N; i++ {
+				test.fn(dst, x)
+			}
+		})
+	}
+}
+
+func sigmoid(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
+
+func gelu(x float64) float64 {
+	return 0.5 * x * (1 + math.Tanh(math.Sqrt(2/math.Pi)*(x+0.044715*x*x*x)))
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetParallelThreshold(t *testing.T) {
+	defer SetParallelThreshold(ParallelThreshold())
+
+	SetParallelThreshold(42)
+	assert.Equal(t, 42, ParallelThreshold())
+
+	SetParallelThreshold(0)
+	assert.Equal(t, math.MaxInt64, ParallelThreshold())
+}
+
+func TestParallelFor(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	defer SetParallelThreshold(ParallelThreshold())
+	SetParallelThreshold(1)
+
+	for _, n := range []int{0, 1, 7, 100, 1001} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			visited := make([]int, n)
+			parallelFor(n, func(lo, hi int) {
+				for i := lo; i < hi; i++ {
+					visited[i]++
+				}
+			})
+			for i, v := range visited {
+				assert.Equalf(t, 1, v, "index %d", i)
+			}
+		})
+	}
+}
+
+func TestDense_ParallelElementWise(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	defer SetParallelThreshold(ParallelThreshold())
+
+	a := NewDense(37, 29, makeTestData(37*29, 0.5))
+	b := NewDense(37, 29, makeTestData(37*29, 1.5))
+	fn := func(i, j int, v Float) Float { return v*Float(i) + Float(j) }
+	fnAlpha := func(i, j int, v Float, alpha ...Float) Float { return v*alpha[0] + Float(i-j) }
+
+	ops := map[string]func() Matrix{
+		"Apply": func() Matrix {
+			out := a.ZerosLike()
+			out.Apply(fn, b)
+			return out
+		},
+		"ApplyWithAlpha": func() Matrix {
+			out := a.ZerosLike()
+			out.ApplyWithAlpha(fnAlpha, b, 3)
+			return out
+		},
+		"Add":               func() Matrix { return a.Add(b) },
+		"AddInPlace":        func() Matrix { return a.Clone().AddInPlace(b) },
+		"Sub":               func() Matrix { return a.Sub(b) },
+		"SubInPlace":        func() Matrix { return a.Clone().SubInPlace(b) },
+		"Prod":              func() Matrix { return a.Prod(b) },
+		"ProdInPlace":       func() Matrix { return a.Clone().ProdInPlace(b) },
+		"Div":               func() Matrix { return a.Div(b) },
+		"DivInPlace":        func() Matrix { return a.Clone().DivInPlace(b) },
+		"AddScalar":         func() Matrix { return a.AddScalar(2) },
+		"SubScalarInPlace":  func() Matrix { return a.Clone().SubScalarInPlace(2) },
+		"ProdScalar":        func() Matrix { return a.ProdScalar(2) },
+		"ProdScalarInPlace": func() Matrix { return a.Clone().ProdScalarInPlace(2) },
+		"Abs":               func() Matrix { return a.Abs() },
+		"Pow":               func() Matrix { return a.Pow(3) },
+		"Sqrt":              func() Matrix { return a.Abs().Sqrt() },
+	}
+
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			SetParallelThreshold(0)
+			expected := op()
+			SetParallelThreshold(1)
+			actual := op()
+			assert.True(t, SameDims(expected, actual))
+			assert.Equal(t, expected.Data(), actual.Data())
+		})
+	}
+}
+
+func TestTuneParallelThreshold(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the benchmark in short mode")
+	}
+	defer SetParallelThreshold(ParallelThreshold())
+
+	threshold := TuneParallelThreshold()
+	assert.Equal(t, threshold, ParallelThreshold())
+	if runtime.GOMAXPROCS(0) == 1 {
+		assert.Equal(t, math.MaxInt64, threshold)
+	}
+	assert.Greater(t, threshold, 0)
+}
+
+func BenchmarkDense_Apply(b *testing.B) {
+	benchmarkParallelElementWise(b, func(x, y *Dense) {
+		x.Apply(func(_, _ int, v Float) Float { return Tanh(v) }, y)
+	})
+}
+
+func BenchmarkDense_AddInPlace(b *testing.B) {
+	benchmarkParallelElementWise(b, func(x, y *Dense) {
+		x.AddInPlace(y)
+	})
+}
+
+// benchmarkParallelElementWise compares the serial and the parallel execution
+// of an element-wise operation, for increasing sizes.
+func benchmarkParallelElementWise(b *testing.B, op func(x, y *Dense)) {
+	defer SetParallelThreshold(ParallelThreshold())
+	for _, size := range []int{1 << 10, 1 << 14, 1 << 18, 1 << 22} {
+		x := NewInitVecDense(size, 0.5)
+		y := NewInitVecDense(size, 0.1)
+		for _, parallel := range []bool{false, true} {
+			b.Run(fmt.Sprintf("size=%d/parallel=%t", size, parallel), func(b *testing.B) {
+				if parallel {
+					SetParallelThreshold(1)
+				} else {
+					SetParallelThreshold(0)
+				}
+				for i := 0; i < b.N; i++ {
+					op(x, y)
+				}
+			})
+		}
+	}
+}
+
+func makeTestData(size int, offset Float) []Float {
+	data := make([]Float, size)
+	for i := range data {
+		data[i] = Float(i%13)*offset - 3
+	}
+	return data
+}
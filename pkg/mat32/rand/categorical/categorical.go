@@ -0,0 +1,125 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package categorical
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"math"
+	"sort"
+)
+
+// Categorical is a source of random indices drawn from a discrete probability
+// distribution over the categories [0, len(Probs)).
+// See: https://en.wikipedia.org/wiki/Categorical_distribution.
+type Categorical struct {
+	// Probs is the normalized probability vector.
+	Probs      []float32
+	cumulative []float64
+	generator  *rand.LockedRand
+}
+
+// New returns a new Categorical, initialized with the given weights.
+// The weights do not need to sum to one, since they are normalized here;
+// however, they must be non-negative and their sum must be positive,
+// otherwise New panics.
+func New(weights []float32, generator *rand.LockedRand) *Categorical {
+	var sum float64
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(float64(w)) {
+			panic("categorical: weights must be non-negative")
+		}
+		sum += float64(w)
+	}
+	if sum <= 0 || math.IsInf(sum, 1) {
+		panic("categorical: the sum of the weights must be positive and finite")
+	}
+	probs := make([]float32, len(weights))
+	cumulative := make([]float64, len(weights))
+	var acc float64
+	for i, w := range weights {
+		probs[i] = float32(float64(w) / sum)
+		acc += float64(w) / sum
+		cumulative[i] = acc
+	}
+	return &Categorical{
+		Probs:      probs,
+		cumulative: cumulative,
+		generator:  generator,
+	}
+}
+
+// Next returns a random index drawn from the distribution.
+func (u Categorical) Next() int {
+	p := float64(u.generator.Float32())
+	i := sort.Search(len(u.cumulative), func(i int) bool {
+		return u.cumulative[i] > p
+	})
+	// the cumulative sum may fall slightly short of one because of rounding
+	// errors: fall back to the last category with non-zero probability
+	if i == len(u.cumulative) {
+		i--
+		for u.Probs[i] == 0 {
+			i--
+		}
+	}
+	return i
+}
+
+// Sample returns n indices drawn independently from the distribution,
+// i.e. sampled with replacement.
+func (u Categorical) Sample(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = u.Next()
+	}
+	return out
+}
+
+// Multinomial returns the number of times each category is selected
+// over n independent trials.
+// See: https://en.wikipedia.org/wiki/Multinomial_distribution.
+func (u Categorical) Multinomial(n int) []int {
+	counts := make([]int, len(u.Probs))
+	for i := 0; i < n; i++ {
+		counts[u.Next()]++
+	}
+	return counts
+}
+
+// SampleWithoutReplacement returns n distinct indices drawn from the distribution,
+// in order of selection. It panics if n is greater than the number of categories
+// with non-zero probability.
+//
+// It implements the weighted random sampling of Efraimidis and Spirakis,
+// "Weighted random sampling with a reservoir" (2006), which is equivalent
+// to taking the top-n of the log-probabilities perturbed with Gumbel noise.
+func (u Categorical) SampleWithoutReplacement(n int) []int {
+	type key struct {
+		index int
+		value float64
+	}
+	keys := make([]key, 0, len(u.Probs))
+	for i, p := range u.Probs {
+		if p == 0 {
+			continue
+		}
+		r := float64(u.generator.Float32())
+		for r == 0 {
+			r = float64(u.generator.Float32())
+		}
+		keys = append(keys, key{index: i, value: math.Log(r) / float64(p)})
+	}
+	if n > len(keys) {
+		panic("categorical: not enough categories with non-zero probability")
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].value > keys[j].value
+	})
+	out := make([]int, n)
+	for i := range out {
+		out[i] = keys[i].index
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dirichlet
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestDirichlet_Next(t *testing.T) {
+	for _, alpha := range [][]float32{
+		{1, 1, 1}, {0.5, 2, 5}, {10},
+	} {
+		d := New(alpha, rand.NewLockedRand(42))
+		n := 20000
+		sum := make([]float64, len(alpha))
+		sqSum := make([]float64, len(alpha))
+		for i := 0; i < n; i++ {
+			x := d.Next()
+			assert.Len(t, x, len(alpha))
+			var total float64
+			for j, v := range x {
+				assert.True(t, v >= 0)
+				total += float64(v)
+				sum[j] += float64(v)
+				sqSum[j] += float64(v) * float64(v)
+			}
+			assert.InDelta(t, 1, total, 1e-5)
+		}
+
+		var alpha0 float64
+		for _, a := range alpha {
+			alpha0 += float64(a)
+		}
+		for j, a := range alpha {
+			mean := sum[j] / float64(n)
+			variance := sqSum[j]/float64(n) - mean*mean
+			expMean := float64(a) / alpha0
+			expVariance := float64(a) * (alpha0 - float64(a)) / (alpha0 * alpha0 * (alpha0 + 1))
+			assert.InDelta(t, expMean, mean, 0.01)
+			assert.InDelta(t, expVariance, variance, expVariance*0.06+1e-9)
+		}
+	}
+}
+
+func TestDirichlet_Next_SmallAlpha(t *testing.T) {
+	d := New([]float32{0.001, 0.001, 0.001}, rand.NewLockedRand(1))
+	for i := 0; i < 1000; i++ {
+		var total float64
+		for _, v := range d.Next() {
+			assert.False(t, math.IsNaN(float64(v)))
+			assert.True(t, v >= 0)
+			total += float64(v)
+		}
+		assert.InDelta(t, 1, total, 1e-5)
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.Panics(t, func() { New(nil, rand.NewLockedRand(42)) })
+	assert.Panics(t, func() { New([]float32{1, 0}, rand.NewLockedRand(42)) })
+}
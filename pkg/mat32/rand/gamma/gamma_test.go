@@ -0,0 +1,49 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamma
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestGamma_Next(t *testing.T) {
+	for _, tc := range []struct{ shape, scale float32 }{
+		{0.5, 1}, {1, 2}, {3, 0.5}, {9, 1},
+	} {
+		g := New(tc.shape, tc.scale, rand.NewLockedRand(42))
+		n := 50000
+		var sum, sqSum float64
+		for i := 0; i < n; i++ {
+			x := float64(g.Next())
+			assert.True(t, x >= 0)
+			sum += x
+			sqSum += x * x
+		}
+		mean := sum / float64(n)
+		variance := sqSum/float64(n) - mean*mean
+		expMean := float64(tc.shape * tc.scale)
+		expVariance := float64(tc.shape * tc.scale * tc.scale)
+		assert.InDelta(t, expMean, mean, expMean*0.03)
+		assert.InDelta(t, expVariance, variance, expVariance*0.06)
+	}
+}
+
+func TestGamma_NextLog(t *testing.T) {
+	for _, shape := range []float32{0.5, 3} {
+		g1 := New(shape, 2, rand.NewLockedRand(42))
+		g2 := New(shape, 2, rand.NewLockedRand(42))
+		for i := 0; i < 100; i++ {
+			assert.InDelta(t, math.Log(float64(g1.Next())), float64(g2.NextLog()), 1e-4)
+		}
+	}
+	// the sample underflows, but not its log
+	g := New(0.001, 1, rand.NewLockedRand(1))
+	for i := 0; i < 100; i++ {
+		assert.False(t, math.IsInf(float64(g.NextLog()), 0))
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gumbel
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"math"
+)
+
+// Gumbel is a source of random numbers following the Gumbel (type-I extreme value)
+// distribution, as used for instance by the Gumbel-Max trick and the Gumbel-Softmax.
+// See: https://en.wikipedia.org/wiki/Gumbel_distribution.
+type Gumbel struct {
+	Mu        float32
+	Beta      float32
+	generator *rand.LockedRand
+}
+
+// New returns a new Gumbel, initialized with the given location (mu) and scale (beta)
+// parameters.
+func New(mu, beta float32, generator *rand.LockedRand) *Gumbel {
+	return &Gumbel{
+		Mu:        mu,
+		Beta:      beta,
+		generator: generator,
+	}
+}
+
+// Next returns a random sample drawn from the distribution.
+func (u Gumbel) Next() float32 {
+	p := float64(u.generator.Float32())
+	for p == 0 {
+		p = float64(u.generator.Float32())
+	}
+	return u.Mu - u.Beta*float32(math.Log(-math.Log(p)))
+}
+
+// Distribution creates a new matrix initialized with Gumbel distribution.
+func Distribution(r, c int, mu, beta float32, generator *rand.LockedRand) mat32.Matrix {
+	out := mat32.NewEmptyDense(r, c)
+	dist := New(mu, beta, generator)
+	data := out.Data()
+	for i := range data {
+		data[i] = dist.Next()
+	}
+	return out
+}
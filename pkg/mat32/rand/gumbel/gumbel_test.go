@@ -0,0 +1,43 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gumbel
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestGumbel_Next(t *testing.T) {
+	const eulerGamma = 0.5772156649015329
+	for _, tc := range []struct{ mu, beta float32 }{
+		{0, 1}, {2, 0.5}, {-1, 3},
+	} {
+		g := New(tc.mu, tc.beta, rand.NewLockedRand(42))
+		n := 50000
+		var sum, sqSum float64
+		for i := 0; i < n; i++ {
+			x := float64(g.Next())
+			assert.False(t, math.IsInf(x, 0) || math.IsNaN(x))
+			sum += x
+			sqSum += x * x
+		}
+		mean := sum / float64(n)
+		variance := sqSum/float64(n) - mean*mean
+		beta := float64(tc.beta)
+		expMean := float64(tc.mu) + beta*eulerGamma
+		expVariance := math.Pi * math.Pi * beta * beta / 6
+		assert.InDelta(t, expMean, mean, beta*0.03)
+		assert.InDelta(t, expVariance, variance, expVariance*0.06)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	m := Distribution(3, 4, 0, 1, rand.NewLockedRand(42))
+	assert.Equal(t, 3, m.Rows())
+	assert.Equal(t, 4, m.Columns())
+	assert.NotEqual(t, m.Data()[0], m.Data()[1])
+}
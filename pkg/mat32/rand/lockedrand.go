@@ -12,18 +12,38 @@ import (
 // LockedRand is an implementation of rand.Rand that is concurrency-safe.
 // It is just a wrap of the standard rand.Rand with its operations protected by a sync.Mutex.
 type LockedRand struct {
-	lk sync.Mutex
-	r  *rand.Rand
+	lk  sync.Mutex
+	r   *rand.Rand
+	src *rand.PCGSource
 }
 
 // NewLockedRand creates a new LockedRand that implements all Rand functions that is safe
 // for concurrent use.
 func NewLockedRand(seed uint64) *LockedRand {
+	src := new(rand.PCGSource)
+	src.Seed(seed)
 	return &LockedRand{
-		r: rand.New(rand.NewSource(seed)),
+		r:   rand.New(src),
+		src: src,
 	}
 }
 
+// MarshalBinary encodes the state of the generator, so that it can be
+// restored with UnmarshalBinary, e.g. to resume a training.
+// The bytes buffered by Read are not included.
+func (lr *LockedRand) MarshalBinary() ([]byte, error) {
+	lr.lk.Lock()
+	defer lr.lk.Unlock()
+	return lr.src.MarshalBinary()
+}
+
+// UnmarshalBinary restores the state of the generator encoded by MarshalBinary.
+func (lr *LockedRand) UnmarshalBinary(data []byte) error {
+	lr.lk.Lock()
+	defer lr.lk.Unlock()
+	return lr.src.UnmarshalBinary(data)
+}
+
 // Seed uses the provided seed value to initialize the generator to a deterministic state.
 // Seed should not be called concurrently with any other Rand method.
 func (lr *LockedRand) Seed(seed uint64) {
@@ -128,6 +148,16 @@ func (lr *LockedRand) NormFloat32() (n float32) {
 	return
 }
 
+// ExpFloat32 returns an exponentially distributed float32 in the range
+// (0, +math.MaxFloat64] with an exponential distribution whose rate parameter
+// (lambda) is 1 and whose mean is 1/lambda (1).
+func (lr *LockedRand) ExpFloat32() (n float32) {
+	lr.lk.Lock()
+	n = float32(lr.r.ExpFloat64())
+	lr.lk.Unlock()
+	return
+}
+
 // Float is an alias for Float32.
 func (lr *LockedRand) Float() (n float32) {
 	return lr.Float32()
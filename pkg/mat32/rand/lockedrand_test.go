@@ -0,0 +1,24 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockedRand_MarshalBinary(t *testing.T) {
+	r := NewLockedRand(42)
+	r.Float()
+	data, err := r.MarshalBinary()
+	require.NoError(t, err)
+	expected := []float32{r.Float(), r.Float32(), r.NormFloat32()}
+
+	restored := NewLockedRand(1)
+	require.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, expected, []float32{restored.Float(), restored.Float32(), restored.NormFloat32()})
+}
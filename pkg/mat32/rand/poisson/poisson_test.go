@@ -0,0 +1,30 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package poisson
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPoisson_Next(t *testing.T) {
+	for _, lambda := range []float32{0.5, 4, 10, 42, 300} {
+		p := New(lambda, rand.NewLockedRand(42))
+		n := 50000
+		var sum, sqSum float64
+		for i := 0; i < n; i++ {
+			x := float64(p.Next())
+			assert.True(t, x >= 0)
+			sum += x
+			sqSum += x * x
+		}
+		mean := sum / float64(n)
+		variance := sqSum/float64(n) - mean*mean
+		assert.InDelta(t, lambda, mean, float64(lambda)*0.03)
+		assert.InDelta(t, lambda, variance, float64(lambda)*0.06)
+	}
+	assert.Equal(t, 0, New(0, rand.NewLockedRand(42)).Next())
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package truncnormal
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"math"
+)
+
+// TruncatedNormal is a source of normally distributed random numbers,
+// bounded within the interval [Min, Max].
+// See: https://en.wikipedia.org/wiki/Truncated_normal_distribution.
+type TruncatedNormal struct {
+	Std       float32
+	Mean      float32
+	Min       float32
+	Max       float32
+	generator *rand.LockedRand
+}
+
+// New returns a new TruncatedNormal, initialized with the given standard deviation,
+// mean, and the lower and upper bounds of the distribution.
+// It panics if min is not lower than max.
+func New(std, mean, min, max float32, generator *rand.LockedRand) *TruncatedNormal {
+	if min >= max {
+		panic("truncnormal: min must be lower than max")
+	}
+	return &TruncatedNormal{
+		Std:       std,
+		Mean:      mean,
+		Min:       min,
+		Max:       max,
+		generator: generator,
+	}
+}
+
+// Next returns a random sample drawn from the distribution.
+// It uses the inverse transform method, so that exactly one uniform
+// number is consumed for each sample, regardless of the bounds.
+func (u TruncatedNormal) Next() float32 {
+	std := float64(u.Std) * math.Sqrt2
+	lo := math.Erf((float64(u.Min) - float64(u.Mean)) / std)
+	hi := math.Erf((float64(u.Max) - float64(u.Mean)) / std)
+	p := lo + float64(u.generator.Float32())*(hi-lo)
+	x := float32(float64(u.Mean) + std*math.Erfinv(p))
+	// guard against rounding errors on the far tails
+	if x < u.Min || math.IsNaN(float64(x)) {
+		return u.Min
+	}
+	if x > u.Max {
+		return u.Max
+	}
+	return x
+}
+
+// Distribution creates a new matrix initialized with a truncated normal distribution.
+func Distribution(r, c int, std, mean, min, max float32, generator *rand.LockedRand) mat32.Matrix {
+	out := mat32.NewEmptyDense(r, c)
+	dist := New(std, mean, min, max, generator)
+	data := out.Data()
+	for i := range data {
+		data[i] = dist.Next()
+	}
+	return out
+}
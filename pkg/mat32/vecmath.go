@@ -0,0 +1,47 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import "github.com/nlpodyssey/spago/pkg/mat32/internal/asm/f32"
+
+// The following functions apply a transcendental function to each element
+// of x, storing the results in dst, which must be at least as long as x.
+// They use vectorized kernels where available (AVX2 on amd64), and split
+// large inputs across multiple goroutines (see SetParallelThreshold).
+//
+// Their results may differ from the ones of the scalar functions (e.g. Exp)
+// by a few units in the last place; denormal numbers are flushed to zero.
+
+// ExpTo sets dst[i] = Exp(x[i]).
+func ExpTo(dst, x []Float) {
+	vectorized(dst, x, f32.ExpTo)
+}
+
+// LogTo sets dst[i] = Log(x[i]).
+func LogTo(dst, x []Float) {
+	vectorized(dst, x, f32.LogTo)
+}
+
+// TanhTo sets dst[i] = Tanh(x[i]).
+func TanhTo(dst, x []Float) {
+	vectorized(dst, x, f32.TanhTo)
+}
+
+// SigmoidTo sets dst[i] = 1 / (1 + Exp(-x[i])).
+func SigmoidTo(dst, x []Float) {
+	vectorized(dst, x, f32.SigmoidTo)
+}
+
+// GELUTo sets dst[i] = 0.5 * x[i] * (1 + Tanh(Sqrt(2/Pi) * (x[i] + 0.044715*x[i]^3))).
+func GELUTo(dst, x []Float) {
+	vectorized(dst, x, f32.GELUTo)
+}
+
+func vectorized(dst, x []Float, fn func(dst, x []Float)) {
+	dst = dst[:len(x)]
+	parallelFor(len(x), func(lo, hi int) {
+		fn(dst[lo:hi], x[lo:hi])
+	})
+}
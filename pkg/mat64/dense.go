@@ -315,11 +315,22 @@ func (d *Dense) ApplyWithAlpha(fn func(i, j int, v Float, alpha ...Float) Float,
 	if !SameDims(d, a) {
 		panic("mat64: incompatible matrix dimensions.")
 	}
-	for i := 0; i < d.rows; i++ {
-		for j := 0; j < d.cols; j++ {
-			d.data[i*d.cols+j] = fn(i, j, a.At(i, j), alpha...)
-		}
+	dData := d.data
+	if aa, ok := a.(*Dense); ok {
+		aData := aa.data
+		parallelFor(len(dData), func(lo, hi int) {
+			for k := lo; k < hi; k++ {
+				dData[k] = fn(k/d.cols, k%d.cols, aData[k], alpha...)
+			}
+		})
+		return
 	}
+	parallelFor(len(dData), func(lo, hi int) {
+		for k := lo; k < hi; k++ {
+			i, j := k/d.cols, k%d.cols
+			dData[k] = fn(i, j, a.At(i, j), alpha...)
+		}
+	})
 }
 
 // Apply executes the unary function fn.
@@ -328,80 +339,93 @@ func (d *Dense) Apply(fn func(i, j int, v Float) Float, a Matrix) {
 		panic("mat64: incompatible matrix dimensions.")
 	}
 	dData := d.data
-	r := 0
-	c := 0
 	switch aa := a.(type) {
 	case *Dense:
 		aData := aa.data
-		lastIndex := len(aData) - 1
-		if lastIndex < 0 {
+		if len(aData) == 0 {
 			return
 		}
-		_ = dData[lastIndex]
-		for i, val := range aData {
-			dData[i] = fn(r, c, val)
-			c++
-			if c == d.cols {
-				r++
-				c = 0
+		_ = dData[len(aData)-1]
+		parallelFor(len(aData), func(lo, hi int) {
+			r, c := lo/d.cols, lo%d.cols
+			for i, val := range aData[lo:hi] {
+				dData[lo+i] = fn(r, c, val)
+				c++
+				if c == d.cols {
+					r++
+					c = 0
+				}
 			}
-		}
+		})
 	default:
-		for i := range dData {
-			dData[i] = fn(r, c, a.At(r, c))
-			c++
-			if c == d.cols {
-				r++
-				c = 0
-			}
+		if len(dData) == 0 {
+			return
 		}
+		parallelFor(len(dData), func(lo, hi int) {
+			r, c := lo/d.cols, lo%d.cols
+			for i := lo; i < hi; i++ {
+				dData[i] = fn(r, c, a.At(r, c))
+				c++
+				if c == d.cols {
+					r++
+					c = 0
+				}
+			}
+		})
 	}
 }
 
 // AddScalar performs the addition between the matrix and the given value.
 func (d *Dense) AddScalar(n Float) Matrix {
 	out := d.Clone().(*Dense)
-	f64.AddConst(n, out.data)
+	addConst(n, out.data)
 	return out
 }
 
 // SubScalar performs a subtraction between the matrix and the given value.
 func (d *Dense) SubScalar(n Float) Matrix {
 	out := d.Clone().(*Dense)
-	f64.AddConst(-n, out.data)
+	addConst(-n, out.data)
 	return out
 }
 
 // AddScalarInPlace adds the scalar to all values of the matrix.
 func (d *Dense) AddScalarInPlace(n Float) Matrix {
-	f64.AddConst(n, d.data)
+	addConst(n, d.data)
 	return d
 }
 
 // SubScalarInPlace subtracts the scalar from the receiver's values.
 func (d *Dense) SubScalarInPlace(n Float) Matrix {
-	f64.AddConst(-n, d.data)
+	addConst(-n, d.data)
 	return d
 }
 
 // ProdScalarInPlace performs the in-place multiplication between the matrix and
 // the given value.
 func (d *Dense) ProdScalarInPlace(n Float) Matrix {
-	f64.ScalUnitary(n, d.data)
+	parallelFor(len(d.data), func(lo, hi int) {
+		f64.ScalUnitary(n, d.data[lo:hi])
+	})
 	return d
 }
 
 // ProdMatrixScalarInPlace multiplies the given matrix with the value, storing the
 // result in the receiver.
 func (d *Dense) ProdMatrixScalarInPlace(m Matrix, n Float) Matrix {
-	f64.ScalUnitaryTo(d.data, n, m.(*Dense).data)
+	mData := m.(*Dense).data
+	parallelFor(len(mData), func(lo, hi int) {
+		f64.ScalUnitaryTo(d.data[lo:hi], n, mData[lo:hi])
+	})
 	return d
 }
 
 // ProdScalar returns the multiplication between the matrix and the given value.
 func (d *Dense) ProdScalar(n Float) Matrix {
 	out := d.ZerosLike().(*Dense)
-	f64.ScalUnitaryTo(out.data, n, d.data)
+	parallelFor(len(d.data), func(lo, hi int) {
+		f64.ScalUnitaryTo(out.data[lo:hi], n, d.data[lo:hi])
+	})
 	return out
 }
 
@@ -414,7 +438,9 @@ func (d *Dense) Add(other Matrix) Matrix {
 	}
 	b := other.(*Dense)
 	out := d.ZerosLike().(*Dense)
-	f64.AxpyUnitaryTo(out.data, 1.0, b.data, d.data)
+	parallelFor(len(b.data), func(lo, hi int) {
+		f64.AxpyUnitaryTo(out.data[lo:hi], 1.0, b.data[lo:hi], d.data[lo:hi])
+	})
 	return out
 }
 
@@ -426,7 +452,9 @@ func (d *Dense) AddInPlace(other Matrix) Matrix {
 		panic("mat64: matrices with not compatible size")
 	}
 	b := other.(*Dense)
-	f64.AxpyUnitary(1.0, b.data, d.data)
+	parallelFor(len(b.data), func(lo, hi int) {
+		f64.AxpyUnitary(1.0, b.data[lo:hi], d.data[lo:hi])
+	})
 	return d
 }
 
@@ -439,7 +467,9 @@ func (d *Dense) Sub(other Matrix) Matrix {
 	}
 	out := d.ZerosLike().(*Dense)
 	b := other.(*Dense)
-	f64.AxpyUnitaryTo(out.data, -1.0, b.data, d.data)
+	parallelFor(len(b.data), func(lo, hi int) {
+		f64.AxpyUnitaryTo(out.data[lo:hi], -1.0, b.data[lo:hi], d.data[lo:hi])
+	})
 	return out
 }
 
@@ -452,7 +482,9 @@ func (d *Dense) SubInPlace(other Matrix) Matrix {
 	}
 	switch other := other.(type) {
 	case *Dense:
-		f64.AxpyUnitary(-1.0, other.data, d.data)
+		parallelFor(len(other.data), func(lo, hi int) {
+			f64.AxpyUnitary(-1.0, other.data[lo:hi], d.data[lo:hi])
+		})
 	case *Sparse:
 		other.DoNonZero(func(i, j int, k Float) {
 			d.Set(i, j, d.At(i, j)-k)
@@ -482,9 +514,11 @@ func (d *Dense) Prod(other Matrix) Matrix {
 	}
 	_ = outData[lastIndex]
 	_ = dData[lastIndex]
-	for i := lastIndex; i >= 0; i-- {
-		outData[i] = dData[i] * bData[i]
-	}
+	parallelFor(len(bData), func(lo, hi int) {
+		for i := hi - 1; i >= lo; i-- {
+			outData[i] = dData[i] * bData[i]
+		}
+	})
 	return out
 }
 
@@ -498,9 +532,11 @@ func (d *Dense) ProdInPlace(other Matrix) Matrix {
 	b := other.(*Dense)
 	bData := b.data
 	dData := d.data
-	for i, val := range bData {
-		dData[i] *= val
-	}
+	parallelFor(len(bData), func(lo, hi int) {
+		for i, val := range bData[lo:hi] {
+			dData[lo+i] *= val
+		}
+	})
 	return d
 }
 
@@ -512,7 +548,10 @@ func (d *Dense) Div(other Matrix) Matrix {
 		panic("mat64: matrices with not compatible size")
 	}
 	out := d.ZerosLike().(*Dense)
-	f64.DivTo(out.data, d.data, other.(*Dense).data)
+	bData := other.(*Dense).data
+	parallelFor(len(d.data), func(lo, hi int) {
+		f64.DivTo(out.data[lo:hi], d.data[lo:hi], bData[lo:hi])
+	})
 	return out
 }
 
@@ -524,9 +563,11 @@ func (d *Dense) DivInPlace(other Matrix) Matrix {
 		panic("mat64: matrices with not compatible size")
 	}
 	b := other.(*Dense)
-	for i, val := range b.data {
-		d.data[i] *= 1.0 / val
-	}
+	parallelFor(len(b.data), func(lo, hi int) {
+		for i, val := range b.data[lo:hi] {
+			d.data[lo+i] *= 1.0 / val
+		}
+	})
 	return d
 }
 
@@ -635,9 +676,11 @@ func (d *Dense) ClipInPlace(min, max Float) Matrix {
 func (d *Dense) Abs() Matrix {
 	out := GetDenseWorkspace(d.Dims())
 	outData := out.data
-	for i, val := range d.data {
-		outData[i] = math.Abs(val)
-	}
+	parallelFor(len(d.data), func(lo, hi int) {
+		for i, val := range d.data[lo:hi] {
+			outData[lo+i] = math.Abs(val)
+		}
+	})
 	return out
 }
 
@@ -646,9 +689,11 @@ func (d *Dense) Abs() Matrix {
 func (d *Dense) Pow(power Float) Matrix {
 	out := GetDenseWorkspace(d.Dims())
 	outData := out.data
-	for i, val := range d.data {
-		outData[i] = math.Pow(val, power)
-	}
+	parallelFor(len(d.data), func(lo, hi int) {
+		for i, val := range d.data[lo:hi] {
+			outData[lo+i] = math.Pow(val, power)
+		}
+	})
 	return out
 }
 
@@ -662,9 +707,11 @@ func (d *Dense) Sqrt() Matrix {
 	}
 	outData := out.data
 	_ = outData[lastIndex]
-	for i, val := range inData {
-		outData[i] = math.Sqrt(val)
-	}
+	parallelFor(len(inData), func(lo, hi int) {
+		for i, val := range inData[lo:hi] {
+			outData[lo+i] = math.Sqrt(val)
+		}
+	})
 	return out
 }
 
@@ -984,3 +1031,10 @@ func (d *Dense) PadRows(n int) Matrix {
 func (d *Dense) String() string {
 	return fmt.Sprintf("%v", d.data)
 }
+
+// addConst adds alpha to all the values of x.
+func addConst(alpha Float, x []Float) {
+	parallelFor(len(x), func(lo, hi int) {
+		f64.AddConst(alpha, x[lo:hi])
+	})
+}
@@ -29,6 +29,7 @@ func (d Dense) MarshalBinary() ([]byte, error) {
 }
 
 // UnmarshalBinary unmarshals a binary representation of a Dense matrix.
+// The values can be encoded with either 32 or 64 bits (see decodeFloats).
 func (d *Dense) UnmarshalBinary(data []byte) error {
 	d.viewOf = nil
 	d.fromPool = false
@@ -36,10 +37,7 @@ func (d *Dense) UnmarshalBinary(data []byte) error {
 	d.cols = int(binary.LittleEndian.Uint32(data[4:]))
 	d.size = d.rows * d.cols
 	d.data = make([]Float, d.size)
-	for i := range d.data {
-		d.data[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8+i*8:]))
-	}
-	return nil
+	return decodeFloats(d.data, data[8:])
 }
 
 // MarshalBinary marshals a Sparse matrix into binary form.
@@ -54,17 +52,38 @@ func (s Sparse) MarshalBinary() ([]byte, error) {
 }
 
 // UnmarshalBinary unmarshals a binary representation of a Sparse matrix.
+// The values can be encoded with either 32 or 64 bits (see decodeFloats).
 func (s *Sparse) UnmarshalBinary(data []byte) error {
 	rows := int(binary.LittleEndian.Uint32(data))
 	cols := int(binary.LittleEndian.Uint32(data[4:]))
 	elements := make([]Float, rows*cols)
-	for i := range elements {
-		elements[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8+i*8:]))
+	if err := decodeFloats(elements, data[8:]); err != nil {
+		return err
 	}
 	*s = *NewSparse(rows, cols, elements)
 	return nil
 }
 
+// decodeFloats decodes the little-endian values of dst from data, converting
+// them to Float. The values can be encoded either with 32 or with 64 bits, as
+// long as the length of data matches, so that the matrices marshaled by mat32
+// can be unmarshaled by mat64, and vice versa.
+func decodeFloats(dst []Float, data []byte) error {
+	switch len(data) {
+	case len(dst) * 4:
+		for i := range dst {
+			dst[i] = Float(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+		}
+	case len(dst) * 8:
+		for i := range dst {
+			dst[i] = Float(math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:])))
+		}
+	default:
+		return fmt.Errorf("mat64: invalid binary length %d for %d values", len(data), len(dst))
+	}
+	return nil
+}
+
 const (
 	binaryNilMatrix byte = iota
 	binaryDenseMatrix
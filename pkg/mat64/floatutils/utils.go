@@ -11,6 +11,26 @@ import (
 	"strings"
 )
 
+// EqualApprox returns true if a and b are equal to within reasonable
+// absolute tolerance (hardcoded as 1.0e-04).
+func EqualApprox(a, b float64) bool {
+	return a == b || math.Abs(a-b) <= 1.0e-04
+}
+
+// SliceEqualApprox returns true if a and b have the same length and EqualApprox
+// is true for each element pair from a and b.
+func SliceEqualApprox(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, va := range a {
+		if !EqualApprox(va, b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // Copy creates and return a copy of the given slice.
 func Copy(in []float64) []float64 {
 	out := make([]float64, len(in))
@@ -0,0 +1,122 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultParallelThreshold is the default minimum number of elements for
+// which the element-wise operations of a Dense matrix are split across
+// multiple goroutines.
+const DefaultParallelThreshold = 1 << 15
+
+// parallelThreshold is accessed atomically.
+var parallelThreshold int64 = DefaultParallelThreshold
+
+// ParallelThreshold returns the minimum number of elements for which the
+// element-wise operations of a Dense matrix are split across multiple
+// goroutines (see SetParallelThreshold).
+func ParallelThreshold() int {
+	return int(atomic.LoadInt64(&parallelThreshold))
+}
+
+// SetParallelThreshold sets the minimum number of elements for which the
+// element-wise operations of a Dense matrix, such as Apply, AddInPlace or
+// ProdInPlace, are split across multiple goroutines (up to GOMAXPROCS).
+// A value lower than or equal to zero disables the parallel execution.
+//
+// Please note that, above the threshold, the functions passed to Apply and
+// ApplyWithAlpha are invoked concurrently.
+func SetParallelThreshold(n int) {
+	if n <= 0 {
+		n = math.MaxInt64
+	}
+	atomic.StoreInt64(&parallelThreshold, int64(n))
+}
+
+// parallelFor calls fn on consecutive chunks [lo, hi) of the range [0, n),
+// concurrently if n is not lower than the parallel threshold.
+func parallelFor(n int, fn func(lo, hi int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers < 2 || int64(n) < atomic.LoadInt64(&parallelThreshold) {
+		fn(0, n)
+		return
+	}
+	chunkSize := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := chunkSize; lo < n; lo += chunkSize {
+		hi := lo + chunkSize
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			fn(lo, hi)
+		}(lo, hi)
+	}
+	fn(0, chunkSize) // the first chunk is processed by the calling goroutine
+	wg.Wait()
+}
+
+// TuneParallelThreshold measures the execution time of some element-wise
+// operations on matrices of increasing size, both serially and in parallel,
+// then sets (and returns) the parallel threshold to the smallest size for
+// which the parallel execution turns out to be faster.
+// If the parallel execution is never faster, it is disabled.
+//
+// It takes a few hundred milliseconds, and it is meant to be called once at
+// the beginning of the program, on an otherwise idle machine.
+func TuneParallelThreshold() int {
+	threshold := 0
+	if runtime.GOMAXPROCS(0) > 1 {
+		for size := 1 << 10; size <= 1<<22; size <<= 1 {
+			serial := benchmarkElementWise(size, math.MaxInt64)
+			parallel := benchmarkElementWise(size, 0)
+			if serial > parallel+parallel/10 { // at least 10% faster, to tolerate some noise
+				threshold = size
+				break
+			}
+		}
+	}
+	SetParallelThreshold(threshold)
+	return ParallelThreshold()
+}
+
+// benchmarkElementWise returns the time spent performing some element-wise
+// operations on vectors of the given size, with the given parallel threshold.
+func benchmarkElementWise(size int, threshold int64) time.Duration {
+	prev := atomic.SwapInt64(&parallelThreshold, threshold)
+	defer atomic.StoreInt64(&parallelThreshold, prev)
+
+	a := GetDenseWorkspace(size, 1)
+	b := GetDenseWorkspace(size, 1)
+	defer ReleaseDense(a)
+	defer ReleaseDense(b)
+	for i := range b.data {
+		b.data[i] = Float(i%100) / 100
+	}
+	fn := func(_, _ int, v Float) Float { return Tanh(v) }
+
+	repetitions := 1 + (1<<20)/size
+	best := time.Duration(math.MaxInt64)
+	for round := 0; round < 3; round++ {
+		start := time.Now()
+		for i := 0; i < repetitions; i++ {
+			a.Apply(fn, b) // overwrites the values of a, so they don't grow indefinitely
+			a.AddInPlace(b)
+			a.ProdInPlace(b)
+		}
+		if elapsed := time.Since(start); elapsed < best {
+			best = elapsed
+		}
+	}
+	return best
+}
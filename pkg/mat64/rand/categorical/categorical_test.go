@@ -0,0 +1,54 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package categorical
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	c := New([]float64{1, 3, 0, 4}, rand.NewLockedRand(42))
+	assert.InDeltaSlice(t, []float64{0.125, 0.375, 0, 0.5}, c.Probs, 1e-6)
+
+	assert.Panics(t, func() { New([]float64{1, -1}, rand.NewLockedRand(42)) })
+	assert.Panics(t, func() { New([]float64{0, 0}, rand.NewLockedRand(42)) })
+}
+
+func TestCategorical_Multinomial(t *testing.T) {
+	c := New([]float64{0.2, 0.3, 0, 0.5}, rand.NewLockedRand(42))
+	n := 100000
+	counts := c.Multinomial(n)
+	assert.Equal(t, 0, counts[2])
+	for i, p := range c.Probs {
+		assert.InDelta(t, p, float64(counts[i])/float64(n), 0.01)
+	}
+}
+
+func TestCategorical_SampleWithoutReplacement(t *testing.T) {
+	c := New([]float64{0.1, 0.2, 0, 0.3, 0.4}, rand.NewLockedRand(42))
+	for i := 0; i < 100; i++ {
+		s := c.SampleWithoutReplacement(4)
+		assert.Len(t, s, 4)
+		assert.ElementsMatch(t, []int{0, 1, 3, 4}, s)
+	}
+	assert.Panics(t, func() { c.SampleWithoutReplacement(5) })
+
+	first := make([]int, 5)
+	n := 20000
+	for i := 0; i < n; i++ {
+		first[c.SampleWithoutReplacement(1)[0]]++
+	}
+	for i, p := range c.Probs {
+		assert.InDelta(t, p, float64(first[i])/float64(n), 0.015)
+	}
+}
+
+func TestCategorical_Seed(t *testing.T) {
+	a := New([]float64{1, 2, 3}, rand.NewLockedRand(7)).Sample(50)
+	b := New([]float64{1, 2, 3}, rand.NewLockedRand(7)).Sample(50)
+	assert.Equal(t, a, b)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dirichlet
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+	"github.com/nlpodyssey/spago/pkg/mat64/rand/gamma"
+	"math"
+)
+
+// Dirichlet is a source of random probability vectors following the Dirichlet
+// distribution.
+// See: https://en.wikipedia.org/wiki/Dirichlet_distribution.
+type Dirichlet struct {
+	Alpha     []float64
+	gammas    []*gamma.Gamma
+	generator *rand.LockedRand
+}
+
+// New returns a new Dirichlet, initialized with the given concentration parameters.
+// It panics if alpha is empty or if any of its values is not positive.
+func New(alpha []float64, generator *rand.LockedRand) *Dirichlet {
+	if len(alpha) == 0 {
+		panic("dirichlet: alpha must not be empty")
+	}
+	gammas := make([]*gamma.Gamma, len(alpha))
+	for i, a := range alpha {
+		gammas[i] = gamma.New(a, 1, generator)
+	}
+	return &Dirichlet{
+		Alpha:     alpha,
+		gammas:    gammas,
+		generator: generator,
+	}
+}
+
+// Next returns a random sample drawn from the distribution, that is a
+// vector of len(Alpha) non-negative values summing to one.
+//
+// The Gamma samples are normalized in log space, since with small
+// concentration parameters they can all underflow to zero.
+func (u Dirichlet) Next() []float64 {
+	logs := make([]float64, len(u.gammas))
+	maxLog := math.Inf(-1)
+	for i, g := range u.gammas {
+		logs[i] = float64(g.NextLog())
+		maxLog = math.Max(maxLog, logs[i])
+	}
+	var sum float64
+	for i, l := range logs {
+		logs[i] = math.Exp(l - maxLog)
+		sum += logs[i]
+	}
+	out := make([]float64, len(logs))
+	for i, v := range logs {
+		out[i] = float64(v / sum)
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gamma
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+	"math"
+)
+
+// Gamma is a source of random numbers following the Gamma distribution.
+// See: https://en.wikipedia.org/wiki/Gamma_distribution.
+type Gamma struct {
+	Shape     float64
+	Scale     float64
+	generator *rand.LockedRand
+}
+
+// New returns a new Gamma, initialized with the given shape (k) and scale (theta)
+// parameters. It panics if any of them is not positive.
+func New(shape, scale float64, generator *rand.LockedRand) *Gamma {
+	if shape <= 0 || scale <= 0 {
+		panic("gamma: shape and scale must be positive")
+	}
+	return &Gamma{
+		Shape:     shape,
+		Scale:     scale,
+		generator: generator,
+	}
+}
+
+// Next returns a random sample drawn from the distribution.
+//
+// The implementation follows the method of Marsaglia and Tsang, "A Simple Method
+// for Generating Gamma Variables" (2000). When the shape is lower than one, the
+// sample is boosted as Gamma(k) = Gamma(k+1) * U^(1/k).
+func (u Gamma) Next() float64 {
+	return sample(u.Shape, u.generator) * u.Scale
+}
+
+// NextLog returns the logarithm of a random sample drawn from the
+// distribution, drawing the same random numbers as Next. Unlike the log of
+// Next, it doesn't underflow to -Inf for small shapes.
+func (u Gamma) NextLog() float64 {
+	return logSample(u.Shape, u.generator) + math.Log(u.Scale)
+}
+
+func sample(shape float64, generator *rand.LockedRand) float64 {
+	if shape < 1 {
+		p := generator.Float64()
+		for p == 0 {
+			p = generator.Float64()
+		}
+		return sample(shape+1, generator) * math.Pow(p, 1/shape)
+	}
+	d := shape - 1.0/3.0
+	c := 1.0 / math.Sqrt(9*d)
+	for {
+		var x, v float64
+		for v <= 0 {
+			x = generator.NormFloat64()
+			v = 1 + c*x
+		}
+		v = v * v * v
+		p := generator.Float64()
+		if p < 1-0.0331*x*x*x*x {
+			return d * v
+		}
+		if p > 0 && math.Log(p) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
+
+// logSample returns the logarithm of a sample, as sample does.
+func logSample(shape float64, generator *rand.LockedRand) float64 {
+	if shape < 1 {
+		p := generator.Float64()
+		for p == 0 {
+			p = generator.Float64()
+		}
+		return logSample(shape+1, generator) + math.Log(p)/shape
+	}
+	return math.Log(sample(shape, generator))
+}
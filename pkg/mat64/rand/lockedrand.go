@@ -12,18 +12,38 @@ import (
 // LockedRand is an implementation of rand.Rand that is concurrency-safe.
 // It is just a wrap of the standard rand.Rand with its operations protected by a sync.Mutex.
 type LockedRand struct {
-	lk sync.Mutex
-	r  *rand.Rand
+	lk  sync.Mutex
+	r   *rand.Rand
+	src *rand.PCGSource
 }
 
 // NewLockedRand creates a new LockedRand that implements all Rand functions that is safe
 // for concurrent use.
 func NewLockedRand(seed uint64) *LockedRand {
+	src := new(rand.PCGSource)
+	src.Seed(seed)
 	return &LockedRand{
-		r: rand.New(rand.NewSource(seed)),
+		r:   rand.New(src),
+		src: src,
 	}
 }
 
+// MarshalBinary encodes the state of the generator, so that it can be
+// restored with UnmarshalBinary, e.g. to resume a training.
+// The bytes buffered by Read are not included.
+func (lr *LockedRand) MarshalBinary() ([]byte, error) {
+	lr.lk.Lock()
+	defer lr.lk.Unlock()
+	return lr.src.MarshalBinary()
+}
+
+// UnmarshalBinary restores the state of the generator encoded by MarshalBinary.
+func (lr *LockedRand) UnmarshalBinary(data []byte) error {
+	lr.lk.Lock()
+	defer lr.lk.Unlock()
+	return lr.src.UnmarshalBinary(data)
+}
+
 // Seed uses the provided seed value to initialize the generator to a deterministic state.
 // Seed should not be called concurrently with any other Rand method.
 func (lr *LockedRand) Seed(seed uint64) {
@@ -0,0 +1,82 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package poisson
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+	"math"
+)
+
+// Poisson is a source of random numbers following the Poisson distribution.
+// See: https://en.wikipedia.org/wiki/Poisson_distribution.
+type Poisson struct {
+	Lambda    float64
+	generator *rand.LockedRand
+}
+
+// New returns a new Poisson, initialized with the given rate (lambda) parameter.
+// It panics if lambda is negative.
+func New(lambda float64, generator *rand.LockedRand) *Poisson {
+	if lambda < 0 {
+		panic("poisson: lambda must be non-negative")
+	}
+	return &Poisson{
+		Lambda:    lambda,
+		generator: generator,
+	}
+}
+
+// Next returns a random sample drawn from the distribution.
+//
+// Small rates are handled with Knuth's multiplication method, while for
+// lambda >= 10 the transformed rejection method with squeeze (PTRS) by
+// Hörmann, "The transformed rejection method for generating Poisson random
+// variables" (1993), is used.
+func (u Poisson) Next() int {
+	lambda := u.Lambda
+	if lambda == 0 {
+		return 0
+	}
+	if lambda < 10 {
+		return u.knuth(lambda)
+	}
+	return u.ptrs(lambda)
+}
+
+func (u Poisson) knuth(lambda float64) int {
+	l := math.Exp(-lambda)
+	k := 0
+	p := u.generator.Float64()
+	for p > l {
+		k++
+		p *= u.generator.Float64()
+	}
+	return k
+}
+
+func (u Poisson) ptrs(lambda float64) int {
+	slam := math.Sqrt(lambda)
+	loglam := math.Log(lambda)
+	b := 0.931 + 2.53*slam
+	a := -0.059 + 0.02483*b
+	invalpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+	for {
+		p := u.generator.Float64() - 0.5
+		v := u.generator.Float64()
+		us := 0.5 - math.Abs(p)
+		k := math.Floor((2*a/us+b)*p + lambda + 0.43)
+		if us >= 0.07 && v <= vr {
+			return int(k)
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		lg, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invalpha)-math.Log(a/(us*us)+b) <= -lambda+k*loglam-lg {
+			return int(k)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+)
+
+// Reservoir selects a simple random sample of K items, without replacement,
+// from a stream of unknown length, in a single pass.
+// See: https://en.wikipedia.org/wiki/Reservoir_sampling.
+type Reservoir struct {
+	K         int
+	items     []interface{}
+	seen      int
+	generator *rand.LockedRand
+}
+
+// New returns a new Reservoir, which keeps at most k items.
+func New(k int, generator *rand.LockedRand) *Reservoir {
+	if k < 0 {
+		panic("reservoir: k must be non-negative")
+	}
+	return &Reservoir{
+		K:         k,
+		items:     make([]interface{}, 0, k),
+		generator: generator,
+	}
+}
+
+// Add offers a new item of the stream to the reservoir.
+// It reports whether the item has been retained (for now).
+func (r *Reservoir) Add(item interface{}) bool {
+	r.seen++
+	if len(r.items) < r.K {
+		r.items = append(r.items, item)
+		return true
+	}
+	if j := r.generator.Intn(r.seen); j < r.K {
+		r.items[j] = item
+		return true
+	}
+	return false
+}
+
+// Seen returns the number of items offered to the reservoir so far.
+func (r *Reservoir) Seen() int {
+	return r.seen
+}
+
+// Items returns the current sample. The returned slice must not be modified.
+func (r *Reservoir) Items() []interface{} {
+	return r.items
+}
+
+// Reset empties the reservoir, so that it can be reused for a new stream.
+func (r *Reservoir) Reset() {
+	r.items = r.items[:0]
+	r.seen = 0
+}
+
+// Sample selects k distinct indices out of [0, n) with reservoir sampling,
+// without allocating the whole range of indices. The indices are returned in
+// no particular order. If k >= n, all the indices are returned.
+func Sample(n, k int, generator *rand.LockedRand) []int {
+	if k > n {
+		k = n
+	}
+	out := make([]int, k)
+	for i := range out {
+		out[i] = i
+	}
+	for i := k; i < n; i++ {
+		if j := generator.Intn(i + 1); j < k {
+			out[j] = i
+		}
+	}
+	return out
+}
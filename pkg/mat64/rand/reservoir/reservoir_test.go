@@ -0,0 +1,48 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReservoir(t *testing.T) {
+	r := New(3, rand.NewLockedRand(42))
+	r.Add("a")
+	r.Add("b")
+	assert.Equal(t, []interface{}{"a", "b"}, r.Items())
+
+	for i := 0; i < 100; i++ {
+		r.Add(i)
+	}
+	assert.Equal(t, 102, r.Seen())
+	assert.Len(t, r.Items(), 3)
+
+	r.Reset()
+	assert.Equal(t, 0, r.Seen())
+	assert.Empty(t, r.Items())
+}
+
+func TestSample(t *testing.T) {
+	generator := rand.NewLockedRand(42)
+	counts := make([]int, 10)
+	trials := 20000
+	for i := 0; i < trials; i++ {
+		s := Sample(10, 3, generator)
+		assert.Len(t, s, 3)
+		seen := map[int]bool{}
+		for _, x := range s {
+			assert.False(t, seen[x])
+			seen[x] = true
+			counts[x]++
+		}
+	}
+	for _, c := range counts {
+		assert.InDelta(t, 0.3, float64(c)/float64(trials), 0.02)
+	}
+	assert.ElementsMatch(t, []int{0, 1}, Sample(2, 5, generator))
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package truncnormal
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTruncatedNormal_Next(t *testing.T) {
+	d := New(1, 0, -2, 2, rand.NewLockedRand(42))
+	n := 50000
+	var sum float64
+	for i := 0; i < n; i++ {
+		x := d.Next()
+		assert.True(t, x >= -2 && x <= 2)
+		sum += x
+	}
+	assert.InDelta(t, 0, sum/float64(n), 0.02)
+
+	// interval far on the tail of the distribution
+	d = New(1, 0, 5, 6, rand.NewLockedRand(42))
+	for i := 0; i < 1000; i++ {
+		x := d.Next()
+		assert.True(t, x >= 5 && x <= 6)
+	}
+
+	assert.Panics(t, func() { New(1, 0, 1, 1, rand.NewLockedRand(42)) })
+}
+
+func TestDistribution(t *testing.T) {
+	m := Distribution(3, 4, 0.02, 0, -0.04, 0.04, rand.NewLockedRand(42))
+	assert.Equal(t, 3, m.Rows())
+	assert.Equal(t, 4, m.Columns())
+	for _, x := range m.Data() {
+		assert.True(t, x >= -0.04 && x <= 0.04)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import "math"
+
+// The following functions apply a transcendental function to each element
+// of x, storing the results in dst, which must be at least as long as x.
+// They split large inputs across multiple goroutines (see SetParallelThreshold).
+//
+// Unlike their mat32 counterparts, they are not vectorized.
+
+// ExpTo sets dst[i] = Exp(x[i]).
+func ExpTo(dst, x []Float) {
+	vectorized(dst, x, math.Exp)
+}
+
+// LogTo sets dst[i] = Log(x[i]).
+func LogTo(dst, x []Float) {
+	vectorized(dst, x, math.Log)
+}
+
+// TanhTo sets dst[i] = Tanh(x[i]).
+func TanhTo(dst, x []Float) {
+	vectorized(dst, x, math.Tanh)
+}
+
+// SigmoidTo sets dst[i] = 1 / (1 + Exp(-x[i])).
+func SigmoidTo(dst, x []Float) {
+	vectorized(dst, x, func(v Float) Float {
+		return 1 / (1 + math.Exp(-v))
+	})
+}
+
+// GELUTo sets dst[i] = 0.5 * x[i] * (1 + Tanh(Sqrt(2/Pi) * (x[i] + 0.044715*x[i]^3))).
+func GELUTo(dst, x []Float) {
+	vectorized(dst, x, func(v Float) Float {
+		return 0.5 * v * (1 + math.Tanh(math.Sqrt(2/math.Pi)*(v+0.044715*v*v*v)))
+	})
+}
+
+func vectorized(dst, x []Float, fn func(Float) Float) {
+	dst = dst[:len(x)]
+	parallelFor(len(x), func(lo, hi int) {
+		for i, v := range x[lo:hi] {
+			dst[lo+i] = fn(v)
+		}
+	})
+}
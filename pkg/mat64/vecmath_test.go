@@ -0,0 +1,68 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVecMath(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	defer SetParallelThreshold(ParallelThreshold())
+
+	x := makeTestData(1001, 0.5)
+	positive := make([]Float, len(x))
+	for i, v := range x {
+		positive[i] = Abs(v) + 0.1
+	}
+
+	tests := map[string]struct {
+		fn func(dst, x []Float)
+		f  func(v Float) Float
+		x  []Float
+	}{
+		"Exp":     {ExpTo, Exp, x},
+		"Log":     {LogTo, Log, positive},
+		"Tanh":    {TanhTo, Tanh, x},
+		"Sigmoid": {SigmoidTo, func(v Float) Float { return 1 / (1 + Exp(-v)) }, x},
+		"GELU": {GELUTo, func(v Float) Float {
+			return 0.5 * v * (1 + Tanh(Sqrt(2/Pi)*(v+0.044715*v*v*v)))
+		}, x},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expected := make([]Float, len(test.x))
+			for i, v := range test.x {
+				expected[i] = test.f(v)
+			}
+			for _, threshold := range []int{0, 1} {
+				SetParallelThreshold(threshold)
+				actual := make([]Float, len(test.x))
+				test.fn(actual, test.x)
+				assert.InDeltaSlice(t, expected, actual, 1.0e-4)
+			}
+		})
+	}
+}
+
+func BenchmarkExpTo(b *testing.B) {
+	x := makeTestData(1<<12, 0.5)
+	dst := make([]Float, len(x))
+	b.Run("scalar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j, v := range x {
+				dst[j] = Exp(v)
+			}
+		}
+	})
+	b.Run("vectorized", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ExpTo(dst, x)
+		}
+	})
+}
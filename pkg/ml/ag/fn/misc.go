@@ -5,7 +5,7 @@
 package fn
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 // Tan is an operator to perform element-wise tangent.
@@ -36,6 +36,7 @@ func NewTanh(x Operand) *Tanh {
 			x:  x,
 			f:  tanh,
 			df: tanhDeriv,
+			vf: mat.TanhTo,
 		},
 	}
 }
@@ -52,6 +53,7 @@ func NewSigmoid(x Operand) *Sigmoid {
 			x:  x,
 			f:  sigmoid,
 			df: sigmoidDeriv,
+			vf: mat.SigmoidTo,
 		},
 	}
 }
@@ -164,6 +166,7 @@ func NewExp(x Operand) *Exp {
 			x:  x,
 			f:  func(i, j int, v mat.Float) mat.Float { return mat.Exp(v) },
 			df: func(i, j int, v mat.Float) mat.Float { return mat.Exp(v) },
+			vf: mat.ExpTo,
 		},
 	}
 }
@@ -180,6 +183,7 @@ func NewLog(x Operand) *Log {
 			x:  x,
 			f:  safeLog,
 			df: safeLogDeriv,
+			vf: safeLogTo,
 		},
 	}
 }
@@ -266,6 +270,7 @@ func NewGELU(x Operand) *GELU {
 			x:  x,
 			f:  gelu,
 			df: geluDeriv,
+			vf: mat.GELUTo,
 		},
 	}
 }
@@ -327,6 +332,16 @@ func safeLog(_, _ int, v mat.Float) mat.Float {
 	panic("ag: invalid log for negative values")
 }
 
+// safeLogTo is the vectorized version of safeLog.
+func safeLogTo(dst, x []mat.Float) {
+	mat.LogTo(dst, x)
+	for i, v := range x {
+		if !(v > 0.0) {
+			dst[i] = safeLog(0, 0, v)
+		}
+	}
+}
+
 func safeLogDeriv(_, _ int, v mat.Float) mat.Float {
 	if v > 0.0 {
 		return 1.0 / v
@@ -5,7 +5,7 @@
 package fn
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -265,3 +265,44 @@ func TestNewGELUForward(t *testing.T) {
 
 	assert.InDeltaSlice(t, []mat.Float{0.5, 0.579522, 0.507979, 0.420478, 0.492021, 1.082964, 1.0, -0.082964, 0.0}, x.grad.Data(), 1.0e-6)
 }
+
+func TestUnaryElementwise_Vectorized(t *testing.T) {
+	data := make([]mat.Float, 37)
+	for i := range data {
+		data[i] = mat.Float(i)/4 - 4
+	}
+	positive := make([]mat.Float, len(data))
+	for i, v := range data {
+		positive[i] = mat.Abs(v)
+	}
+
+	ops := map[string]struct {
+		f func(x Operand) *UnaryElementwise
+		x []mat.Float
+	}{
+		"Exp":     {func(x Operand) *UnaryElementwise { return NewExp(x).UnaryElementwise }, data},
+		"Log":     {func(x Operand) *UnaryElementwise { return NewLog(x).UnaryElementwise }, positive},
+		"Tanh":    {func(x Operand) *UnaryElementwise { return NewTanh(x).UnaryElementwise }, data},
+		"Sigmoid": {func(x Operand) *UnaryElementwise { return NewSigmoid(x).UnaryElementwise }, data},
+		"GELU":    {func(x Operand) *UnaryElementwise { return NewGELU(x).UnaryElementwise }, data},
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			f := op.f(&variable{value: mat.NewVecDense(op.x), requiresGrad: false})
+			assert.NotNil(t, f.vf)
+			expected := make([]mat.Float, len(op.x))
+			for i, v := range op.x {
+				expected[i] = f.f(i, 0, v)
+			}
+			assert.InDeltaSlice(t, expected, f.Forward().Data(), 1.0e-4)
+		})
+	}
+}
+
+func TestSafeLog_VectorizedNegative(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, -0.1}),
+		requiresGrad: false,
+	}
+	assert.Panics(t, func() { NewLog(x).Forward() })
+}
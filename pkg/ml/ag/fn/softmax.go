@@ -5,7 +5,7 @@
 package fn
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 var _ Function = &Softmax{}
@@ -63,11 +63,13 @@ func max(v []mat.Float) (m mat.Float) {
 
 func softmax(v []mat.Float) []mat.Float {
 	maximum := max(v)
-	var sum mat.Float = 0.0
 	out := make([]mat.Float, len(v))
 	for i, x := range v {
-		e := mat.Exp(x - maximum)
-		out[i] = e
+		out[i] = x - maximum
+	}
+	mat.ExpTo(out, out)
+	var sum mat.Float = 0.0
+	for _, e := range out {
 		sum += e
 	}
 	for i := range v {
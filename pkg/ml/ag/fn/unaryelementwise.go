@@ -5,7 +5,7 @@
 package fn
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 var _ Function = &UnaryElementwise{}
@@ -15,12 +15,18 @@ type UnaryElementwise struct {
 	x  Operand
 	f  func(i, j int, v mat.Float) mat.Float // function
 	df func(i, j int, v mat.Float) mat.Float // derivative
+	vf func(dst, x []mat.Float)              // vectorized function (optional)
 }
 
 // Forward computes the output of this node.
 func (r *UnaryElementwise) Forward() mat.Matrix {
-	y := mat.GetDenseWorkspace(r.x.Value().Dims())
-	y.Apply(r.f, r.x.Value())
+	x := r.x.Value()
+	y := mat.GetDenseWorkspace(x.Dims())
+	if xd, ok := x.(*mat.Dense); ok && r.vf != nil {
+		r.vf(y.Data(), xd.Data())
+		return y
+	}
+	y.Apply(r.f, x)
 	return y
 }
 
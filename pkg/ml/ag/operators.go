@@ -6,7 +6,7 @@ package ag
 
 import (
 	"fmt"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
 	"reflect"
 	"strings"
@@ -0,0 +1,229 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package checkpoint saves and restores the whole state of a training, so
+// that it can be resumed exactly where it stopped: the params of the model,
+// the payloads of the optimizer, the time steps of the optimization methods,
+// the epochs of the param groups, the state of the random generator, the
+// averaged params of an EMA, SWA or Lookahead optimizer, the state of a
+// learning rate schedule such as ReduceOnPlateau, and any other counter of
+// the training loop, such as the steps of a learning rate scheduler or the
+// position in the training data.
+package checkpoint
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/averaging"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+// Checkpoint is the state of a training.
+type Checkpoint struct {
+	// Model contains the params of the model, in the state dict format
+	// (see nn.WriteStateDict).
+	Model []byte
+	// Payloads are the optimizer payloads, by param name (see nn.ForEachNamedParam).
+	Payloads map[string]*nn.Payload
+	// TimeSteps are the time steps of the optimization methods (see
+	// gd.GradientDescent.TimeSteps).
+	TimeSteps []int
+	// ParamGroups is the state of the param groups of the optimizer (see
+	// gd.GradientDescent.ParamGroupsState), i.e. their epochs and their
+	// decayed learning rates.
+	ParamGroups *gd.ParamGroupsState
+	// Rand is the state of the random generator.
+	Rand []byte
+	// Averaging is the state of the optional averaging optimizer (see
+	// averaging.Averager), i.e. its steps and the averaged params.
+	Averaging *averaging.State
+	// Schedule is the state of the optional learning rate schedule depending
+	// on the training history, such as schedule.ReduceOnPlateau.
+	Schedule []byte
+	// Counters are the other counters of the training, by name, e.g. the
+	// steps of a scheduler, or the position in the training data.
+	Counters map[string]int
+}
+
+// New returns the Checkpoint of a model and its optimizer, which can be nil.
+func New(m nn.Model, optimizer *gd.GradientDescent) (*Checkpoint, error) {
+	c := &Checkpoint{
+		Payloads: make(map[string]*nn.Payload),
+		Counters: make(map[string]int),
+	}
+	buf := new(bytes.Buffer)
+	if err := nn.WriteStateDict(m, buf); err != nil {
+		return nil, err
+	}
+	c.Model = buf.Bytes()
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		if payload := param.Payload(); payload != nil {
+			c.Payloads[name] = payload
+		}
+	})
+	if optimizer != nil {
+		c.TimeSteps = optimizer.TimeSteps()
+		state := optimizer.ParamGroupsState()
+		c.ParamGroups = &state
+	}
+	return c, nil
+}
+
+// SetRand records the state of the random generator.
+func (c *Checkpoint) SetRand(r *rand.LockedRand) error {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	c.Rand = data
+	return nil
+}
+
+// SetAverager records the state of an averaging optimizer (EMA, SWA or
+// Lookahead) wrapping the optimizer of the training.
+func (c *Checkpoint) SetAverager(a averaging.Averager) {
+	state := a.State()
+	c.Averaging = &state
+}
+
+// SetSchedule records the state of a learning rate schedule, such as
+// schedule.ReduceOnPlateau.
+func (c *Checkpoint) SetSchedule(s encoding.BinaryMarshaler) error {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	c.Schedule = data
+	return nil
+}
+
+// Restore restores the params of the model and the state of its optimizer,
+// which can be nil. The model must have exactly the params of the
+// checkpoint (see nn.LoadStateDict).
+func (c *Checkpoint) Restore(m nn.Model, optimizer *gd.GradientDescent) error {
+	entries, err := nn.ReadStateDict(bytes.NewReader(c.Model))
+	if err != nil {
+		return err
+	}
+	// replacing the values clears the payloads, which are restored afterwards
+	if _, err := nn.LoadStateDictEntries(m, entries, true); err != nil {
+		return err
+	}
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		if payload, ok := c.Payloads[name]; ok {
+			param.SetPayload(payload)
+		}
+	})
+	if optimizer != nil && c.TimeSteps != nil {
+		optimizer.SetTimeSteps(c.TimeSteps)
+	}
+	if optimizer != nil && c.ParamGroups != nil {
+		if err := optimizer.SetParamGroupsState(*c.ParamGroups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreRand restores the state of the random generator.
+func (c *Checkpoint) RestoreRand(r *rand.LockedRand) error {
+	if c.Rand == nil {
+		return fmt.Errorf("checkpoint: missing random generator state")
+	}
+	return r.UnmarshalBinary(c.Rand)
+}
+
+// RestoreAverager restores the state of an averaging optimizer.
+func (c *Checkpoint) RestoreAverager(a averaging.Averager) error {
+	if c.Averaging == nil {
+		return fmt.Errorf("checkpoint: missing averaging state")
+	}
+	return a.SetState(*c.Averaging)
+}
+
+// RestoreSchedule restores the state of a learning rate schedule. The
+// learning rates must be updated afterwards (see schedule.Scheduler.Update).
+func (c *Checkpoint) RestoreSchedule(s encoding.BinaryUnmarshaler) error {
+	if c.Schedule == nil {
+		return fmt.Errorf("checkpoint: missing schedule state")
+	}
+	return s.UnmarshalBinary(c.Schedule)
+}
+
+// SaveTraining saves the state of a training to a file (see Save): the
+// params of the model, the state of the optimizer, which can be nil, the
+// state of the random generator, and the given counters.
+func SaveTraining(filename string, m nn.Model, optimizer *gd.GradientDescent, r *rand.LockedRand, counters map[string]int) error {
+	c, err := New(m, optimizer)
+	if err != nil {
+		return err
+	}
+	if err := c.SetRand(r); err != nil {
+		return err
+	}
+	for name, value := range counters {
+		c.Counters[name] = value
+	}
+	return Save(filename, c)
+}
+
+// ResumeTraining restores the state of a training saved by SaveTraining, if
+// the file exists, returning its checkpoint to read the counters from.
+// It returns nil, without errors, if the filename is empty or the file
+// doesn't exist, i.e. if the training starts from scratch.
+func ResumeTraining(filename string, m nn.Model, optimizer *gd.GradientDescent, r *rand.LockedRand) (*Checkpoint, error) {
+	if filename == "" || !Exists(filename) {
+		return nil, nil
+	}
+	c, err := Load(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Restore(m, optimizer); err != nil {
+		return nil, err
+	}
+	if err := c.RestoreRand(r); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the checkpoint to a file. The file is replaced atomically, so
+// that a crash during the writing doesn't corrupt the previous checkpoint.
+func Save(filename string, c *Checkpoint) error {
+	tmp := filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err := utils.SerializeToFile(tmp, c); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// Load reads a checkpoint written by Save.
+func Load(filename string) (*Checkpoint, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c := new(Checkpoint)
+	if err := gob.NewDecoder(f).Decode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Exists reports whether the checkpoint file exists.
+func Exists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/averaging"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/decay/exponential"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/schedule"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+type trainer struct {
+	model     *testModel
+	optimizer *gd.GradientDescent
+	ema       *averaging.EMA // optional
+	rndGen    *rand.LockedRand
+	step      int
+}
+
+func newTrainer() *trainer {
+	m := &testModel{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2, 3})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{0.5})),
+	}
+	optimizer := gd.NewOptimizer(adam.New(adam.NewDefaultConfig()), nn.NewDefaultParamsIterator(m))
+	return &trainer{model: m, optimizer: optimizer, rndGen: rand.NewLockedRand(42)}
+}
+
+// train performs n steps with random gradients.
+func (t *trainer) train(n int) {
+	for i := 0; i < n; i++ {
+		nn.ForEachParam(t.model, func(param nn.Param) {
+			grad := mat.NewEmptyDense(param.Value().Dims())
+			for k := range grad.Data() {
+				grad.Data()[k] = t.rndGen.Float() - 0.5
+			}
+			param.PropagateGrad(grad)
+		})
+		t.optimizer.IncExample()
+		if t.ema != nil {
+			t.ema.Optimize()
+		} else {
+			t.optimizer.Optimize()
+		}
+		t.step++
+	}
+}
+
+func TestCheckpoint_Resume(t *testing.T) {
+	reference := newTrainer()
+	reference.train(5)
+
+	c, err := New(reference.model, reference.optimizer)
+	require.NoError(t, err)
+	require.NoError(t, c.SetRand(reference.rndGen))
+	c.Counters["step"] = reference.step
+	filename := filepath.Join(t.TempDir(), "checkpoint.bin")
+	require.NoError(t, Save(filename, c))
+	assert.True(t, Exists(filename))
+
+	reference.train(5)
+
+	loaded, err := Load(filename)
+	require.NoError(t, err)
+	resumed := newTrainer()
+	require.NoError(t, loaded.Restore(resumed.model, resumed.optimizer))
+	require.NoError(t, loaded.RestoreRand(resumed.rndGen))
+	resumed.step = loaded.Counters["step"]
+	assert.Equal(t, 5, resumed.step)
+	assert.Equal(t, []int{6}, resumed.optimizer.TimeSteps())
+	resumed.train(5)
+
+	assert.Equal(t, reference.model.W.Value().Data(), resumed.model.W.Value().Data())
+	assert.Equal(t, reference.model.B.Value().Data(), resumed.model.B.Value().Data())
+}
+
+func TestCheckpoint_RestoreMismatch(t *testing.T) {
+	c, err := New(newTrainer().model, nil)
+	require.NoError(t, err)
+	other := &testModel{W: nn.NewParam(mat.NewVecDense([]mat.Float{1})), B: nn.NewParam(mat.NewScalar(0))}
+	assert.Error(t, c.Restore(other, nil))
+	assert.Error(t, c.RestoreRand(rand.NewLockedRand(1)))
+}
+
+func TestCheckpoint_Averager(t *testing.T) {
+	reference := newTrainer()
+	reference.ema = averaging.NewEMA(reference.optimizer, reference.model, 0.9)
+	reference.train(3)
+
+	c, err := New(reference.model, reference.optimizer)
+	require.NoError(t, err)
+	assert.Error(t, c.RestoreAverager(reference.ema))
+	c.SetAverager(reference.ema)
+	require.NoError(t, c.SetRand(reference.rndGen))
+	filename := filepath.Join(t.TempDir(), "checkpoint.bin")
+	require.NoError(t, Save(filename, c))
+
+	loaded, err := Load(filename)
+	require.NoError(t, err)
+	resumed := newTrainer()
+	resumed.ema = averaging.NewEMA(resumed.optimizer, resumed.model, 0.9)
+	require.NoError(t, loaded.Restore(resumed.model, resumed.optimizer))
+	require.NoError(t, loaded.RestoreAverager(resumed.ema))
+	require.NoError(t, loaded.RestoreRand(resumed.rndGen))
+	assert.Equal(t, 3, resumed.ema.Steps)
+
+	reference.train(2)
+	resumed.train(2)
+	assert.Equal(t, reference.ema.Averaged()["w"].Data(), resumed.ema.Averaged()["w"].Data())
+	assert.Equal(t, reference.ema.Averaged()["b"].Data(), resumed.ema.Averaged()["b"].Data())
+}
+
+func TestSaveTraining(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checkpoint.bin")
+	resumed := newTrainer()
+	for _, name := range []string{"", filename} {
+		c, err := ResumeTraining(name, resumed.model, resumed.optimizer, resumed.rndGen)
+		assert.NoError(t, err)
+		assert.Nil(t, c, "no checkpoint")
+	}
+
+	reference := newTrainer()
+	reference.train(3)
+	require.NoError(t, SaveTraining(filename, reference.model, reference.optimizer, reference.rndGen, map[string]int{"step": 3}))
+	reference.train(2)
+
+	c, err := ResumeTraining(filename, resumed.model, resumed.optimizer, resumed.rndGen)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, 3, c.Counters["step"])
+	resumed.train(2)
+	assert.Equal(t, reference.model.W.Value().Data(), resumed.model.W.Value().Data())
+}
+
+func TestCheckpoint_ParamGroupsAndSchedule(t *testing.T) {
+	type groupsTrainer struct {
+		*trainer
+		biases  *sgd.SGD
+		plateau *schedule.ReduceOnPlateau
+	}
+	newGroupsTrainer := func() *groupsTrainer {
+		t := newTrainer()
+		biases := sgd.New(sgd.NewConfig(0.1, 0, false))
+		t.optimizer = gd.NewOptimizer(adam.New(adam.NewDefaultConfig()), nn.NewDefaultParamsIterator(t.model),
+			gd.WithParamGroups(t.model, gd.ParamGroup{
+				Types:  []nn.ParamsType{nn.Biases},
+				Method: biases,
+				Decay:  exponential.New(0.1, 0.01, 10),
+			}))
+		c := schedule.NewDefaultPlateauConfig()
+		c.Patience = 0
+		return &groupsTrainer{trainer: t, biases: biases, plateau: schedule.NewReduceOnPlateau(c)}
+	}
+	// epoch performs 2 steps, followed by a new epoch with a decreasing metric.
+	epoch := func(t *groupsTrainer, metric mat.Float) {
+		t.train(2)
+		t.optimizer.IncEpoch()
+		t.plateau.Observe(metric)
+	}
+
+	reference := newGroupsTrainer()
+	epoch(reference, 1)
+	epoch(reference, 2)
+
+	c, err := New(reference.model, reference.optimizer)
+	require.NoError(t, err)
+	require.NoError(t, c.SetRand(reference.rndGen))
+	require.NoError(t, c.SetSchedule(reference.plateau))
+	filename := filepath.Join(t.TempDir(), "checkpoint.bin")
+	require.NoError(t, Save(filename, c))
+
+	loaded, err := Load(filename)
+	require.NoError(t, err)
+	resumed := newGroupsTrainer()
+	require.NoError(t, loaded.Restore(resumed.model, resumed.optimizer))
+	require.NoError(t, loaded.RestoreRand(resumed.rndGen))
+	require.NoError(t, loaded.RestoreSchedule(resumed.plateau))
+	assert.Equal(t, reference.biases.LR, resumed.biases.LR)
+	assert.Equal(t, reference.optimizer.ParamGroupsState(), resumed.optimizer.ParamGroupsState())
+	assert.Equal(t, 1, resumed.plateau.Reductions())
+
+	epoch(reference, 3)
+	epoch(resumed, 3)
+	assert.Equal(t, reference.biases.LR, resumed.biases.LR)
+	assert.Equal(t, reference.plateau.Factor(0), resumed.plateau.Factor(0))
+	assert.Equal(t, reference.model.W.Value().Data(), resumed.model.W.Value().Data())
+	assert.Equal(t, reference.model.B.Value().Data(), resumed.model.B.Value().Data())
+	assert.Error(t, new(Checkpoint).RestoreSchedule(resumed.plateau))
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dataparallel implements data-parallel training: each batch is split
+// into shards, which are processed concurrently by one processor per worker,
+// each on its own graph. The gradients of the shards are then added up in the
+// params of the model, always in the order of the shards, so that the result
+// doesn't depend on the scheduling of the goroutines.
+package dataparallel
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+// Func processes the examples of a batch in the range [start, end) with the
+// given processor, which is reified for training on a graph of its own, and
+// returns the loss to back-propagate. It can return nil if there is nothing
+// to learn from the shard.
+type Func func(proc nn.Model, start, end int) ag.Node
+
+// DataParallel runs the forward and backward steps of a model on several
+// goroutines. The optimization step is up to the caller, after Run.
+type DataParallel struct {
+	model     nn.Model
+	workers   int
+	graphOpts []ag.GraphOption
+}
+
+// New returns a new DataParallel for the model m, with the given number of
+// workers (runtime.NumCPU() if zero). Each worker builds its graph with
+// the given options. Since the parallelism is among the workers, the graphs
+// are sequential by default, i.e. with ag.ConcurrentComputations(1).
+func New(m nn.Model, workers int, opts ...ag.GraphOption) *DataParallel {
+	if workers < 0 {
+		panic("dataparallel: the number of workers must be positive")
+	}
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	return &DataParallel{
+		model:     m,
+		workers:   workers,
+		graphOpts: append([]ag.GraphOption{ag.ConcurrentComputations(1)}, opts...),
+	}
+}
+
+// Workers returns the number of workers.
+func (d *DataParallel) Workers() int {
+	return d.workers
+}
+
+// Run splits a batch of size n into contiguous shards, one per worker, and
+// calls fn on each shard concurrently, back-propagating the losses. Then it
+// adds the gradients of the shards to the params of the model, in order, and
+// returns the sum of the losses.
+func (d *DataParallel) Run(n int, fn Func) mat.Float {
+	shards := Shards(n, d.workers)
+	losses := make([]mat.Float, len(shards))
+	grads := make([]*nn.LocalGrads, len(shards))
+
+	var wg sync.WaitGroup
+	wg.Add(len(shards))
+	for i, shard := range shards {
+		go func(i int, start, end int) {
+			defer wg.Done()
+			g := ag.NewGraph(d.graphOpts...)
+			defer g.Clear()
+			proc, localGrads := nn.ReifyWithLocalGrads(d.model, g, nn.Training)
+			grads[i] = localGrads
+			loss := fn(proc, start, end)
+			if loss == nil {
+				return
+			}
+			g.Backward(loss)
+			losses[i] = loss.ScalarValue()
+		}(i, shard[0], shard[1])
+	}
+	wg.Wait()
+
+	total := mat.Float(0)
+	for i := range shards {
+		grads[i].Flush()
+		total += losses[i]
+	}
+	return total
+}
+
+// Shards splits the range [0, n) into at most k contiguous shards of almost
+// the same size, returned as [start, end) pairs.
+func Shards(n, k int) [][2]int {
+	if k > n {
+		k = n
+	}
+	shards := make([][2]int, k)
+	start := 0
+	for i := range shards {
+		size := n / k
+		if i < n%k {
+			size++
+		}
+		shards[i] = [2]int{start, start + size}
+		start += size
+	}
+	return shards
+}
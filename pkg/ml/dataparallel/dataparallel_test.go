@@ -0,0 +1,90 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataparallel
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+func (m *testModel) forward(x []mat.Float) ag.Node {
+	g := m.Graph()
+	return g.Add(g.Dot(m.W, g.NewVariable(mat.NewVecDense(x), false)), m.B)
+}
+
+func newTestModel() *testModel {
+	return &testModel{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{0.5, -0.2, 0.1})),
+		B: nn.NewParam(mat.NewScalar(0.3)),
+	}
+}
+
+var (
+	xs = [][]mat.Float{{1, 2, 3}, {0, 1, 0}, {-1, 0.5, 2}, {2, 2, 2}, {0.3, -1, 0}, {1, 0, -1}, {0, 0, 1}}
+	ys = []mat.Float{1, 0, -1, 2, 0.5, 0, 1}
+)
+
+func lossFunc(proc nn.Model, start, end int) ag.Node {
+	m := proc.(*testModel)
+	g := m.Graph()
+	var loss ag.Node
+	for i := start; i < end; i++ {
+		loss = g.Add(loss, losses.MSE(g, m.forward(xs[i]), g.NewScalar(ys[i]), false))
+	}
+	return loss
+}
+
+func TestDataParallel_Run(t *testing.T) {
+	reference := newTestModel()
+	g := ag.NewGraph()
+	loss := lossFunc(nn.ReifyForTraining(reference, g), 0, len(xs))
+	g.Backward(loss)
+
+	m := newTestModel()
+	d := New(m, 3)
+	assert.Equal(t, 3, d.Workers())
+	total := d.Run(len(xs), lossFunc)
+
+	assert.InDelta(t, loss.ScalarValue(), total, 1e-5)
+	assert.InDeltaSlice(t, reference.W.Grad().Data(), m.W.Grad().Data(), 1e-5)
+	assert.InDeltaSlice(t, reference.B.Grad().Data(), m.B.Grad().Data(), 1e-5)
+
+	// the sum is deterministic
+	grad := m.W.Grad().Clone()
+	for i := 0; i < 10; i++ {
+		m.W.ZeroGrad()
+		d.Run(len(xs), lossFunc)
+		assert.Equal(t, grad.Data(), m.W.Grad().Data())
+	}
+}
+
+func TestDataParallel_Run_NilLoss(t *testing.T) {
+	m := newTestModel()
+	total := New(m, 4).Run(len(xs), func(proc nn.Model, start, end int) ag.Node {
+		if start > 0 {
+			return nil
+		}
+		return lossFunc(proc, start, end)
+	})
+	assert.Greater(t, float64(total), 0.0)
+	assert.True(t, m.W.HasGrad())
+}
+
+func TestShards(t *testing.T) {
+	assert.Equal(t, [][2]int{{0, 3}, {3, 5}, {5, 7}}, Shards(7, 3))
+	assert.Equal(t, [][2]int{{0, 1}, {1, 2}}, Shards(2, 4))
+	assert.Empty(t, Shards(0, 4))
+}
@@ -5,7 +5,7 @@
 package fofe
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"sort"
 )
 
@@ -5,7 +5,7 @@
 package pe
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 // SinusoidalPositionalEncoder uses the sine and cosine functions of different frequencies to compose position embeddings so to
@@ -7,7 +7,7 @@ package graphviz
 import (
 	"fmt"
 	"github.com/awalterschulze/gographviz"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
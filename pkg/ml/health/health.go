@@ -0,0 +1,350 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package health monitors the params and the gradients of a model during the
+// training, to detect problems such as exploding or vanishing gradients, non
+// finite values, or dead units, before the loss diverges.
+//
+// A Monitor hooks into a gd.GradientDescent (see Monitor.Options) and, every
+// few optimization steps, computes the statistics of each param: the mean,
+// the standard deviation and the maximum absolute value of its values, the
+// norm of its gradients, and the ratio between the norm of its update and the
+// norm of its values. The activations of the layers, e.g. of a ReLU, can be
+// observed as well, to compute the fraction of units which were never active.
+package health
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+// Config provides configuration settings for a Monitor.
+type Config struct {
+	// Interval is the number of optimization steps between two reports.
+	// If 0, a report is made at each step.
+	Interval int
+	// ExplodingThreshold, if not zero, is the norm of the gradients of a param
+	// above which the gradients are reported as exploding.
+	ExplodingThreshold mat.Float
+	// VanishingThreshold, if not zero, is the norm of the gradients of a param
+	// below which the gradients are reported as vanishing.
+	VanishingThreshold mat.Float
+	// DeadThreshold, if not zero, is the fraction of dead units of the
+	// observed activations above which an alert is raised.
+	DeadThreshold mat.Float
+	// OnReport, if not nil, is called with each report.
+	OnReport func(r *Report)
+	// OnAlert is called with each alert. If nil, the alerts are logged.
+	OnAlert func(a Alert)
+}
+
+// ParamStats contains the statistics of a param.
+type ParamStats struct {
+	Name   string
+	Mean   mat.Float
+	Std    mat.Float
+	MaxAbs mat.Float
+	// GradNorm is the L2 norm of the gradients, before the clipping.
+	GradNorm mat.Float
+	// UpdateRatio is the L2 norm of the update divided by the L2 norm of the
+	// values (zero if the param wasn't updated).
+	UpdateRatio mat.Float
+}
+
+// ActivationStats contains the statistics of the observed activations of a layer.
+type ActivationStats struct {
+	Name string
+	// Units is the number of units.
+	Units int
+	// DeadFraction is the fraction of units which were never positive since
+	// the last report.
+	DeadFraction mat.Float
+}
+
+// Report contains the statistics computed at an optimization step.
+type Report struct {
+	Step        int
+	Params      []ParamStats
+	Activations []ActivationStats
+	Alerts      []Alert
+}
+
+// AlertKind is the kind of an Alert.
+type AlertKind int
+
+const (
+	// ExplodingGradients means that the norm of the gradients is above ExplodingThreshold.
+	ExplodingGradients AlertKind = iota
+	// VanishingGradients means that the norm of the gradients is below VanishingThreshold.
+	VanishingGradients
+	// NonFiniteGradients means that the gradients contain NaN or infinite values.
+	NonFiniteGradients
+	// NonFiniteValues means that the values of a param contain NaN or infinite values.
+	NonFiniteValues
+	// DeadUnits means that the fraction of dead units is above DeadThreshold.
+	DeadUnits
+)
+
+// String returns a description of the kind of alert.
+func (k AlertKind) String() string {
+	switch k {
+	case ExplodingGradients:
+		return "exploding gradients"
+	case VanishingGradients:
+		return "vanishing gradients"
+	case NonFiniteGradients:
+		return "non-finite gradients"
+	case NonFiniteValues:
+		return "non-finite values"
+	case DeadUnits:
+		return "dead units"
+	default:
+		return "unknown"
+	}
+}
+
+// Alert reports a problem of a param, or of the activations of a layer.
+type Alert struct {
+	Step int
+	Kind AlertKind
+	// Name is the name of the param or of the activations.
+	Name string
+	// Value is the value which raised the alert, e.g. the norm of the gradients.
+	Value mat.Float
+}
+
+// String returns a description of the alert.
+func (a Alert) String() string {
+	return fmt.Sprintf("step %d: %s in %q (%g)", a.Step, a.Kind, a.Name, a.Value)
+}
+
+// Monitor monitors the health of the params of a model.
+type Monitor struct {
+	Config
+	names map[nn.Param]string
+	mu    sync.Mutex
+	step  int
+	// pending are the statistics of the current step, if it is reported.
+	pending     map[nn.Param]*ParamStats
+	alerts      []Alert
+	activations map[string]*activations
+	lastReport  *Report
+}
+
+// activations tracks which units of a layer have been active.
+type activations struct {
+	name  string
+	alive []bool
+}
+
+// New returns a new Monitor of the params of the model m.
+func New(m nn.Model, config Config) *Monitor {
+	if config.Interval < 1 {
+		config.Interval = 1
+	}
+	names := make(map[nn.Param]string)
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		names[param] = name
+	})
+	return &Monitor{
+		Config:      config,
+		names:       names,
+		activations: make(map[string]*activations),
+	}
+}
+
+// Options returns the options to install the monitor in a gd.GradientDescent.
+func (mo *Monitor) Options() []gd.Option {
+	return []gd.Option{
+		gd.WithGradientsCallback(mo.observeGradients),
+		gd.WithUpdatesCallback(mo.observeUpdates),
+	}
+}
+
+// LastReport returns the last report, or nil.
+func (mo *Monitor) LastReport() *Report {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	return mo.lastReport
+}
+
+// ObserveActivations records the activations of a layer, e.g. the output of
+// a ReLU, given by name. The values are either a vector, with an element for
+// each unit, or a matrix with a row for each example and a column for each
+// unit, so that the number of rows may change from a batch to another.
+// A unit whose activations are never positive until the next report is
+// counted as dead. It is safe to call it concurrently.
+func (mo *Monitor) ObserveActivations(name string, values mat.Matrix) {
+	units := values.Columns()
+	if values.IsVector() {
+		units = values.Size()
+	}
+	data := values.Data()
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	a, ok := mo.activations[name]
+	if !ok || len(a.alive) != units {
+		a = &activations{name: name, alive: make([]bool, units)}
+		mo.activations[name] = a
+	}
+	for i, v := range data {
+		if v > 0 {
+			a.alive[i%units] = true
+		}
+	}
+}
+
+// observeGradients computes the statistics of the gradients, if the step is reported.
+func (mo *Monitor) observeGradients(params []nn.Param) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	mo.step++
+	if mo.step%mo.Interval != 0 {
+		mo.pending = nil
+		return
+	}
+	mo.pending = make(map[nn.Param]*ParamStats, len(params))
+	mo.alerts = nil
+	for _, param := range params {
+		name, ok := mo.names[param]
+		if !ok {
+			continue
+		}
+		stats := &ParamStats{Name: name}
+		mo.pending[param] = stats
+		if !param.HasGrad() {
+			continue
+		}
+		grad := param.Grad().Data()
+		if !allFinite(grad) {
+			mo.alert(NonFiniteGradients, name, mat.Float(math.NaN()))
+			stats.GradNorm = mat.Float(math.NaN())
+			continue
+		}
+		stats.GradNorm = norm(grad)
+		if mo.ExplodingThreshold != 0 && stats.GradNorm > mo.ExplodingThreshold {
+			mo.alert(ExplodingGradients, name, stats.GradNorm)
+		}
+		if mo.VanishingThreshold != 0 && stats.GradNorm < mo.VanishingThreshold {
+			mo.alert(VanishingGradients, name, stats.GradNorm)
+		}
+	}
+}
+
+// observeUpdates completes the statistics of the reported step, and makes the report.
+func (mo *Monitor) observeUpdates(params []nn.Param, deltas []mat.Matrix) {
+	mo.mu.Lock()
+	if mo.pending == nil {
+		mo.mu.Unlock()
+		return
+	}
+	report := &Report{Step: mo.step}
+	for i, param := range params {
+		stats, ok := mo.pending[param]
+		if !ok {
+			continue
+		}
+		values := param.Value().Data()
+		if !allFinite(values) {
+			mo.alert(NonFiniteValues, stats.Name, mat.Float(math.NaN()))
+		}
+		stats.Mean, stats.Std, stats.MaxAbs = describe(values)
+		if deltas[i] != nil {
+			if n := norm(values); n != 0 {
+				stats.UpdateRatio = norm(deltas[i].Data()) / n
+			}
+		}
+		report.Params = append(report.Params, *stats)
+	}
+	for _, a := range mo.sortedActivations() {
+		dead := 0
+		for _, alive := range a.alive {
+			if !alive {
+				dead++
+			}
+		}
+		stats := ActivationStats{Name: a.name, Units: len(a.alive)}
+		if len(a.alive) > 0 {
+			stats.DeadFraction = mat.Float(dead) / mat.Float(len(a.alive))
+		}
+		if mo.DeadThreshold != 0 && stats.DeadFraction > mo.DeadThreshold {
+			mo.alert(DeadUnits, a.name, stats.DeadFraction)
+		}
+		report.Activations = append(report.Activations, stats)
+		for j := range a.alive {
+			a.alive[j] = false
+		}
+	}
+	report.Alerts = mo.alerts
+	mo.pending = nil
+	mo.alerts = nil
+	mo.lastReport = report
+	mo.mu.Unlock()
+
+	for _, alert := range report.Alerts {
+		if mo.OnAlert != nil {
+			mo.OnAlert(alert)
+		} else {
+			log.Printf("health: %s", alert)
+		}
+	}
+	if mo.OnReport != nil {
+		mo.OnReport(report)
+	}
+}
+
+func (mo *Monitor) alert(kind AlertKind, name string, value mat.Float) {
+	mo.alerts = append(mo.alerts, Alert{Step: mo.step, Kind: kind, Name: name, Value: value})
+}
+
+// sortedActivations returns the observed activations sorted by name.
+func (mo *Monitor) sortedActivations() []*activations {
+	result := make([]*activations, 0, len(mo.activations))
+	for _, a := range mo.activations {
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+func allFinite(xs []mat.Float) bool {
+	for _, x := range xs {
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func norm(xs []mat.Float) mat.Float {
+	sum := mat.Float(0)
+	for _, x := range xs {
+		sum += x * x
+	}
+	return mat.Sqrt(sum)
+}
+
+// describe returns the mean, the standard deviation and the maximum absolute value.
+func describe(xs []mat.Float) (mean, std, maxAbs mat.Float) {
+	if len(xs) == 0 {
+		return
+	}
+	for _, x := range xs {
+		mean += x
+		maxAbs = mat.Max(maxAbs, mat.Abs(x))
+	}
+	mean /= mat.Float(len(xs))
+	for _, x := range xs {
+		std += (x - mean) * (x - mean)
+	}
+	std = mat.Sqrt(std / mat.Float(len(xs)))
+	return
+}
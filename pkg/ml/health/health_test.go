@@ -0,0 +1,111 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package health
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+func newTestModel() *testModel {
+	return &testModel{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{3, -4})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{1})),
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	m := newTestModel()
+	var reports []*Report
+	var alerts []Alert
+	monitor := New(m, Config{
+		Interval:           2,
+		ExplodingThreshold: 10,
+		VanishingThreshold: 1e-3,
+		DeadThreshold:      0.5,
+		OnReport:           func(r *Report) { reports = append(reports, r) },
+		OnAlert:            func(a Alert) { alerts = append(alerts, a) },
+	})
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0, false)), nn.NewDefaultParamsIterator(m), monitor.Options()...)
+
+	step := func(wGrad, bGrad []mat.Float) {
+		m.W.PropagateGrad(mat.NewVecDense(wGrad))
+		m.B.PropagateGrad(mat.NewVecDense(bGrad))
+		optimizer.Optimize()
+	}
+
+	step([]mat.Float{1, 1}, []mat.Float{1})
+	assert.Empty(t, reports)
+
+	monitor.ObserveActivations("relu", mat.NewVecDense([]mat.Float{0, 1, 0, 0}))
+	monitor.ObserveActivations("relu", mat.NewVecDense([]mat.Float{0, 0, 2, 0}))
+	step([]mat.Float{60, 80}, []mat.Float{1e-4})
+	require.Len(t, reports, 1)
+	r := reports[0]
+	assert.Equal(t, 2, r.Step)
+	require.Len(t, r.Params, 2)
+	assert.Equal(t, "w", r.Params[0].Name)
+	assert.InDelta(t, 100, r.Params[0].GradNorm, 1e-4)
+	// w = [3, -4] - 0.1 [1, 1] - 0.1 [60, 80] = [-3.1, -12.1]
+	assert.InDeltaSlice(t, []mat.Float{-3.1, -12.1}, m.W.Value().Data(), 1e-4)
+	assert.InDelta(t, -7.6, r.Params[0].Mean, 1e-4)
+	assert.InDelta(t, 4.5, r.Params[0].Std, 1e-4)
+	assert.InDelta(t, 12.1, r.Params[0].MaxAbs, 1e-4)
+	assert.InDelta(t, 10/mat.Sqrt(3.1*3.1+12.1*12.1), r.Params[0].UpdateRatio, 1e-4)
+	assert.Equal(t, []ActivationStats{{Name: "relu", Units: 4, DeadFraction: 0.5}}, r.Activations)
+	assert.Equal(t, []Alert{
+		{Step: 2, Kind: ExplodingGradients, Name: "w", Value: r.Params[0].GradNorm},
+		{Step: 2, Kind: VanishingGradients, Name: "b", Value: r.Params[1].GradNorm},
+	}, alerts)
+	assert.Equal(t, r, monitor.LastReport())
+
+	// the activations are reset after each report
+	alerts = nil
+	monitor.ObserveActivations("relu", mat.NewVecDense([]mat.Float{0, 0, 0, 1}))
+	step([]mat.Float{1, 1}, []mat.Float{1})
+	step([]mat.Float{mat.Float(math.NaN()), 1}, []mat.Float{1})
+	require.Len(t, reports, 2)
+	assert.Equal(t, mat.Float(0.75), reports[1].Activations[0].DeadFraction)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, NonFiniteGradients, alerts[0].Kind)
+	assert.Equal(t, NonFiniteValues, alerts[1].Kind)
+	assert.Equal(t, DeadUnits, alerts[2].Kind)
+	assert.Equal(t, `step 4: dead units in "relu" (0.75)`, alerts[2].String())
+}
+
+func TestMonitor_ObserveActivations_Batches(t *testing.T) {
+	m := &testModel{W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})), B: nn.NewParam(mat.NewScalar(0))}
+	var reports []*Report
+	monitor := New(m, Config{OnReport: func(r *Report) { reports = append(reports, r) }})
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0, false)), nn.NewDefaultParamsIterator(m), monitor.Options()...)
+
+	// a unit is alive if it is active for any example of any batch
+	monitor.ObserveActivations("relu", mat.NewDense(2, 3, []mat.Float{
+		0, 1, 0,
+		0, 0, 0,
+	}))
+	monitor.ObserveActivations("relu", mat.NewDense(3, 3, []mat.Float{
+		0, 0, 0,
+		0, 0, 0,
+		0, 0, 2,
+	}))
+	m.W.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1}))
+	optimizer.Optimize()
+	require.Len(t, reports, 1)
+	assert.Equal(t, []ActivationStats{{Name: "relu", Units: 3, DeadFraction: mat.Float(1) / 3}}, reports[0].Activations)
+}
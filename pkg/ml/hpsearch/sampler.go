@@ -0,0 +1,257 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpsearch
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/de"
+)
+
+// Sampler is implemented by the search strategies, which propose the
+// candidates to evaluate as points of the unit hypercube (see Space).
+type Sampler interface {
+	// Ask returns the next candidates to evaluate. n is the number of
+	// candidates which can be evaluated in parallel, but a sampler can return
+	// more or less of them. It returns nil when the search is over.
+	Ask(n int) [][]mat.Float
+	// Tell gives the scores of the candidates returned by the last call of
+	// Ask, in the same order. The lower the score, the better. The candidates
+	// which haven't been evaluated have a score of +Inf.
+	Tell(scores []mat.Float)
+}
+
+var (
+	_ Sampler = &RandomSearch{}
+	_ Sampler = &GridSearch{}
+	_ Sampler = &DifferentialEvolution{}
+)
+
+// RandomSearch samples the candidates uniformly at random. It is never over.
+type RandomSearch struct {
+	size   int
+	rndGen *rand.LockedRand
+}
+
+// NewRandomSearch returns a new RandomSearch on the space.
+func NewRandomSearch(space Space, seed uint64) *RandomSearch {
+	return &RandomSearch{
+		size:   len(space),
+		rndGen: rand.NewLockedRand(seed),
+	}
+}
+
+// Ask returns n random candidates.
+func (r *RandomSearch) Ask(n int) [][]mat.Float {
+	if n < 1 {
+		n = 1
+	}
+	points := make([][]mat.Float, n)
+	for i := range points {
+		points[i] = make([]mat.Float, r.size)
+		for j := range points[i] {
+			points[i][j] = mat.Float(r.rndGen.Float())
+		}
+	}
+	return points
+}
+
+// Tell does nothing.
+func (r *RandomSearch) Tell(_ []mat.Float) {}
+
+// GridSearch evaluates all the combinations of evenly spaced values of the
+// hyperparameters (in the log scale, if Log is true), including the bounds.
+type GridSearch struct {
+	// axes are the coordinates of the values of each hyperparameter.
+	axes [][]mat.Float
+	next int
+	size int
+}
+
+// NewGridSearch returns a new GridSearch on the space, with the given number
+// of values for each hyperparameter. An integer hyperparameter can have fewer
+// values, if its range is smaller.
+func NewGridSearch(space Space, points int) *GridSearch {
+	if points < 1 {
+		panic("hpsearch: the number of points must be greater than zero")
+	}
+	g := &GridSearch{axes: make([][]mat.Float, len(space)), size: 1}
+	for i, p := range space {
+		seen := make(map[mat.Float]bool)
+		for j := 0; j < points; j++ {
+			u := mat.Float(0.5)
+			if points > 1 {
+				u = mat.Float(j) / mat.Float(points-1)
+			}
+			if value := p.decode(u); !seen[value] {
+				seen[value] = true
+				g.axes[i] = append(g.axes[i], u)
+			}
+		}
+		g.size *= len(g.axes[i])
+	}
+	return g
+}
+
+// Size returns the number of points of the grid.
+func (g *GridSearch) Size() int {
+	return g.size
+}
+
+// Ask returns the next n points of the grid, or nil if all of them have been evaluated.
+func (g *GridSearch) Ask(n int) [][]mat.Float {
+	if n < 1 {
+		n = 1
+	}
+	var points [][]mat.Float
+	for ; g.next < g.size && len(points) < n; g.next++ {
+		point := make([]mat.Float, len(g.axes))
+		index := g.next
+		for i := len(g.axes) - 1; i >= 0; i-- {
+			point[i] = g.axes[i][index%len(g.axes[i])]
+			index /= len(g.axes[i])
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// Tell does nothing.
+func (g *GridSearch) Tell(_ []mat.Float) {}
+
+// DEConfig provides configuration settings for a DifferentialEvolution sampler.
+type DEConfig struct {
+	// PopulationSize is the number of members of the population, i.e. the
+	// number of candidates of each generation.
+	PopulationSize int
+	// MutationFactor is the differential weight.
+	MutationFactor mat.Float
+	// CrossoverRate is the crossover probability.
+	CrossoverRate mat.Float
+	// WeightFactor is the weight of the global mutation of DEGL.
+	WeightFactor mat.Float
+	// NeighborhoodRadius is the radius of the neighborhood of DEGL, as a
+	// fraction of the population size. The neighborhood must contain at least
+	// two members on each side.
+	NeighborhoodRadius mat.Float
+	// Seed is the random seed.
+	Seed uint64
+}
+
+// NewDefaultDEConfig returns a new DEConfig with a population of 10 members.
+func NewDefaultDEConfig() DEConfig {
+	return DEConfig{
+		PopulationSize:     10,
+		MutationFactor:     0.5,
+		CrossoverRate:      0.9,
+		WeightFactor:       0.5,
+		NeighborhoodRadius: 0.2,
+	}
+}
+
+// DifferentialEvolution samples the candidates with the differential
+// evolution of the de package, using the DEGL mutation and a binomial
+// crossover. Each member of the population is a point of the search space;
+// each generation is a batch of trials. It is never over.
+type DifferentialEvolution struct {
+	population *de.Population
+	mutation   de.Mutator
+	crossover  de.Crossover
+	// initialized is true once the target vectors have been scored.
+	initialized bool
+}
+
+// NewDifferentialEvolution returns a new DifferentialEvolution on the space.
+func NewDifferentialEvolution(space Space, config DEConfig) *DifferentialEvolution {
+	if int(mat.Float(config.PopulationSize)*config.NeighborhoodRadius) < 2 {
+		panic("hpsearch: the DEGL neighborhood must contain at least two members on each side")
+	}
+	rndGen := rand.NewLockedRand(config.Seed)
+	hyperParams := de.MemberHyperParams{
+		MutationFactor: config.MutationFactor,
+		CrossoverRate:  config.CrossoverRate,
+		WeightFactor:   config.WeightFactor,
+	}
+	members := make([]*de.Member, config.PopulationSize)
+	for i := range members {
+		vector := mat.NewEmptyVecDense(len(space))
+		initializers.Uniform(vector, -1, 1, rndGen)
+		members[i] = de.NewMember(vector, hyperParams)
+	}
+	return &DifferentialEvolution{
+		population: &de.Population{Members: members},
+		mutation:   de.NewDeglMutation(config.NeighborhoodRadius, 1),
+		crossover:  &binomialCrossover{rndGen: rndGen},
+	}
+}
+
+// Ask returns the target vectors of the population, the first time, and the
+// trial vectors of a new generation afterwards.
+func (d *DifferentialEvolution) Ask(_ int) [][]mat.Float {
+	if d.initialized {
+		d.mutation.Mutate(d.population)
+		d.crossover.Crossover(d.population)
+	}
+	points := make([][]mat.Float, len(d.population.Members))
+	for i, member := range d.population.Members {
+		vector := member.TargetVector
+		if d.initialized {
+			vector = member.DonorVector
+		}
+		points[i] = toUnit(vector)
+	}
+	return points
+}
+
+// Tell scores the target vectors, the first time, and then replaces each of
+// them with its trial vector if the latter scores better.
+func (d *DifferentialEvolution) Tell(scores []mat.Float) {
+	for i, member := range d.population.Members {
+		if !d.initialized {
+			member.TargetScore = scores[i]
+			continue
+		}
+		member.TrialScore = scores[i]
+		if member.TrialScore < member.TargetScore {
+			member.TargetScore = member.TrialScore
+			member.TargetVector = member.DonorVector.Clone()
+		}
+	}
+	d.initialized = true
+}
+
+// toUnit maps a vector of the population, in [-1, 1], to the unit hypercube.
+func toUnit(vector mat.Matrix) []mat.Float {
+	point := make([]mat.Float, vector.Size())
+	for i, v := range vector.Data() {
+		point[i] = (v + 1) / 2
+	}
+	return point
+}
+
+var _ de.Crossover = &binomialCrossover{}
+
+// binomialCrossover takes each coordinate of the trial vector from the donor
+// vector with probability CrossoverRate, and at least one of them, otherwise
+// from the target vector. Unlike de.BinomialCrossover, which always keeps a
+// coordinate of the target vector, it lets the members move along all the
+// dimensions of a small search space.
+type binomialCrossover struct {
+	rndGen *rand.LockedRand
+}
+
+// Crossover performs the crossover on each member of the population.
+func (c *binomialCrossover) Crossover(p *de.Population) {
+	for _, member := range p.Members {
+		size := member.DonorVector.Size()
+		k := c.rndGen.Intn(size)
+		for i := 0; i < size; i++ {
+			if i != k && mat.Float(c.rndGen.Float()) >= member.CrossoverRate {
+				member.DonorVector.SetVec(i, member.TargetVector.AtVec(i))
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpsearch
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomSearch(t *testing.T) {
+	sampler := NewRandomSearch(newTestSpace(), 42)
+	points := sampler.Ask(5)
+	require.Len(t, points, 5)
+	for _, point := range points {
+		require.Len(t, point, 4)
+		for _, u := range point {
+			assert.True(t, u >= 0 && u < 1)
+		}
+	}
+	assert.NotEqual(t, points[0], points[1])
+	assert.Equal(t, points, NewRandomSearch(newTestSpace(), 42).Ask(5))
+}
+
+func TestGridSearch(t *testing.T) {
+	space := Space{
+		{Name: "dropout", Type: Float, Min: 0, Max: 0.5},
+		{Name: "layers", Type: Int, Min: 1, Max: 2},
+	}
+	sampler := NewGridSearch(space, 3)
+	// the integer hyperparameter has only two values
+	assert.Equal(t, 6, sampler.Size())
+
+	var values []Values
+	for points := sampler.Ask(4); points != nil; points = sampler.Ask(4) {
+		assert.LessOrEqual(t, len(points), 4)
+		for _, point := range points {
+			values = append(values, space.Decode(point))
+		}
+	}
+	assert.Equal(t, []Values{
+		{"dropout": 0, "layers": 1},
+		{"dropout": 0, "layers": 2},
+		{"dropout": 0.25, "layers": 1},
+		{"dropout": 0.25, "layers": 2},
+		{"dropout": 0.5, "layers": 1},
+		{"dropout": 0.5, "layers": 2},
+	}, values)
+}
+
+func TestGridSearch_SinglePoint(t *testing.T) {
+	space := Space{{Name: "dropout", Type: Float, Min: 0, Max: 0.5}}
+	sampler := NewGridSearch(space, 1)
+	assert.Equal(t, [][]mat.Float{{0.5}}, sampler.Ask(2))
+	assert.Nil(t, sampler.Ask(2))
+	assert.Panics(t, func() { NewGridSearch(space, 0) })
+}
+
+func TestDifferentialEvolution(t *testing.T) {
+	space := Space{
+		{Name: "x", Type: Float, Min: -5, Max: 5},
+		{Name: "y", Type: Float, Min: -5, Max: 5},
+	}
+	config := NewDefaultDEConfig()
+	config.Seed = 1
+	sampler := NewDifferentialEvolution(space, config)
+	f := func(v Values) mat.Float {
+		x, y := v.Float("x")-1, v.Float("y")+2
+		return x*x + y*y
+	}
+
+	best := mat.Inf(1)
+	for generation := 0; generation < 40; generation++ {
+		points := sampler.Ask(1)
+		require.Len(t, points, config.PopulationSize)
+		scores := make([]mat.Float, len(points))
+		for i, point := range points {
+			for _, u := range point {
+				require.True(t, u >= 0 && u <= 1)
+			}
+			scores[i] = f(space.Decode(point))
+			if scores[i] < best {
+				best = scores[i]
+			}
+		}
+		sampler.Tell(scores)
+	}
+	assert.Less(t, float64(best), 1e-2)
+
+	// the targets retain the best scores
+	for _, member := range sampler.population.Members {
+		assert.GreaterOrEqual(t, float64(member.TargetScore), float64(best))
+	}
+}
+
+func TestNewDifferentialEvolution_SmallPopulation(t *testing.T) {
+	config := NewDefaultDEConfig()
+	config.PopulationSize = 5
+	assert.Panics(t, func() { NewDifferentialEvolution(newTestSpace(), config) })
+}
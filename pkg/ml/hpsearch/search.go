@@ -0,0 +1,322 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hpsearch implements the search of the hyperparameters of a model,
+// such as the learning rate, the dropout or the size of the hidden layers.
+//
+// The hyperparameters are named and bounded, with integer or real values, on
+// a linear or a log scale (see Space). A Sampler proposes the candidate
+// values: the differential evolution of the de package, or the random and
+// the grid search as baselines. Each candidate is evaluated by a trial, i.e.
+// a training run with a budget, and several trials can run in parallel.
+// The trials report their intermediate scores, so that the unpromising ones
+// can be terminated early (see MedianStopping).
+package hpsearch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/trainer"
+)
+
+// TrainFunc trains a model with the hyperparameters of the trial, within
+// its budget, and returns its final score. It should report the intermediate
+// scores with Trial.Report, and stop as soon as the trial is terminated.
+// An error aborts the search.
+type TrainFunc func(t *Trial) (mat.Float, error)
+
+// Config provides configuration settings for a search.
+type Config struct {
+	// MaxTrials is the maximum number of trials. If 0, the search goes on
+	// until the sampler is over or the timeout expires: since the random and
+	// the differential evolution samplers are never over, at least one of
+	// MaxTrials and Timeout must be set for them.
+	MaxTrials int
+	// Timeout, if not 0, is the maximum duration of the search. When it
+	// expires, the running trials are terminated and no new trial is started.
+	Timeout time.Duration
+	// TrialBudget is the budget of each trial (see Trial.Budget).
+	TrialBudget int
+	// Parallelism is the number of trials which run in parallel. If 0, the
+	// trials run one at a time.
+	Parallelism int
+	// Maximize is true if the higher the score, the better (e.g. the
+	// accuracy), false if the lower, the better (e.g. the loss).
+	Maximize bool
+	// EarlyTermination, if not nil, terminates the unpromising trials.
+	EarlyTermination *MedianStopping
+	// OnTrialEnd, if not nil, is called at the end of each trial. The calls
+	// are serialized.
+	OnTrialEnd func(r TrialResult)
+}
+
+// MedianStopping terminates a trial whose best score at a step is worse
+// than the median of the best scores of the other trials at the same step.
+//
+// Reference: "Google Vizier: A Service for Black-Box Optimization" (Golovin et al., 2017)
+type MedianStopping struct {
+	// WarmupSteps is the number of steps before which a trial is never terminated.
+	WarmupSteps int
+	// MinTrials is the minimum number of other trials which must have
+	// reported a score at the same step. If 0, 1 is used.
+	MinTrials int
+}
+
+// TrialResult is the outcome of a trial.
+type TrialResult struct {
+	// ID is the number of the trial, starting from 0.
+	ID     int
+	Values Values
+	// Score is the final score returned by the trial.
+	Score mat.Float
+	// Stopped is true if the trial was terminated before the end of its
+	// budget, by the early termination or by the timeout.
+	Stopped  bool
+	Duration time.Duration
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	// Trials are the results of the trials, sorted by ID.
+	Trials []TrialResult
+	// Best is the result of the best trial, or nil if no trial was run.
+	Best *TrialResult
+}
+
+// Trial is a training run with candidate values of the hyperparameters.
+type Trial struct {
+	// ID is the number of the trial, starting from 0.
+	ID int
+	// Values are the values of the hyperparameters.
+	Values Values
+	// Budget is the budget of the trial (see Config.TrialBudget), e.g. the
+	// maximum number of epochs.
+	Budget int
+	search *search
+	// best is the best loss reported so far.
+	best    mat.Float
+	stopped bool
+}
+
+// Report reports the score of the trial at the given step, e.g. the
+// validation score at the end of an epoch. It returns false if the trial is
+// terminated, in which case the training should stop.
+// It is safe to call it concurrently.
+func (t *Trial) Report(step int, score mat.Float) bool {
+	return t.search.report(t, step, score)
+}
+
+// Stopped reports whether the trial has been terminated.
+func (t *Trial) Stopped() bool {
+	t.search.mu.Lock()
+	defer t.search.mu.Unlock()
+	return t.stopped
+}
+
+// OnEvaluation implements trainer.EvaluationCallback: it reports the
+// validation score of each evaluation of a trainer.Trainer, with the number
+// of evaluations as step, and stops the training when the trial is terminated.
+func (t *Trial) OnEvaluation(s *trainer.State) {
+	if !t.Report(s.Evaluations, s.Score) {
+		s.Stop()
+	}
+}
+
+// Run searches the hyperparameters of the space, running a trial for each
+// candidate of the sampler.
+func Run(space Space, sampler Sampler, train TrainFunc, config Config) (*Result, error) {
+	if err := space.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Parallelism < 1 {
+		config.Parallelism = 1
+	}
+	s := &search{
+		Config: config,
+		space:  space,
+		train:  train,
+		steps:  make(map[int]map[int]mat.Float),
+	}
+	if config.Timeout > 0 {
+		s.deadline = time.Now().Add(config.Timeout)
+	}
+
+	for !s.expired() {
+		n := config.Parallelism
+		if config.MaxTrials > 0 {
+			remaining := config.MaxTrials - len(s.results)
+			if remaining <= 0 {
+				break
+			}
+			if n > remaining {
+				n = remaining
+			}
+		}
+		candidates := sampler.Ask(n)
+		if len(candidates) == 0 {
+			break
+		}
+		asked := len(candidates)
+		if config.MaxTrials > 0 && asked > config.MaxTrials-len(s.results) {
+			candidates = candidates[:config.MaxTrials-len(s.results)]
+		}
+		losses, err := s.runTrials(candidates)
+		if err != nil {
+			return nil, err
+		}
+		for len(losses) < asked {
+			losses = append(losses, mat.Inf(1)) // not run, beyond MaxTrials
+		}
+		sampler.Tell(losses)
+	}
+
+	r := &Result{Trials: s.results}
+	sort.Slice(r.Trials, func(i, j int) bool { return r.Trials[i].ID < r.Trials[j].ID })
+	bestLoss := mat.Inf(1)
+	for i, trial := range r.Trials {
+		if loss := s.loss(trial.Score); r.Best == nil || loss < bestLoss {
+			bestLoss = loss
+			r.Best = &r.Trials[i]
+		}
+	}
+	return r, nil
+}
+
+// search is the state of a search.
+type search struct {
+	Config
+	space    Space
+	train    TrainFunc
+	deadline time.Time
+	mu       sync.Mutex
+	nextID   int
+	results  []TrialResult
+	// steps are the best losses of the trials at each step, by trial ID.
+	steps map[int]map[int]mat.Float
+}
+
+// runTrials runs the trials of the candidates, returning their losses.
+// The candidates whose trials are not started because of the timeout get +Inf.
+func (s *search) runTrials(candidates [][]mat.Float) ([]mat.Float, error) {
+	losses := make([]mat.Float, len(candidates))
+	for i := range losses {
+		losses[i] = mat.Inf(1)
+	}
+	var wg sync.WaitGroup
+	var firstErr error
+	failed := func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return firstErr != nil
+	}
+	sem := make(chan struct{}, s.Parallelism)
+	for i, candidate := range candidates {
+		sem <- struct{}{}
+		if s.expired() || failed() {
+			<-sem
+			break
+		}
+		trial := &Trial{
+			ID:     s.nextID,
+			Values: s.space.Decode(candidate),
+			Budget: s.TrialBudget,
+			search: s,
+			best:   mat.Inf(1),
+		}
+		s.nextID++
+		wg.Add(1)
+		go func(i int, trial *Trial) {
+			defer func() { <-sem; wg.Done() }()
+			start := time.Now()
+			score, err := s.train(trial)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("hpsearch: trial %d: %w", trial.ID, err)
+				}
+				return
+			}
+			losses[i] = s.loss(score)
+			result := TrialResult{
+				ID:       trial.ID,
+				Values:   trial.Values,
+				Score:    score,
+				Stopped:  trial.stopped,
+				Duration: time.Since(start),
+			}
+			s.results = append(s.results, result)
+			if s.OnTrialEnd != nil {
+				s.OnTrialEnd(result)
+			}
+		}(i, trial)
+	}
+	wg.Wait()
+	return losses, firstErr
+}
+
+// report records the score of the trial at the step, and decides whether it must be terminated.
+func (s *search) report(t *Trial, step int, score mat.Float) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if loss := s.loss(score); loss < t.best {
+		t.best = loss
+	}
+	if s.steps[step] == nil {
+		s.steps[step] = make(map[int]mat.Float)
+	}
+	s.steps[step][t.ID] = t.best
+	if !t.stopped && (s.expired() || s.worseThanMedian(t, step)) {
+		t.stopped = true
+	}
+	return !t.stopped
+}
+
+// worseThanMedian reports whether the trial must be terminated by the median stopping rule.
+func (s *search) worseThanMedian(t *Trial, step int) bool {
+	es := s.EarlyTermination
+	if es == nil || step < es.WarmupSteps {
+		return false
+	}
+	others := make([]float64, 0, len(s.steps[step]))
+	for id, loss := range s.steps[step] {
+		if id != t.ID {
+			others = append(others, float64(loss))
+		}
+	}
+	minTrials := es.MinTrials
+	if minTrials < 1 {
+		minTrials = 1
+	}
+	if len(others) < minTrials {
+		return false
+	}
+	sort.Float64s(others)
+	median := others[len(others)/2]
+	if len(others)%2 == 0 {
+		median = (others[len(others)/2-1] + median) / 2
+	}
+	return float64(t.best) > median
+}
+
+// loss converts a score to a value to minimize. A NaN score is the worst.
+func (s *search) loss(score mat.Float) mat.Float {
+	if math.IsNaN(float64(score)) {
+		return mat.Inf(1)
+	}
+	if s.Maximize {
+		return -score
+	}
+	return score
+}
+
+// expired reports whether the timeout has expired.
+func (s *search) expired() bool {
+	return !s.deadline.IsZero() && time.Now().After(s.deadline)
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpsearch
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/trainer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testObjective simulates a training, whose loss decreases with the epochs
+// towards a minimum which depends on the learning rate and the number of
+// layers. The best values are lr = 1e-2 and layers = 2.
+func testObjective(trial *Trial) (mat.Float, error) {
+	x := mat.Log(trial.Values.Float("lr")) / mat.Log(10)
+	final := (x+2)*(x+2) + mat.Abs(mat.Float(trial.Values.Int("layers")-2))
+	loss := mat.Float(0)
+	for epoch := 1; epoch <= trial.Budget; epoch++ {
+		loss = final + 1/mat.Float(epoch)
+		if !trial.Report(epoch, loss) {
+			break
+		}
+	}
+	return loss, nil
+}
+
+func TestRun_DifferentialEvolution(t *testing.T) {
+	space := newTestSpace()
+	config := NewDefaultDEConfig()
+	config.Seed = 2
+	var mu sync.Mutex
+	ended := 0
+	r, err := Run(space, NewDifferentialEvolution(space, config), testObjective, Config{
+		MaxTrials:   200,
+		TrialBudget: 5,
+		Parallelism: 4,
+		OnTrialEnd: func(r TrialResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			ended++
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, r.Trials, 200)
+	assert.Equal(t, 200, ended)
+	for i, trial := range r.Trials {
+		assert.Equal(t, i, trial.ID)
+		assert.False(t, trial.Stopped)
+	}
+	require.NotNil(t, r.Best)
+	assert.InDelta(t, 1e-2, r.Best.Values.Float("lr"), 5e-3)
+	assert.Equal(t, 2, r.Best.Values.Int("layers"))
+	for _, trial := range r.Trials {
+		assert.GreaterOrEqual(t, float64(trial.Score), float64(r.Best.Score))
+	}
+}
+
+func TestRun_DifferentialEvolution_PartialGeneration(t *testing.T) {
+	space := newTestSpace()
+	config := NewDefaultDEConfig()
+	// the last generation is cut short by MaxTrials
+	r, err := Run(space, NewDifferentialEvolution(space, config), testObjective, Config{
+		MaxTrials:   config.PopulationSize*2 + 3,
+		TrialBudget: 5,
+		Parallelism: 4,
+	})
+	require.NoError(t, err)
+	assert.Len(t, r.Trials, config.PopulationSize*2+3)
+}
+
+func TestRun_GridSearch(t *testing.T) {
+	space := Space{
+		{Name: "lr", Type: Float, Min: 1e-4, Max: 1, Log: true},
+		{Name: "layers", Type: Int, Min: 1, Max: 3},
+	}
+	r, err := Run(space, NewGridSearch(space, 5), testObjective, Config{
+		TrialBudget: 1,
+		Parallelism: 3,
+	})
+	require.NoError(t, err)
+	assert.Len(t, r.Trials, 15)
+	require.NotNil(t, r.Best)
+	assert.InDelta(t, 1e-2, r.Best.Values.Float("lr"), 1e-5)
+	assert.Equal(t, 2, r.Best.Values.Int("layers"))
+	assert.InDelta(t, 1, r.Best.Score, 1e-5)
+}
+
+func TestRun_RandomSearch_Maximize(t *testing.T) {
+	space := Space{{Name: "x", Type: Float, Min: 0, Max: 1}}
+	r, err := Run(space, NewRandomSearch(space, 1), func(trial *Trial) (mat.Float, error) {
+		return trial.Values.Float("x"), nil
+	}, Config{MaxTrials: 50, Parallelism: 8, Maximize: true})
+	require.NoError(t, err)
+	assert.Len(t, r.Trials, 50)
+	for _, trial := range r.Trials {
+		assert.LessOrEqual(t, float64(trial.Score), float64(r.Best.Score))
+	}
+	assert.Greater(t, float64(r.Best.Score), 0.9)
+}
+
+func TestRun_MedianStopping(t *testing.T) {
+	space := Space{{Name: "x", Type: Int, Min: 0, Max: 9}}
+	var mu sync.Mutex
+	epochs := make(map[int]int)
+	r, err := Run(space, NewGridSearch(space, 10), func(trial *Trial) (mat.Float, error) {
+		x := mat.Float(trial.Values.Int("x"))
+		for epoch := 1; epoch <= trial.Budget; epoch++ {
+			mu.Lock()
+			epochs[trial.Values.Int("x")] = epoch
+			mu.Unlock()
+			if !trial.Report(epoch, x) {
+				assert.True(t, trial.Stopped())
+				break
+			}
+		}
+		return x, nil
+	}, Config{
+		TrialBudget:      10,
+		EarlyTermination: &MedianStopping{WarmupSteps: 2, MinTrials: 3},
+	})
+	require.NoError(t, err)
+	require.Len(t, r.Trials, 10)
+
+	// the first trials are never stopped, since there aren't enough others
+	for x := 0; x <= 2; x++ {
+		assert.False(t, r.Trials[x].Stopped)
+		assert.Equal(t, 10, epochs[x])
+	}
+	// the others are stopped after the warmup, since they are worse than the median
+	for x := 3; x <= 9; x++ {
+		assert.True(t, r.Trials[x].Stopped)
+		assert.Equal(t, 2, epochs[x])
+	}
+	assert.Equal(t, mat.Float(0), r.Best.Score)
+}
+
+func TestRun_Timeout(t *testing.T) {
+	space := Space{{Name: "x", Type: Float, Min: 0, Max: 1}}
+	start := time.Now()
+	r, err := Run(space, NewRandomSearch(space, 1), func(trial *Trial) (mat.Float, error) {
+		for step := 1; ; step++ {
+			time.Sleep(time.Millisecond)
+			if !trial.Report(step, trial.Values.Float("x")) {
+				return trial.Values.Float("x"), nil
+			}
+		}
+	}, Config{Timeout: 50 * time.Millisecond, Parallelism: 2})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	require.Len(t, r.Trials, 2)
+	for _, trial := range r.Trials {
+		assert.True(t, trial.Stopped)
+	}
+}
+
+func TestRun_Error(t *testing.T) {
+	space := Space{{Name: "x", Type: Float, Min: 0, Max: 1}}
+	failure := errors.New("failure")
+	_, err := Run(space, NewRandomSearch(space, 1), func(trial *Trial) (mat.Float, error) {
+		if trial.ID == 3 {
+			return 0, failure
+		}
+		return 0, nil
+	}, Config{MaxTrials: 10})
+	assert.True(t, errors.Is(err, failure))
+
+	_, err = Run(Space{}, NewRandomSearch(Space{}, 1), testObjective, Config{MaxTrials: 1})
+	assert.Error(t, err)
+}
+
+func TestTrial_OnEvaluation(t *testing.T) {
+	space := Space{{Name: "x", Type: Int, Min: 0, Max: 1}}
+	var _ trainer.EvaluationCallback = &Trial{}
+	r, err := Run(space, NewGridSearch(space, 2), func(trial *Trial) (mat.Float, error) {
+		state := &trainer.State{}
+		for state.Evaluations = 1; state.Evaluations <= trial.Budget && !state.Stopped; state.Evaluations++ {
+			state.Score = mat.Float(trial.Values.Int("x"))
+			trial.OnEvaluation(state)
+		}
+		return state.Score, nil
+	}, Config{
+		TrialBudget:      5,
+		Maximize:         true,
+		EarlyTermination: &MedianStopping{},
+	})
+	require.NoError(t, err)
+	require.Len(t, r.Trials, 2)
+	assert.False(t, r.Trials[0].Stopped) // the first trial has no others to compare with
+	assert.False(t, r.Trials[1].Stopped) // the second trial is better
+	assert.Equal(t, 1, r.Best.Values.Int("x"))
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpsearch
+
+import (
+	"fmt"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+// Type is the type of the values of a hyperparameter.
+type Type int
+
+const (
+	// Float is the type of real values (e.g. the learning rate or the dropout).
+	Float Type = iota
+	// Int is the type of integer values (e.g. the size of a hidden layer).
+	Int
+)
+
+// Param is a bounded hyperparameter.
+type Param struct {
+	// Name is the name of the hyperparameter.
+	Name string
+	// Type is the type of its values.
+	Type Type
+	// Min is the minimum value.
+	Min mat.Float
+	// Max is the maximum value (inclusive).
+	Max mat.Float
+	// Log samples the values uniformly in the log scale, e.g. for the learning
+	// rate. Min must be positive.
+	Log bool
+}
+
+// Space is the search space, which is made of a set of hyperparameters.
+//
+// The samplers explore the unit hypercube, which has a dimension for each
+// hyperparameter: a point of it is mapped to the values of the
+// hyperparameters, in their ranges. The integer values in the range of a
+// Param take up intervals of the same width (in the log scale, if Log is true).
+type Space []Param
+
+// Values are the values of the hyperparameters of a trial, by name.
+type Values map[string]mat.Float
+
+// Float returns the value of the hyperparameter with the given name.
+// It panics if the hyperparameter doesn't exist.
+func (v Values) Float(name string) mat.Float {
+	value, ok := v[name]
+	if !ok {
+		panic(fmt.Sprintf("hpsearch: unknown hyperparameter %q", name))
+	}
+	return value
+}
+
+// Int returns the value of the integer hyperparameter with the given name.
+// It panics if the hyperparameter doesn't exist.
+func (v Values) Int(name string) int {
+	return int(v.Float(name))
+}
+
+// Validate returns an error if the space is empty, or any of its
+// hyperparameters is not well defined.
+func (s Space) Validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("hpsearch: empty search space")
+	}
+	names := make(map[string]bool, len(s))
+	for _, p := range s {
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("hpsearch: missing hyperparameter name")
+		case names[p.Name]:
+			return fmt.Errorf("hpsearch: duplicate hyperparameter %q", p.Name)
+		case p.Type != Float && p.Type != Int:
+			return fmt.Errorf("hpsearch: hyperparameter %q has an invalid type", p.Name)
+		case p.Min > p.Max:
+			return fmt.Errorf("hpsearch: hyperparameter %q has min greater than max", p.Name)
+		case p.Log && p.Min <= 0:
+			return fmt.Errorf("hpsearch: hyperparameter %q has a log scale, but min is not positive", p.Name)
+		case p.Type == Int && (mat.Floor(p.Min) != p.Min || mat.Floor(p.Max) != p.Max):
+			return fmt.Errorf("hpsearch: integer hyperparameter %q has non-integer bounds", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// Decode maps a point of the unit hypercube to the values of the
+// hyperparameters. The coordinates out of [0, 1] are clipped.
+func (s Space) Decode(point []mat.Float) Values {
+	if len(point) != len(s) {
+		panic("hpsearch: the point doesn't match the dimension of the space")
+	}
+	values := make(Values, len(s))
+	for i, p := range s {
+		values[p.Name] = p.decode(point[i])
+	}
+	return values
+}
+
+// decode maps the coordinate u in [0, 1] to a value in the range of the hyperparameter.
+func (p Param) decode(u mat.Float) mat.Float {
+	if u < 0 {
+		u = 0
+	} else if u > 1 {
+		u = 1
+	}
+	lo, hi := p.Min, p.Max
+	if p.Type == Int {
+		hi++ // so that Max takes up an interval as wide as the other values
+	}
+	var x mat.Float
+	if p.Log {
+		x = mat.Exp(mat.Log(lo) + u*(mat.Log(hi)-mat.Log(lo)))
+	} else {
+		x = lo + u*(hi-lo)
+	}
+	if p.Type == Int {
+		x = mat.Floor(x)
+	}
+	// clip the rounding errors
+	if x < p.Min {
+		return p.Min
+	}
+	if x > p.Max {
+		return p.Max
+	}
+	return x
+}
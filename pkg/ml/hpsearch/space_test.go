@@ -0,0 +1,72 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpsearch
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSpace() Space {
+	return Space{
+		{Name: "lr", Type: Float, Min: 1e-4, Max: 1, Log: true},
+		{Name: "dropout", Type: Float, Min: 0, Max: 0.5},
+		{Name: "hidden", Type: Int, Min: 16, Max: 128, Log: true},
+		{Name: "layers", Type: Int, Min: 1, Max: 3},
+	}
+}
+
+func TestSpace_Validate(t *testing.T) {
+	assert.NoError(t, newTestSpace().Validate())
+	assert.Error(t, Space{}.Validate())
+	assert.Error(t, Space{{Min: 0, Max: 1}}.Validate())
+	assert.Error(t, Space{{Name: "a", Min: 0, Max: 1}, {Name: "a", Min: 0, Max: 1}}.Validate())
+	assert.Error(t, Space{{Name: "a", Min: 1, Max: 0}}.Validate())
+	assert.Error(t, Space{{Name: "a", Min: 0, Max: 1, Log: true}}.Validate())
+	assert.Error(t, Space{{Name: "a", Type: Int, Min: 0, Max: 1.5}}.Validate())
+	assert.Error(t, Space{{Name: "a", Type: Type(5), Min: 0, Max: 1}}.Validate())
+}
+
+func TestSpace_Decode(t *testing.T) {
+	space := newTestSpace()
+
+	values := space.Decode([]mat.Float{0, 0, 0, 0})
+	assert.InDelta(t, 1e-4, values.Float("lr"), 1e-7)
+	assert.Equal(t, mat.Float(0), values.Float("dropout"))
+	assert.Equal(t, 16, values.Int("hidden"))
+	assert.Equal(t, 1, values.Int("layers"))
+
+	values = space.Decode([]mat.Float{1, 1, 1, 1})
+	assert.InDelta(t, 1, values.Float("lr"), 1e-5)
+	assert.Equal(t, mat.Float(0.5), values.Float("dropout"))
+	assert.Equal(t, 128, values.Int("hidden"))
+	assert.Equal(t, 3, values.Int("layers"))
+
+	values = space.Decode([]mat.Float{0.5, 0.5, 0.5, 0.5})
+	assert.InDelta(t, 1e-2, values.Float("lr"), 1e-5)
+	assert.Equal(t, mat.Float(0.25), values.Float("dropout"))
+	assert.Equal(t, 2, values.Int("layers"))
+
+	// out of range coordinates are clipped
+	values = space.Decode([]mat.Float{-1, 2, -1, 2})
+	assert.InDelta(t, 1e-4, values.Float("lr"), 1e-7)
+	assert.Equal(t, mat.Float(0.5), values.Float("dropout"))
+	assert.Equal(t, 16, values.Int("hidden"))
+	assert.Equal(t, 3, values.Int("layers"))
+
+	assert.Panics(t, func() { space.Decode([]mat.Float{0}) })
+	assert.Panics(t, func() { values.Float("foo") })
+}
+
+func TestSpace_Decode_IntUniform(t *testing.T) {
+	p := Param{Name: "layers", Type: Int, Min: 1, Max: 3}
+	counts := make(map[mat.Float]int)
+	for i := 0; i < 300; i++ {
+		counts[p.decode((mat.Float(i)+0.5)/300)]++
+	}
+	assert.Equal(t, map[mat.Float]int{1: 100, 2: 100, 3: 100}, counts)
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lora implements the low-rank adaptation (LoRA) of the linear layers
+// of a model, such as a BERT or BART transformer, for a parameter-efficient
+// fine-tuning (see "LoRA: Low-Rank Adaptation of Large Language Models",
+// Hu et al., 2021).
+//
+// Inject adds a trainable adapter (see linear.LoRA) to the selected linear
+// layers and freezes all the other parameters of the model, so that only the
+// adapters are trained. Since they are small, the adapters can be saved and
+// loaded separately from the base model (see Save and Load), e.g. one file
+// per task. For deployment, Merge adds the adapters to the base weights,
+// removing the overhead of the low-rank multiplications.
+package lora
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+)
+
+// Config provides configuration settings for the injection of the adapters.
+type Config struct {
+	// Targets are the patterns (see path.Match) of the paths of the linear
+	// layers to adapt (see nn.ForEachModel), e.g. "encoder.layers.*.multiheadattention.attention.*.query"
+	// for the queries of a BERT model. Note that "*" also matches the dots.
+	Targets []string
+	// Rank is the rank of the adapters (e.g. 8).
+	Rank int
+	// Alpha scales the output of the adapters by Alpha / Rank.
+	// If zero, Rank is used, i.e. the output is not scaled.
+	Alpha mat.Float
+	// Trainable are the patterns (see path.Match) of the names of other
+	// parameters to keep trainable (see nn.ForEachNamedParam), e.g. "classifier.*"
+	// for the head of a task. They are saved and loaded along with the adapters.
+	Trainable []string
+	// Seed is the seed of the random generator used for initializing the adapters.
+	Seed uint64
+}
+
+// Inject adds a new adapter to each linear layer of m whose path matches one
+// of the config targets, and freezes all the parameters of m except the
+// adapters and the ones matching the config trainable patterns.
+// It returns the paths of the adapted layers.
+//
+// The word embeddings kept in a storage are not parameters of the model:
+// use a read-only storage to freeze them.
+func Inject(m nn.Model, config Config) ([]string, error) {
+	if config.Rank < 1 {
+		return nil, errors.New("lora: the rank must be greater than zero")
+	}
+	if len(config.Targets) == 0 {
+		return nil, errors.New("lora: no target layers")
+	}
+	for _, patterns := range [][]string{config.Targets, config.Trainable} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("lora: invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	alpha := config.Alpha
+	if alpha == 0 {
+		alpha = mat.Float(config.Rank)
+	}
+	scale := alpha / mat.Float(config.Rank)
+
+	var paths []string
+	var layers []*linear.Model
+	visited := make(map[*linear.Model]bool)
+	var err error
+	nn.ForEachModel(m, func(p string, model nn.Model) {
+		lm, ok := model.(*linear.Model)
+		if !ok || err != nil || visited[lm] || !matchAny(config.Targets, p) {
+			return
+		}
+		visited[lm] = true // e.g. shared or embedded
+		if lm.LoRA != nil {
+			err = fmt.Errorf("lora: the layer %q already has an adapter", p)
+			return
+		}
+		paths = append(paths, p)
+		layers = append(layers, lm)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return nil, errors.New("lora: no linear layer matches the targets")
+	}
+
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		param.SetRequiresGrad(matchAny(config.Trainable, name))
+	})
+
+	generator := rand.NewLockedRand(config.Seed)
+	for _, lm := range layers {
+		out, in := layerDims(lm)
+		adapter := linear.NewLoRA(in, out, config.Rank, scale)
+		bound := 1 / mat.Sqrt(mat.Float(in))
+		initializers.Uniform(adapter.A.Value(), -bound, bound, generator)
+		lm.LoRA = adapter
+	}
+	return paths, nil
+}
+
+// Adapters returns the adapters of the linear layers of m, by path.
+func Adapters(m nn.Model) map[string]*linear.LoRA {
+	adapters := make(map[string]*linear.LoRA)
+	visited := make(map[*linear.LoRA]bool)
+	nn.ForEachModel(m, func(p string, model nn.Model) {
+		if lm, ok := model.(*linear.Model); ok && lm.LoRA != nil && !visited[lm.LoRA] {
+			visited[lm.LoRA] = true
+			adapters[p] = lm.LoRA
+		}
+	})
+	return adapters
+}
+
+// Merge adds the adapters of m to the weights of their linear layers and
+// removes them (see linear.Model.MergeLoRA), returning the paths of the
+// merged layers. The parameters are left frozen.
+// It returns an error, without modifying the model, if an adapted layer
+// has been quantized.
+func Merge(m nn.Model) ([]string, error) {
+	var paths []string
+	var layers []*linear.Model
+	nn.ForEachModel(m, func(p string, model nn.Model) {
+		if lm, ok := model.(*linear.Model); ok && lm.LoRA != nil {
+			paths = append(paths, p)
+			layers = append(layers, lm)
+		}
+	})
+	for i, lm := range layers {
+		if lm.Int8 != nil {
+			return nil, fmt.Errorf("lora: cannot merge the adapter of the quantized layer %q", paths[i])
+		}
+	}
+	for _, lm := range layers {
+		lm.MergeLoRA()
+	}
+	return paths, nil
+}
+
+// Save writes the adapters of m and the other trainable parameters to a new
+// file (see Write).
+func Save(m nn.Model, filename string) (err error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+	w := bufio.NewWriter(f)
+	if err := Write(m, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Write writes the adapters of m, along with the other parameters left
+// trainable by Inject, in the state dict format (see nn.WriteStateDict).
+// The frozen parameters of the base model are not written.
+func Write(m nn.Model, w io.Writer) error {
+	return nn.WriteStateDictFiltered(m, w, func(_ string, param nn.Param) bool {
+		return param.RequiresGrad()
+	})
+}
+
+// Load reads a file written by Save (see Read).
+func Load(m nn.Model, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Read(m, bufio.NewReader(f))
+}
+
+// Read reads the parameters written by Write into m, which must already
+// have the same adapters (see Inject) and trainable parameters.
+// It returns an error, without modifying the model, if a parameter is not
+// found in m, has a different shape, or is missing from the input.
+func Read(m nn.Model, r io.Reader) error {
+	entries, err := nn.ReadStateDict(r)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]nn.StateDictEntry, len(entries))
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	params := make(map[string]nn.Param)
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		params[name] = param
+		if _, ok := byName[name]; !ok && param.RequiresGrad() && err == nil {
+			err = fmt.Errorf("lora: missing param %q", name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		param, ok := params[e.Name]
+		if !ok {
+			return fmt.Errorf("lora: unexpected param %q", e.Name)
+		}
+		if rows, cols := param.Dims(); rows != 0 || cols != 0 {
+			if rows != e.Rows || cols != e.Columns {
+				return fmt.Errorf("lora: param %q has shape %dx%d, found %dx%d", e.Name, rows, cols, e.Rows, e.Columns)
+			}
+		}
+	}
+	_, err = nn.LoadStateDictEntries(m, entries, false)
+	return err
+}
+
+// layerDims returns the dimensions of the weights of a linear layer, which
+// may have been quantized.
+func layerDims(lm *linear.Model) (rows, cols int) {
+	if lm.Int8 != nil {
+		return lm.Int8.Rows(), lm.Int8.Columns()
+	}
+	return lm.W.Dims()
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lora
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/activation"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/stack"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInject(t *testing.T) {
+	model := newTestModel()
+	xs := newTestInputs(5)
+	expected := forward(model, xs)
+
+	paths, err := Inject(model, Config{Targets: []string{"layers.0"}, Rank: 2, Alpha: 4, Trainable: []string{"layers.2.b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"layers.0"}, paths)
+
+	adapter := model.Layers[0].(*linear.Model).LoRA
+	require.NotNil(t, adapter)
+	assert.Equal(t, mat.Float(2), adapter.Scale)
+	assert.Equal(t, 2, adapter.A.Value().Rows())
+	assert.Equal(t, 6, adapter.A.Value().Columns())
+	assert.Equal(t, 8, adapter.B.Value().Rows())
+	assert.Equal(t, 2, adapter.B.Value().Columns())
+	assert.NotEqual(t, mat.Float(0), adapter.A.Value().Norm(2))
+	assert.Nil(t, model.Layers[2].(*linear.Model).LoRA)
+	assert.Equal(t, map[string]*linear.LoRA{"layers.0": adapter}, Adapters(model))
+
+	var trainable []string
+	nn.ForEachNamedParam(model, func(name string, param nn.Param) {
+		if param.RequiresGrad() {
+			trainable = append(trainable, name)
+		}
+	})
+	assert.Equal(t, []string{"layers.0.lora.a", "layers.0.lora.b", "layers.2.b"}, trainable)
+
+	// B is zero: the output is unchanged
+	actual := forward(model, xs)
+	for i := range expected {
+		assert.InDeltaSlice(t, expected[i], actual[i], 1e-6)
+	}
+
+	_, err = Inject(model, Config{Targets: []string{"layers.*"}, Rank: 2})
+	assert.Error(t, err, "already adapted")
+}
+
+func TestInject_Errors(t *testing.T) {
+	for _, config := range []Config{
+		{Targets: []string{"layers.0"}, Rank: 0},
+		{Rank: 2},
+		{Targets: []string{"["}, Rank: 2},
+		{Targets: []string{"layers.0"}, Trainable: []string{"["}, Rank: 2},
+		{Targets: []string{"foo"}, Rank: 2},
+	} {
+		model := newTestModel()
+		_, err := Inject(model, config)
+		assert.Error(t, err, "%+v", config)
+		assert.Nil(t, model.Layers[0].(*linear.Model).LoRA)
+	}
+}
+
+func TestTrainSaveLoadMerge(t *testing.T) {
+	model := newTestModel()
+	xs := newTestInputs(5)
+	base := forward(model, xs)
+	_, err := Inject(model, Config{Targets: []string{"layers.*"}, Rank: 2, Seed: 3})
+	require.NoError(t, err)
+
+	before := nn.DumpParamsVector(newTestModel()).Data()
+	for step := 0; step < 5; step++ {
+		trainStep(model, xs)
+	}
+	w := model.Layers[0].(*linear.Model).W.Value().Data()
+	assert.Equal(t, before[:len(w)], w, "the base weights are frozen")
+	tuned := forward(model, xs)
+	assert.NotEqual(t, base, tuned)
+
+	filename := filepath.Join(t.TempDir(), "adapters.sd")
+	require.NoError(t, Save(model, filename))
+	buf := new(bytes.Buffer)
+	require.NoError(t, Write(model, buf))
+	entries, err := nn.ReadStateDict(buf)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"layers.0.lora.a", "layers.0.lora.b", "layers.2.lora.a", "layers.2.lora.b"}, names)
+
+	// a new base model with the same (untrained) adapters
+	loaded := newTestModel()
+	_, err = Inject(loaded, Config{Targets: []string{"layers.*"}, Rank: 2})
+	require.NoError(t, err)
+	require.NoError(t, Load(loaded, filename))
+	actual := forward(loaded, xs)
+	for i := range tuned {
+		assert.InDeltaSlice(t, tuned[i], actual[i], 1e-6)
+	}
+
+	paths, err := Merge(loaded)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"layers.0", "layers.2"}, paths)
+	assert.Empty(t, Adapters(loaded))
+	actual = forward(loaded, xs)
+	for i := range tuned {
+		assert.InDeltaSlice(t, tuned[i], actual[i], 1e-5)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	model := newTestModel()
+	_, err := Inject(model, Config{Targets: []string{"layers.*"}, Rank: 2})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, Write(model, buf))
+	data := buf.Bytes()
+
+	t.Run("missing", func(t *testing.T) {
+		dst := newTestModel()
+		_, err := Inject(dst, Config{Targets: []string{"layers.*"}, Rank: 2, Trainable: []string{"layers.0.b"}})
+		require.NoError(t, err)
+		assert.Error(t, Read(dst, bytes.NewReader(data)))
+	})
+
+	t.Run("unexpected", func(t *testing.T) {
+		dst := newTestModel()
+		_, err := Inject(dst, Config{Targets: []string{"layers.0"}, Rank: 2})
+		require.NoError(t, err)
+		assert.Error(t, Read(dst, bytes.NewReader(data)))
+	})
+
+	t.Run("shape mismatch", func(t *testing.T) {
+		dst := newTestModel()
+		_, err := Inject(dst, Config{Targets: []string{"layers.*"}, Rank: 3})
+		require.NoError(t, err)
+		assert.Error(t, Read(dst, bytes.NewReader(data)))
+	})
+}
+
+func TestInject_BERT(t *testing.T) {
+	model := bert.NewDefaultBERT(bert.Config{
+		HiddenAct:             "gelu",
+		HiddenSize:            4,
+		IntermediateSize:      8,
+		MaxPositionEmbeddings: 8,
+		NumAttentionHeads:     2,
+		NumHiddenLayers:       2,
+		TypeVocabSize:         2,
+		VocabSize:             10,
+		Training:              true,
+	}, t.TempDir())
+	defer model.Embeddings.Words.Close()
+
+	paths, err := Inject(model, Config{
+		Targets:   []string{"encoder.layers.*.multiheadattention.attention.*.query", "encoder.layers.*.multiheadattention.attention.*.value"},
+		Rank:      2,
+		Trainable: []string{"classifier.*"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"encoder.layers.0.multiheadattention.attention.0.query",
+		"encoder.layers.0.multiheadattention.attention.0.value",
+		"encoder.layers.0.multiheadattention.attention.1.query",
+		"encoder.layers.0.multiheadattention.attention.1.value",
+		"encoder.layers.1.multiheadattention.attention.0.query",
+		"encoder.layers.1.multiheadattention.attention.0.value",
+		"encoder.layers.1.multiheadattention.attention.1.query",
+		"encoder.layers.1.multiheadattention.attention.1.value",
+	}, paths)
+
+	adapters := make(map[nn.Param]bool)
+	for _, adapter := range Adapters(model) {
+		adapters[adapter.A], adapters[adapter.B] = true, true
+	}
+	assert.Len(t, adapters, 16)
+	nn.ForEachNamedParam(model, func(name string, param nn.Param) {
+		expected := adapters[param] || name == "classifier.w" || name == "classifier.b"
+		assert.Equal(t, expected, param.RequiresGrad(), name)
+	})
+}
+
+func newTestModel() *stack.Model {
+	model := stack.New(
+		linear.New(6, 8),
+		activation.New(ag.OpTanh),
+		linear.New(8, 4),
+	)
+	rndGen := rand.NewLockedRand(42)
+	nn.ForEachParam(model, func(param nn.Param) {
+		initializers.Uniform(param.Value(), -1, 1, rndGen)
+	})
+	return model
+}
+
+func newTestInputs(n int) []mat.Matrix {
+	rndGen := rand.NewLockedRand(1)
+	xs := make([]mat.Matrix, n)
+	for i := range xs {
+		xs[i] = mat.NewEmptyVecDense(6)
+		initializers.Uniform(xs[i], -1, 1, rndGen)
+	}
+	return xs
+}
+
+func forward(model *stack.Model, xs []mat.Matrix) [][]mat.Float {
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForInference(model, g).(*stack.Model)
+	ys := make([][]mat.Float, len(xs))
+	for i, x := range xs {
+		ys[i] = g.GetCopiedValue(proc.Forward(g.NewVariable(x, false))[0]).Data()
+	}
+	return ys
+}
+
+// trainStep performs a step of gradient descent, moving the outputs towards ones.
+func trainStep(model *stack.Model, xs []mat.Matrix) {
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForTraining(model, g).(*stack.Model)
+	var loss ag.Node
+	for _, x := range xs {
+		y := proc.Forward(g.NewVariable(x, false))[0]
+		loss = g.Add(loss, losses.MSE(g, y, g.NewVariable(mat.NewInitVecDense(4, 1), false), false))
+	}
+	g.Backward(loss)
+	nn.ForEachParam(model, func(param nn.Param) {
+		if param.HasGrad() {
+			param.ApplyDelta(param.Grad().ProdScalar(0.1))
+		}
+	})
+	nn.ZeroGrad(model)
+}
@@ -5,7 +5,7 @@
 package bls
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"log"
@@ -53,7 +53,7 @@ func (l *BroadLearningAlgorithm) optimizeFeaturesWeight() {
 	for i := 0; i < l.Model.NumOfFeatures; i++ {
 		z := mat.ConcatH(featuresMap[i]...)
 		wz := admn(z, x, 1e-3, 100) // weight optimization
-		l.Model.Wz[i].Value().SetData(wz.T().Data())
+		l.Model.Wz[i].ReplaceValue(wz.T())
 	}
 }
 
@@ -69,7 +69,7 @@ func (l *BroadLearningAlgorithm) zhs() []mat.Matrix {
 }
 
 func (l *BroadLearningAlgorithm) updateOutputWeights(w mat.Matrix) {
-	l.Model.W.Value().SetData(w.T().Data())
+	l.Model.W.ReplaceValue(w.T())
 }
 
 func (l *BroadLearningAlgorithm) log(message string) {
@@ -5,7 +5,8 @@
 package bls
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -60,3 +61,16 @@ func Test_admn(t *testing.T) {
 		-0.5242062, 0.2030716,
 	}, b.Data(), 1.0e-5)
 }
+
+func TestBroadLearningAlgorithm_updateOutputWeights_HalfPrecision(t *testing.T) {
+	model := &Model{W: nn.NewParam(mat.NewEmptyDense(2, 3))}
+	model.W.SetDType(mat.DTypeBFloat16)
+	l := &BroadLearningAlgorithm{Model: model}
+	l.updateOutputWeights(mat.NewDense(3, 2, []mat.Float{
+		1, 2,
+		3, 4,
+		5, 6,
+	}))
+	assert.Equal(t, []mat.Float{1, 3, 5, 2, 4, 6}, model.W.Value().Data())
+	assert.Equal(t, mat.DTypeBFloat16, model.W.DType())
+}
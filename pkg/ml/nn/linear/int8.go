@@ -0,0 +1,247 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linear
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+// Int8Weights is a weight matrix quantized to 8-bit integers with per-row
+// (i.e. per output unit) scales: the original value at (i, j) is approximated
+// by Data[i*Cols+j] * Scales[i].
+//
+// It is meant for inference only: the gradients are propagated to the input
+// of the multiplication, but the quantized weights can't be trained.
+type Int8Weights struct {
+	rows   int
+	cols   int
+	data   []int8
+	scales []mat.Float
+}
+
+// QuantizeInt8 returns the symmetric int8 quantization of the matrix w.
+//
+// The scale of each row is computed so that the largest absolute value of the
+// row, multiplied by the corresponding clip ratio, is mapped to 127; values
+// beyond that range are saturated. Ratios lower than 1 trade the precision of
+// the outliers for a finer resolution of all other values.
+// If clipRatios is nil, no clipping is performed (i.e. all ratios are 1).
+func QuantizeInt8(w mat.Matrix, clipRatios []mat.Float) *Int8Weights {
+	rows, cols := w.Dims()
+	if clipRatios != nil && len(clipRatios) != rows {
+		panic("linear: the number of clip ratios must match the number of rows")
+	}
+	q := &Int8Weights{
+		rows:   rows,
+		cols:   cols,
+		data:   make([]int8, rows*cols),
+		scales: make([]mat.Float, rows),
+	}
+	data := w.Data()
+	for i := 0; i < rows; i++ {
+		ratio := mat.Float(1)
+		if clipRatios != nil {
+			ratio = clipRatios[i]
+		}
+		q.scales[i] = quantizeInt8Row(data[i*cols:(i+1)*cols], q.data[i*cols:(i+1)*cols], ratio)
+	}
+	return q
+}
+
+// quantizeInt8Row quantizes the values of src into dst, returning the scale.
+func quantizeInt8Row(src []mat.Float, dst []int8, clipRatio mat.Float) mat.Float {
+	maxAbs := mat.Float(0)
+	for _, v := range src {
+		if a := mat.Abs(v); a > maxAbs {
+			maxAbs = a
+		}
+	}
+	scale := mat.Float(float32(maxAbs * clipRatio / 127)) // scales are serialized as float32
+	if scale == 0 {
+		for j := range dst {
+			dst[j] = 0
+		}
+		return 0
+	}
+	for j, v := range src {
+		r := mat.Round(v / scale)
+		switch {
+		case r > 127:
+			r = 127
+		case r < -127:
+			r = -127
+		}
+		dst[j] = int8(r)
+	}
+	return scale
+}
+
+// Rows returns the number of rows of the matrix.
+func (q *Int8Weights) Rows() int {
+	return q.rows
+}
+
+// Columns returns the number of columns of the matrix.
+func (q *Int8Weights) Columns() int {
+	return q.cols
+}
+
+// Scales returns the scale of each row. The slice must not be modified.
+func (q *Int8Weights) Scales() []mat.Float {
+	return q.scales
+}
+
+// Dequantize returns a new Dense matrix with the approximated values.
+func (q *Int8Weights) Dequantize() *mat.Dense {
+	out := mat.GetDenseWorkspace(q.rows, q.cols)
+	data := out.Data()
+	for i := 0; i < q.rows; i++ {
+		scale := q.scales[i]
+		row := q.data[i*q.cols : (i+1)*q.cols]
+		for j, v := range row {
+			data[i*q.cols+j] = mat.Float(v) * scale
+		}
+	}
+	return out
+}
+
+// Mul performs the multiplication of the quantized matrix by x, returning
+// a new Dense matrix. The values of x are used as they are (int8×float): the
+// products of each row are accumulated before applying the row scale.
+func (q *Int8Weights) Mul(x mat.Matrix) *mat.Dense {
+	if x.Rows() != q.cols {
+		panic("linear: matrices with not compatible size")
+	}
+	xCols := x.Columns()
+	out := mat.GetDenseWorkspace(q.rows, xCols)
+	outData := out.Data()
+	xData := x.Data()
+
+	if xCols == 1 {
+		for i := 0; i < q.rows; i++ {
+			outData[i] = dotInt8(q.data[i*q.cols:(i+1)*q.cols], xData) * q.scales[i]
+		}
+		return out
+	}
+
+	xt := x.T() // each row of xt is a column of x, for contiguous access
+	defer mat.ReleaseMatrix(xt)
+	xtData := xt.Data()
+	for i := 0; i < q.rows; i++ {
+		row := q.data[i*q.cols : (i+1)*q.cols]
+		scale := q.scales[i]
+		for k := 0; k < xCols; k++ {
+			outData[i*xCols+k] = dotInt8(row, xtData[k*q.cols:(k+1)*q.cols]) * scale
+		}
+	}
+	return out
+}
+
+// MulT performs the multiplication of the transposed quantized matrix by x,
+// returning a new Dense matrix. It is used to propagate the gradients.
+func (q *Int8Weights) MulT(x mat.Matrix) *mat.Dense {
+	if x.Rows() != q.rows {
+		panic("linear: matrices with not compatible size")
+	}
+	xCols := x.Columns()
+	out := mat.GetEmptyDenseWorkspace(q.cols, xCols)
+	outData := out.Data()
+	xData := x.Data()
+	for i := 0; i < q.rows; i++ {
+		row := q.data[i*q.cols : (i+1)*q.cols]
+		scale := q.scales[i]
+		for k := 0; k < xCols; k++ {
+			v := xData[i*xCols+k] * scale
+			if v == 0 {
+				continue
+			}
+			for j, w := range row {
+				outData[j*xCols+k] += mat.Float(w) * v
+			}
+		}
+	}
+	return out
+}
+
+// dotInt8 returns the dot product of the int8 vector a and the float vector b.
+func dotInt8(a []int8, b []mat.Float) mat.Float {
+	b = b[:len(a)] // optimize bounds checks
+	var s0, s1, s2, s3 mat.Float
+	n := len(a) &^ 3
+	for j := 0; j < n; j += 4 {
+		s0 += mat.Float(a[j]) * b[j]
+		s1 += mat.Float(a[j+1]) * b[j+1]
+		s2 += mat.Float(a[j+2]) * b[j+2]
+		s3 += mat.Float(a[j+3]) * b[j+3]
+	}
+	for j := n; j < len(a); j++ {
+		s0 += mat.Float(a[j]) * b[j]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// MarshalBinary marshals the quantized matrix into binary form.
+func (q Int8Weights) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8+q.rows*4+len(q.data))
+	binary.LittleEndian.PutUint32(data[0:], uint32(q.rows))
+	binary.LittleEndian.PutUint32(data[4:], uint32(q.cols))
+	for i, s := range q.scales {
+		binary.LittleEndian.PutUint32(data[8+i*4:], math.Float32bits(float32(s)))
+	}
+	offset := 8 + q.rows*4
+	for i, v := range q.data {
+		data[offset+i] = byte(v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary unmarshals a binary representation of a quantized matrix.
+func (q *Int8Weights) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("linear: invalid Int8Weights binary data")
+	}
+	q.rows = int(binary.LittleEndian.Uint32(data[0:]))
+	q.cols = int(binary.LittleEndian.Uint32(data[4:]))
+	offset := 8 + q.rows*4
+	if len(data) != offset+q.rows*q.cols {
+		return fmt.Errorf("linear: invalid Int8Weights binary data size")
+	}
+	q.scales = make([]mat.Float, q.rows)
+	for i := range q.scales {
+		q.scales[i] = mat.Float(math.Float32frombits(binary.LittleEndian.Uint32(data[8+i*4:])))
+	}
+	q.data = make([]int8, q.rows*q.cols)
+	for i := range q.data {
+		q.data[i] = int8(data[offset+i])
+	}
+	return nil
+}
+
+var _ fn.Function = &int8Mul{}
+
+// int8Mul is an operator to perform the multiplication of quantized weights by x.
+type int8Mul struct {
+	w *Int8Weights
+	x fn.Operand
+}
+
+// Forward computes the output of the function.
+func (r *int8Mul) Forward() mat.Matrix {
+	return r.w.Mul(r.x.Value())
+}
+
+// Backward computes the backward pass.
+func (r *int8Mul) Backward(gy mat.Matrix) {
+	if r.x.RequiresGrad() {
+		gx := r.w.MulT(gy)
+		defer mat.ReleaseDense(gx)
+		r.x.PropagateGrad(gx)
+	}
+}
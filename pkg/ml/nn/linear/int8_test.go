@@ -0,0 +1,137 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linear
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantizeInt8(t *testing.T) {
+	w := mat.NewDense(2, 3, []mat.Float{
+		0.5, -1.27, 0.0,
+		0.0, 0.0, 0.0,
+	})
+	q := QuantizeInt8(w, nil)
+	assert.Equal(t, 2, q.Rows())
+	assert.Equal(t, 3, q.Columns())
+	assert.InDeltaSlice(t, []mat.Float{0.01, 0}, q.Scales(), 1.0e-6)
+	assert.Equal(t, []int8{50, -127, 0, 0, 0, 0}, q.data)
+	assert.InDeltaSlice(t, w.Data(), q.Dequantize().Data(), 1.0e-6)
+
+	t.Run("clipping saturates the outliers", func(t *testing.T) {
+		q := QuantizeInt8(w, []mat.Float{0.5, 1})
+		assert.Equal(t, []int8{100, -127, 0, 0, 0, 0}, q.data)
+		assert.InDeltaSlice(t, []mat.Float{0.005, 0}, q.Scales(), 1.0e-6)
+	})
+
+	t.Run("it panics with mismatching clip ratios", func(t *testing.T) {
+		assert.Panics(t, func() { QuantizeInt8(w, []mat.Float{1}) })
+	})
+}
+
+func TestInt8Weights_Mul(t *testing.T) {
+	w := newTestModel().W.Value()
+	q := QuantizeInt8(w, nil)
+	deq := q.Dequantize()
+
+	x := mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0})
+	assert.InDeltaSlice(t, deq.Mul(x).Data(), q.Mul(x).Data(), 1.0e-5)
+	assert.InDeltaSlice(t, w.Mul(x).Data(), q.Mul(x).Data(), 0.02)
+
+	xs := mat.NewDense(4, 2, []mat.Float{
+		-0.8, 0.1,
+		-0.9, 0.2,
+		-0.9, 0.3,
+		1.0, 0.4,
+	})
+	assert.InDeltaSlice(t, deq.Mul(xs).Data(), q.Mul(xs).Data(), 1.0e-5)
+
+	gy := mat.NewDense(5, 2, []mat.Float{
+		0.1, 0.2,
+		0.3, 0.4,
+		0.5, 0.6,
+		0.7, 0.8,
+		0.9, 1.0,
+	})
+	assert.InDeltaSlice(t, deq.T().Mul(gy).Data(), q.MulT(gy).Data(), 1.0e-5)
+}
+
+func TestInt8Weights_MarshalBinary(t *testing.T) {
+	q := QuantizeInt8(newTestModel().W.Value(), nil)
+	data, err := q.MarshalBinary()
+	require.NoError(t, err)
+
+	q2 := new(Int8Weights)
+	require.NoError(t, q2.UnmarshalBinary(data))
+	assert.Equal(t, q, q2)
+
+	assert.Error(t, q2.UnmarshalBinary(data[:len(data)-1]))
+}
+
+func TestModel_Quantize(t *testing.T) {
+	model := newTestModel()
+	expected := forwardTestModel(t, model, false)
+
+	model.Quantize(nil)
+	assert.Nil(t, model.W)
+	assert.InDeltaSlice(t, expected, forwardTestModel(t, model, true), 0.02)
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(model))
+	decoded := new(Model)
+	require.NoError(t, gob.NewDecoder(&buf).Decode(decoded))
+	assert.Equal(t, model.Int8, decoded.Int8)
+	assert.InDeltaSlice(t, expected, forwardTestModel(t, decoded, true), 0.02)
+}
+
+func TestModel_Quantize_Params(t *testing.T) {
+	model := newTestModel()
+	model.Quantize(nil)
+
+	var params []nn.Param
+	nn.ForEachParam(model, func(param nn.Param) {
+		params = append(params, param)
+	})
+	assert.Equal(t, []nn.Param{model.B}, params)
+
+	vector := nn.DumpParamsVector(model)
+	assert.Equal(t, model.B.Value().Data(), vector.Data())
+	nn.LoadParamsVector(model, mat.NewVecDense([]mat.Float{1, 2, 3, 4, 5}))
+	assert.Equal(t, []mat.Float{1, 2, 3, 4, 5}, model.B.Value().Data())
+
+	var buf bytes.Buffer
+	require.NoError(t, nn.WriteStateDict(model, &buf))
+	entries, err := nn.ReadStateDict(&buf)
+	require.NoError(t, err)
+	report, err := nn.LoadStateDictEntries(newQuantizedTestModel(), entries, true)
+	assert.NoError(t, err)
+	assert.True(t, report.IsEmpty())
+}
+
+func newQuantizedTestModel() *Model {
+	model := newTestModel()
+	model.Quantize(nil)
+	return model
+}
+
+func forwardTestModel(t *testing.T, model *Model, quantized bool) []mat.Float {
+	g := ag.NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0}), true)
+	y := nn.ReifyForTraining(model, g).(*Model).Forward(x)[0]
+	g.Backward(y)
+	if quantized {
+		assert.Nil(t, model.W)
+	}
+	assert.NotNil(t, x.Grad())
+	return y.Value().Data()
+}
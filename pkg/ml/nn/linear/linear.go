@@ -8,7 +8,7 @@ import (
 	"encoding/gob"
 	"sync"
 
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
@@ -22,6 +22,10 @@ type Model struct {
 	nn.BaseModel
 	W nn.Param `spago:"type:weights"`
 	B nn.Param `spago:"type:biases"`
+	// Int8 holds the weights quantized by Quantize. If set, it replaces W, which is nil.
+	Int8 *Int8Weights `spago:"scope:model"`
+	// LoRA is an optional low-rank adapter, whose output is added to the one of the layer.
+	LoRA *LoRA
 }
 
 // Option allows to configure a new Model with your specific needs.
@@ -82,5 +86,24 @@ func (m *Model) fwdConcurrent(xs []ag.Node) []ag.Node {
 
 // y = w (dot) x + b
 func (m *Model) forward(x ag.Node) ag.Node {
-	return nn.Affine(m.Graph(), m.B, m.W, x)
+	g := m.Graph()
+	var y ag.Node
+	if m.Int8 != nil {
+		y = g.Add(m.B, g.NewOperator(&int8Mul{w: m.Int8, x: x}, x))
+	} else {
+		y = nn.Affine(g, m.B, m.W, x)
+	}
+	if m.LoRA != nil {
+		y = g.Add(y, m.LoRA.forward(x))
+	}
+	return y
+}
+
+// Quantize replaces the weights with their int8 quantization (see QuantizeInt8),
+// setting W to nil, so that it is no longer visited as a param of the model.
+// The quantized model can still propagate the gradients to its input.
+func (m *Model) Quantize(clipRatios []mat.Float) {
+	m.Int8 = QuantizeInt8(m.W.Value(), clipRatios)
+	m.W.ZeroGrad()
+	m.W = nil
 }
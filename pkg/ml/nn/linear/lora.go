@@ -0,0 +1,85 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linear
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+var (
+	_ nn.Model = &LoRA{}
+)
+
+// LoRA is a low-rank adapter of a linear layer, which adds Scale * B A x to
+// the output of the layer (see "LoRA: Low-Rank Adaptation of Large Language
+// Models", Hu et al., 2021).
+//
+// A has shape (rank x in) and B has shape (out x rank): with a small rank,
+// they have far fewer parameters than the weights they adapt.
+type LoRA struct {
+	nn.BaseModel
+	A nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:weights"`
+	// Scale multiplies the output of the adapter (usually alpha / rank).
+	Scale mat.Float
+}
+
+// NewLoRA returns a new adapter for a linear layer with the given input and
+// output sizes, with parameters initialized to zeros. A must be initialized
+// (e.g. randomly) before training, while B is usually left to zeros, so that
+// the adapted layer starts computing the same function as the original one.
+func NewLoRA(in, out, rank int, scale mat.Float) *LoRA {
+	if rank < 1 {
+		panic("linear: the rank of the adapter must be greater than zero")
+	}
+	return &LoRA{
+		A:     nn.NewParam(mat.NewEmptyDense(rank, in)),
+		B:     nn.NewParam(mat.NewEmptyDense(out, rank)),
+		Scale: scale,
+	}
+}
+
+// Forward is not meant to be called directly: the adapter is applied by the
+// Forward of the linear layer holding it.
+func (m *LoRA) Forward(xs ...ag.Node) []ag.Node {
+	ys := make([]ag.Node, len(xs))
+	for i, x := range xs {
+		ys[i] = m.forward(x)
+	}
+	return ys
+}
+
+// y = scale * (B (dot) (A (dot) x))
+func (m *LoRA) forward(x ag.Node) ag.Node {
+	g := m.Graph()
+	y := g.Mul(m.B, g.Mul(m.A, x))
+	if m.Scale == 1 {
+		return y
+	}
+	return g.ProdScalar(y, g.NewScalar(m.Scale))
+}
+
+// Delta returns the update of the weights equivalent to the adapter, i.e. Scale * B A.
+func (m *LoRA) Delta() mat.Matrix {
+	return m.B.Value().Mul(m.A.Value()).ProdScalarInPlace(m.Scale)
+}
+
+// MergeLoRA adds the update of the adapter (see LoRA.Delta) to the weights and
+// removes the adapter, so that the layer computes the same function without
+// the overhead of the low-rank multiplications.
+// It panics if the weights have been quantized.
+func (m *Model) MergeLoRA() {
+	if m.LoRA == nil {
+		return
+	}
+	if m.Int8 != nil {
+		panic("linear: cannot merge the adapter into quantized weights")
+	}
+	delta := m.LoRA.Delta()
+	m.W.ApplyDelta(delta.ProdScalarInPlace(-1)) // ApplyDelta subtracts the delta
+	m.LoRA = nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linear
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoRAModel() *Model {
+	model := newTestModel()
+	model.W.SetRequiresGrad(false)
+	model.B.SetRequiresGrad(false)
+	model.LoRA = NewLoRA(4, 5, 2, 0.5)
+	model.LoRA.A.Value().SetData([]mat.Float{
+		0.1, 0.2, 0.3, 0.4,
+		-0.5, 0.6, -0.7, 0.8,
+	})
+	model.LoRA.B.Value().SetData([]mat.Float{
+		1, 0,
+		0, 1,
+		1, 1,
+		-1, 0,
+		0, 0,
+	})
+	return model
+}
+
+func TestModel_Forward_LoRA(t *testing.T) {
+	model := newTestLoRAModel()
+	g := ag.NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0}), true)
+	proc := nn.ReifyForTraining(model, g).(*Model)
+	y := proc.Forward(x)[0]
+
+	// A x = (-0.13, 1.29); W x + b = (-0.42, -1.09, 0.0, 0.87, -0.19)
+	assert.InDeltaSlice(t, []mat.Float{-0.485, -0.445, 0.58, 0.935, -0.19}, y.Value().Data(), 1.0e-6)
+
+	g.Backward(y, ag.OutputGrad(mat.NewInitVecDense(5, 1)))
+	assert.False(t, model.W.HasGrad())
+	assert.False(t, model.B.HasGrad())
+	require.True(t, model.LoRA.A.HasGrad())
+	require.True(t, model.LoRA.B.HasGrad())
+	// dy/dB = scale * 1 (A x)^T
+	assert.InDeltaSlice(t, []mat.Float{
+		-0.065, 0.645,
+		-0.065, 0.645,
+		-0.065, 0.645,
+		-0.065, 0.645,
+		-0.065, 0.645,
+	}, model.LoRA.B.Grad().Data(), 1.0e-6)
+}
+
+func TestModel_MergeLoRA(t *testing.T) {
+	model := newTestLoRAModel()
+	x := mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0})
+	g := ag.NewGraph()
+	expected := nn.ReifyForInference(model, g).(*Model).Forward(g.NewVariable(x, false))[0].Value().Data()
+
+	model.MergeLoRA()
+	assert.Nil(t, model.LoRA)
+	g = ag.NewGraph()
+	actual := nn.ReifyForInference(model, g).(*Model).Forward(g.NewVariable(x, false))[0].Value().Data()
+	assert.InDeltaSlice(t, expected, actual, 1.0e-6)
+
+	t.Run("it panics with quantized weights", func(t *testing.T) {
+		model := newTestLoRAModel()
+		model.Quantize(nil)
+		assert.Panics(t, func() { model.MergeLoRA() })
+	})
+}
+
+func TestNewLoRA(t *testing.T) {
+	assert.Panics(t, func() { NewLoRA(4, 5, 0, 1) })
+}
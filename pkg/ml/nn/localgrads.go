@@ -0,0 +1,104 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// LocalGrads collects the gradients of the params of a processor reified by
+// ReifyWithLocalGrads, instead of accumulating them directly in the params.
+// This allows several processors of the same model, running concurrently on
+// different graphs, to add up their gradients in a deterministic order.
+type LocalGrads struct {
+	mu     sync.Mutex
+	values map[*param]*localGradValue
+	order  []*localGradValue
+}
+
+// ReifyWithLocalGrads returns a new reified model (a.k.a. processor), as
+// Reify, whose gradients are collected by the returned LocalGrads.
+// Call LocalGrads.Flush to accumulate them in the params of the model.
+func ReifyWithLocalGrads(m Model, g *ag.Graph, mode ProcessingMode) (Model, *LocalGrads) {
+	grads := &LocalGrads{values: make(map[*param]*localGradValue)}
+	r := newReifier(g, mode)
+	r.grads = grads
+	return r.reify(m), grads
+}
+
+// wrap returns the param wrapped in the graph, with local gradients.
+// The same param is always wrapped with the same local gradients.
+func (l *LocalGrads) wrap(g *ag.Graph, p *param) *wrappedParam {
+	if !p.requiresGrad {
+		return p.wrappedParam(g)
+	}
+	l.mu.Lock()
+	value, ok := l.values[p]
+	if !ok {
+		value = &localGradValue{param: p, GradValue: p}
+		if p.half != nil {
+			value.GradValue = &upcastParam{param: p}
+		}
+		l.values[p] = value
+		l.order = append(l.order, value)
+	}
+	l.mu.Unlock()
+	return &wrappedParam{param: p, Node: g.NewWrap(value)}
+}
+
+// Flush adds the local gradients to the params, and zeros them.
+// It must not be called concurrently with the backward step.
+func (l *LocalGrads) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, value := range l.order {
+		if value.grad != nil {
+			value.param.PropagateGrad(value.grad)
+		}
+		value.ZeroGrad()
+	}
+}
+
+// localGradValue is a view of a param with its own gradients.
+type localGradValue struct {
+	ag.GradValue // the param, or its upcast view
+	param        *param
+	mu           sync.Mutex
+	grad         mat.Matrix
+}
+
+// Grad returns the local gradients.
+func (r *localGradValue) Grad() mat.Matrix {
+	return r.grad
+}
+
+// HasGrad returns true if there are local gradients.
+func (r *localGradValue) HasGrad() bool {
+	return r.grad != nil
+}
+
+// PropagateGrad accumulates the gradients locally.
+func (r *localGradValue) PropagateGrad(gx mat.Matrix) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.grad == nil {
+		r.grad = mat.GetEmptyDenseWorkspace(r.param.dims())
+	}
+	r.grad.AddInPlace(gx)
+}
+
+// ZeroGrad clears the local gradients.
+func (r *localGradValue) ZeroGrad() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.grad == nil {
+		return
+	}
+	mat.ReleaseMatrix(r.grad)
+	r.grad = nil
+}
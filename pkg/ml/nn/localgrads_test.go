@@ -0,0 +1,36 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReifyWithLocalGrads(t *testing.T) {
+	shared := NewParam(mat.NewVecDense([]mat.Float{1, 2}))
+	frozen := NewParam(mat.NewVecDense([]mat.Float{3, 4}), RequiresGrad(false))
+	m := &StateDictRoot{
+		Leaf:   &StateDictLeaf{W: shared, B: frozen},
+		Shared: shared,
+	}
+
+	g := ag.NewGraph()
+	proc, grads := ReifyWithLocalGrads(m, g, Training)
+	p := proc.(*StateDictRoot)
+	g.Backward(g.Add(g.Add(p.Leaf.W, p.Shared), p.Leaf.B), ag.OutputGrad(mat.NewVecDense([]mat.Float{1, 2})))
+
+	assert.Equal(t, []mat.Float{2, 4}, p.Shared.Grad().Data())
+	assert.False(t, shared.HasGrad())
+	assert.False(t, frozen.HasGrad())
+
+	grads.Flush()
+	assert.Equal(t, []mat.Float{2, 4}, shared.Grad().Data())
+	assert.False(t, p.Shared.HasGrad())
+	assert.False(t, frozen.HasGrad())
+}
@@ -5,7 +5,7 @@
 package nn
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 )
 
@@ -98,11 +98,20 @@ func ClearSupport(m Model) {
 	})
 }
 
+// SetParamsDType converts the storage of all model's parameters (including sub-params) to the given numeric type.
+func SetParamsDType(m Model, dtype mat.DType) {
+	ForEachParam(m, func(param Param) {
+		param.SetDType(dtype)
+	})
+}
+
 // DumpParamsVector dumps all params of a Model into a single Dense vector.
 func DumpParamsVector(model Model) mat.Matrix {
 	data := make([]mat.Float, 0)
 	ForEachParam(model, func(param Param) {
-		data = append(data, param.Value().Data()...)
+		param.ReadValue(func(value mat.Matrix) {
+			data = append(data, value.Data()...)
+		})
 	})
 	return mat.NewVecDense(data)
 }
@@ -112,8 +121,12 @@ func LoadParamsVector(model Model, vector mat.Matrix) {
 	data := vector.Data()
 	offset := 0
 	ForEachParam(model, func(param Param) {
-		size := param.Value().Size()
-		param.Value().SetData(data[offset : offset+size])
+		value := param.Value()
+		size := value.Size()
+		value.SetData(data[offset : offset+size])
+		if param.DType() != mat.DTypeFloat {
+			param.ReplaceValue(value) // the value of a half-precision param is a copy
+		}
 		offset += size
 	})
 }
@@ -0,0 +1,102 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ForEachModel calls the callback for m and for each Model nested in it,
+// exploring the sub-models recursively in depth-first order.
+//
+// Each model is identified by a dot-separated path built from the (lowercase)
+// names of the struct fields leading to it, starting from the empty path of m.
+// Items of slices and maps contribute their index or key to the path, while
+// embedded (anonymous) fields don't contribute at all: an embedded Model is
+// visited with the same path of the Model embedding it.
+func ForEachModel(m Model, callback func(path string, model Model)) {
+	modelsTraversal{callback: callback}.walk(reflect.ValueOf(m), "")
+}
+
+// modelsTraversal allows the traversal of the Model tree.
+type modelsTraversal struct {
+	callback func(path string, model Model)
+}
+
+func (mt modelsTraversal) walk(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			mt.walk(v.Elem(), path)
+		}
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return
+		}
+		if model, ok := v.Interface().(Model); ok {
+			mt.callback(path, model)
+			mt.walkFields(v.Elem(), path)
+		}
+	case reflect.Struct:
+		if v.CanAddr() {
+			mt.walk(v.Addr(), path)
+		}
+	case reflect.Slice:
+		if !mayContainModels(v.Type().Elem()) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			mt.walk(v.Index(i), joinPath(path, fmt.Sprintf("%d", i)))
+		}
+	case reflect.Map:
+		if !mayContainModels(v.Type().Elem()) {
+			return
+		}
+		keys, values := sortedMapEntries(v) // skips the maps without string or int keys
+		for i, key := range keys {
+			mt.walk(values[i], joinPath(path, key))
+		}
+	}
+}
+
+func (mt modelsTraversal) walkFields(v reflect.Value, path string) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field, tField := v.Field(i), t.Field(i)
+		if !field.CanInterface() {
+			continue // unexported fields are not part of the model
+		}
+		if tField.Anonymous {
+			if field.Kind() == reflect.Struct {
+				mt.walkFields(field, path) // e.g. BaseModel
+			} else {
+				mt.walk(field, path) // e.g. an embedded *linear.Model
+			}
+			continue
+		}
+		if mayContainModels(tField.Type) {
+			mt.walk(field, joinPath(path, strings.ToLower(tField.Name)))
+		}
+	}
+}
+
+// mayContainModels reports whether a value of type t can be or contain a Model.
+func mayContainModels(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Slice, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ModelsTraversalLeaf struct {
+	ParamsTraversalBaseModel
+	W Param
+}
+
+type ModelsTraversalEmbedding struct {
+	*ModelsTraversalLeaf
+}
+
+type ModelsTraversalRoot struct {
+	ParamsTraversalBaseModel
+	Leaf      *ModelsTraversalLeaf
+	Layers    []Model
+	Named     map[string]*ModelsTraversalLeaf
+	Embedding *ModelsTraversalEmbedding
+	Nil       *ModelsTraversalLeaf
+	Other     []int
+	private   *ModelsTraversalLeaf
+}
+
+func TestForEachModel(t *testing.T) {
+	leaf := func() *ModelsTraversalLeaf { return &ModelsTraversalLeaf{} }
+	m := &ModelsTraversalRoot{
+		Leaf:      leaf(),
+		Layers:    []Model{leaf(), nil, leaf()},
+		Named:     map[string]*ModelsTraversalLeaf{"foo": leaf()},
+		Embedding: &ModelsTraversalEmbedding{leaf()},
+		Other:     []int{1, 2, 3},
+		private:   leaf(),
+	}
+
+	visited := map[string][]Model{}
+	ForEachModel(m, func(path string, model Model) {
+		visited[path] = append(visited[path], model)
+	})
+
+	assert.Equal(t, map[string][]Model{
+		"":          {m},
+		"leaf":      {m.Leaf},
+		"layers.0":  {m.Layers[0]},
+		"layers.2":  {m.Layers[2]},
+		"named.foo": {m.Named["foo"]},
+		"embedding": {m.Embedding, m.Embedding.ModelsTraversalLeaf},
+	}, visited)
+}
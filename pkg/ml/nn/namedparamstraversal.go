@@ -0,0 +1,136 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/embeddings/syncmap"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+// namedParamsTraversal visits the parameters declared by a single Model, like
+// ForEachParamStrict, along with their names relative to the model.
+//
+// Unlike Param.Name, which is assigned once by the first traversal and never
+// changes, the names are derived from the structure of the model at each
+// call: the (lowercase) name of the field, followed by the index of the item
+// for slices, and by the key for maps, keeping its case as the model paths do
+// (see ForEachModel). Maps are visited in the order of their keys, so that
+// the traversal is deterministic.
+type namedParamsTraversal struct {
+	callback func(name string, param *param)
+}
+
+func (nt namedParamsTraversal) walk(m interface{}, prefix string) {
+	utils.ForEachField(m, func(field interface{}, name string, rTag reflect.StructTag) {
+		tag, err := parseModuleFieldTag(rTag.Get("spago"))
+		if err != nil {
+			panic(err)
+		}
+		name = joinPath(prefix, strings.ToLower(name))
+		v := reflect.ValueOf(field)
+		switch v.Kind() {
+		case reflect.Struct, reflect.Ptr, reflect.Interface:
+			nt.walkItem(field, name, name, tag)
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				nt.walkValue(v.Index(i), joinPath(name, fmt.Sprintf("%d", i)), name, tag)
+			}
+		case reflect.Map:
+			keys, values := sortedMapEntries(v)
+			for i, key := range keys {
+				keyName := joinPath(name, key)
+				nt.walkValue(values[i], keyName, strings.ToLower(keyName), tag)
+			}
+		}
+	})
+}
+
+func (nt namedParamsTraversal) walkValue(v reflect.Value, name, fieldName string, tag moduleFieldTag) {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Interface:
+		nt.walkItem(v.Interface(), name, fieldName, tag)
+	}
+}
+
+// walkItem visits a value found at the given name. The fieldName is the name
+// assigned to the params still without one, as ForEachParamStrict does.
+func (nt namedParamsTraversal) walkItem(item interface{}, name, fieldName string, tag moduleFieldTag) {
+	v := reflect.ValueOf(item)
+	if !v.IsValid() || v.Kind() == reflect.Ptr && (v.IsNil() || v.Elem().Kind() != reflect.Struct) {
+		return
+	}
+	switch itemT := item.(type) {
+	case *param:
+		if itemT.Name() == "" {
+			itemT.SetName(fieldName)
+		}
+		itemT.SetType(tag.paramType())
+		nt.callback(name, itemT)
+	case Model:
+		return // the sub-models are visited on their own
+	case *sync.Map:
+		nt.walkSyncMap(itemT, name, tag)
+	case *syncmap.Map:
+		nt.walkSyncMap(itemT.Map, name, tag)
+	default:
+		if tag.Type == paramsModuleFieldType {
+			nt.walk(item, name)
+		}
+	}
+}
+
+func (nt namedParamsTraversal) walkSyncMap(m *sync.Map, name string, tag moduleFieldTag) {
+	if tag.Type != paramsModuleFieldType {
+		return
+	}
+	entries := make(map[string]interface{})
+	m.Range(func(key, value interface{}) bool {
+		switch k := key.(type) {
+		case string:
+			entries[k] = value
+		case int:
+			entries[fmt.Sprintf("%d", k)] = value
+		default:
+			return false // skip map if the key is not a string or an int
+		}
+		return true
+	})
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		keyName := joinPath(name, key)
+		nt.walkItem(entries[key], keyName, strings.ToLower(keyName), tag)
+	}
+}
+
+// sortedMapEntries returns the keys, formatted as strings, and the values of
+// a map with string or int keys, sorted by key. Other maps are skipped.
+func sortedMapEntries(v reflect.Value) ([]string, []reflect.Value) {
+	keys := v.MapKeys()
+	switch v.Type().Key().Kind() {
+	case reflect.String:
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	case reflect.Int:
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Int() < keys[j].Int() })
+	default:
+		return nil, nil
+	}
+	names := make([]string, len(keys))
+	values := make([]reflect.Value, len(keys))
+	for i, key := range keys {
+		names[i] = fmt.Sprintf("%v", key.Interface())
+		values[i] = v.MapIndex(key)
+	}
+	return names, values
+}
@@ -6,7 +6,7 @@ package nn
 
 import (
 	"bytes"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/utils/kvdb"
 	"log"
@@ -17,6 +17,19 @@ import (
 type Param interface {
 	ag.Node // it implies fn.Operand and ag.GradValue too
 
+	// Value returns the value of the param. If the param is stored in
+	// half-precision (see DType), a new upcast matrix is returned at each
+	// call: any change to it, such as SetData or an initializer, is silently
+	// lost, and must be performed with ReplaceValue or ApplyDelta instead.
+	// Use ReadValue or Dims to inspect the value without allocating a copy.
+	Value() mat.Matrix
+	// ReadValue calls f with the value of the param, which must be treated as
+	// read-only and not retained after f returns. A half-precision value is
+	// upcast to a workspace, released when f returns.
+	ReadValue(f func(value mat.Matrix))
+	// Dims returns the dimensions of the value, without upcasting it, or
+	// (0, 0) if the param has no value.
+	Dims() (rows, cols int)
 	// Name returns the params name (can be empty string).
 	Name() string
 	// SetName set the params name (can be empty string).
@@ -27,6 +40,11 @@ type Param interface {
 	SetType(pType ParamsType)
 	// SetRequiresGrad set whether the param requires gradient, or not.
 	SetRequiresGrad(value bool)
+	// DType returns the numeric type used to store the value of the param.
+	DType() mat.DType
+	// SetDType converts the storage of the value to the given numeric type.
+	// Regardless of the storage, the value is always returned as mat.Float.
+	SetDType(dtype mat.DType)
 	// ReplaceValue replaces the value of the parameter and clears the support structure.
 	ReplaceValue(value mat.Matrix)
 	// ApplyDelta updates the value of the underlying storage applying the delta.
@@ -56,11 +74,12 @@ var _ Param = &param{}
 
 type param struct {
 	name         string
-	pType        ParamsType // lazy initialization
-	mu           sync.Mutex // to avoid data race
-	value        mat.Matrix // store the results of a forward evaluation.
-	grad         mat.Matrix // TODO: support of sparse gradients
-	payload      *Payload   // additional data used for example by gradient-descend optimization methods
+	pType        ParamsType     // lazy initialization
+	mu           sync.Mutex     // to avoid data race
+	value        mat.Matrix     // store the results of a forward evaluation.
+	half         *mat.HalfDense // replaces value when the param is stored in half-precision
+	grad         mat.Matrix     // TODO: support of sparse gradients
+	payload      *Payload       // additional data used for example by gradient-descend optimization methods
 	hasGrad      bool
 	requiresGrad bool
 	storage      *kvdb.KeyValueDB // default nil
@@ -85,6 +104,15 @@ func SetStorage(storage *kvdb.KeyValueDB) ParamOption {
 	}
 }
 
+// StorageDType is an option to specify the numeric type used to store the
+// value of a Param. Half-precision types halve the memory footprint of the
+// param, at the cost of upcasting the value each time it is accessed.
+func StorageDType(dtype mat.DType) ParamOption {
+	return func(p *param) {
+		p.setDType(dtype)
+	}
+}
+
 // NewParam returns a new param.
 func NewParam(value mat.Matrix, opts ...ParamOption) Param {
 	p := &param{
@@ -123,16 +151,109 @@ func (r *param) Type() ParamsType {
 	return r.pType
 }
 
+// DType returns the numeric type used to store the value of the param.
+func (r *param) DType() mat.DType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dtype()
+}
+
+func (r *param) dtype() mat.DType {
+	if r.half != nil {
+		return r.half.DType()
+	}
+	return mat.DTypeFloat
+}
+
+// SetDType converts the storage of the value to the given numeric type.
+// Regardless of the storage, the value is always returned as mat.Float.
+func (r *param) SetDType(dtype mat.DType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.setDType(dtype) && r.storage != nil {
+		r.updateStorage()
+	}
+}
+
+// setDType converts the storage of the value, reporting whether it changed.
+// A param without value is left untouched.
+func (r *param) setDType(dtype mat.DType) bool {
+	if dtype == r.dtype() {
+		return false
+	}
+	switch {
+	case dtype == mat.DTypeFloat:
+		r.value = r.half.Dense()
+		r.half = nil
+	case r.half != nil:
+		value := r.half.Dense()
+		r.half = mat.NewHalfDense(value, dtype)
+		mat.ReleaseDense(value)
+	case r.value != nil:
+		r.half = mat.NewHalfDense(r.value, dtype)
+		r.value = nil
+	default:
+		return false // nothing to convert
+	}
+	return true
+}
+
 // Value returns the value of the delegate itself.
+// If the param is stored in half-precision, a new upcast matrix is returned
+// at each call: any change to it does not affect the param, and must be
+// performed with ReplaceValue or ApplyDelta instead.
 func (r *param) Value() mat.Matrix {
-	return r.value
+	value, half := r.load()
+	if half != nil {
+		return half.Dense()
+	}
+	return value
+}
+
+// ReadValue calls f with the value of the param, which must be treated as
+// read-only and not retained after f returns.
+func (r *param) ReadValue(f func(value mat.Matrix)) {
+	value, half := r.load()
+	if half == nil {
+		f(value)
+		return
+	}
+	dense := half.Dense()
+	defer mat.ReleaseDense(dense)
+	f(dense)
+}
+
+// Dims returns the dimensions of the value, without upcasting it, or (0, 0)
+// if the param has no value.
+func (r *param) Dims() (rows, cols int) {
+	value, half := r.load()
+	switch {
+	case half != nil:
+		return half.Dims()
+	case value != nil:
+		return value.Dims()
+	default:
+		return 0, 0
+	}
+}
+
+// load returns the current storage of the value. Since a new HalfDense is
+// created on each update, the returned one is never modified afterwards.
+func (r *param) load() (mat.Matrix, *mat.HalfDense) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.value, r.half
 }
 
 // ReplaceValue replaces the value of the parameter and clears the support structure.
 func (r *param) ReplaceValue(value mat.Matrix) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.value = value
+	if r.half != nil {
+		r.half = mat.NewHalfDense(value, r.half.DType())
+	} else {
+		r.value = value
+	}
 	r.payload = nil
 	if r.storage != nil {
 		r.updateStorage()
@@ -143,7 +264,7 @@ func (r *param) ReplaceValue(value mat.Matrix) {
 // It panics if the value is not a scalar.
 // Note that it is not possible to start the backward step from a scalar value.
 func (r *param) ScalarValue() mat.Float {
-	return r.value.Scalar()
+	return r.Value().Scalar()
 }
 
 // Grad returns the gradients accumulated during the backward pass.
@@ -159,7 +280,7 @@ func (r *param) PropagateGrad(grad mat.Matrix) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if r.grad == nil {
-		r.grad = mat.GetEmptyDenseWorkspace(r.value.Dims()) // this could reduce the number of allocations
+		r.grad = mat.GetEmptyDenseWorkspace(r.dims()) // this could reduce the number of allocations
 	}
 	r.grad.AddInPlace(grad)
 	r.hasGrad = true
@@ -196,12 +317,26 @@ func (r *param) ZeroGrad() {
 func (r *param) ApplyDelta(delta mat.Matrix) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.Value().SubInPlace(delta)
+	if r.half != nil {
+		value := r.half.Dense()
+		value.SubInPlace(delta)
+		r.half = mat.NewHalfDense(value, r.half.DType())
+		mat.ReleaseDense(value)
+	} else {
+		r.value.SubInPlace(delta)
+	}
 	if r.storage != nil {
 		r.updateStorage()
 	}
 }
 
+func (r *param) dims() (rows, cols int) {
+	if r.half != nil {
+		return r.half.Dims()
+	}
+	return r.value.Dims()
+}
+
 // Payload returns the optimizer support structure (can be nil).
 func (r *param) Payload() *Payload {
 	r.mu.Lock()
@@ -264,10 +399,35 @@ func (r *param) TimeStep() int {
 
 // wrappedParam returns a new wrappedParam from the param itself.
 func (r *param) wrappedParam(g *ag.Graph) *wrappedParam {
+	var value ag.GradValue = r
+	if r.half != nil {
+		value = &upcastParam{param: r}
+	}
 	if r.requiresGrad {
-		return &wrappedParam{param: r, Node: g.NewWrap(r)}
+		return &wrappedParam{param: r, Node: g.NewWrap(value)}
 	}
-	return &wrappedParam{param: r, Node: g.NewWrapNoGrad(r)}
+	return &wrappedParam{param: r, Node: g.NewWrapNoGrad(value)}
+}
+
+// upcastParam is a view of a half-precision param, whose value is upcast
+// only once for the whole lifetime of the graph it is wrapped in.
+type upcastParam struct {
+	*param
+	once  sync.Once
+	value mat.Matrix
+}
+
+// Value returns the upcast value of the param.
+func (r *upcastParam) Value() mat.Matrix {
+	r.once.Do(func() {
+		r.value = r.param.Value()
+	})
+	return r.value
+}
+
+// ScalarValue returns the the scalar value of the node.
+func (r *upcastParam) ScalarValue() mat.Float {
+	return r.Value().Scalar()
 }
 
 var _ Param = &wrappedParam{}
@@ -288,6 +448,16 @@ func (r *wrappedParam) Graph() *ag.Graph {
 	return r.Node.Graph()
 }
 
+// Value dispatches the call to the Node.
+func (r *wrappedParam) Value() mat.Matrix {
+	return r.Node.Value()
+}
+
+// ScalarValue dispatches the call to the Node.
+func (r *wrappedParam) ScalarValue() mat.Float {
+	return r.Node.ScalarValue()
+}
+
 // Grad dispatches the call to the Node.
 func (r *wrappedParam) Grad() mat.Matrix {
 	return r.Node.Grad()
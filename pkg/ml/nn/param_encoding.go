@@ -9,7 +9,7 @@ import (
 	"encoding/binary"
 	"encoding/gob"
 	"fmt"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"io"
 	"log"
 )
@@ -20,11 +20,20 @@ func init() {
 	gob.Register(&param{})
 }
 
+// binaryHalfValue marks the binary form of a param stored in half-precision.
+// It must not clash with the matrix types of mat.MarshalBinaryMatrix.
+const binaryHalfValue byte = 0xff
+
 // MarshalBinary marshals a param into binary form.
 func (r *param) MarshalBinary() ([]byte, error) {
 	buf := new(bytes.Buffer)
 
-	err := mat.MarshalBinaryMatrix(r.value, buf)
+	var err error
+	if r.half != nil {
+		err = marshalBinaryHalfValue(r.half, buf)
+	} else {
+		err = mat.MarshalBinaryMatrix(r.value, buf)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +57,13 @@ func (r *param) UnmarshalBinary(data []byte) error {
 	var err error
 	buf := bytes.NewReader(data)
 
-	r.value, err = mat.UnmarshalBinaryMatrix(buf)
+	if len(data) > 0 && data[0] == binaryHalfValue {
+		r.value = nil
+		r.half, err = unmarshalBinaryHalfValue(buf)
+	} else {
+		r.half = nil
+		r.value, err = mat.UnmarshalBinaryMatrix(buf)
+	}
 	if err != nil {
 		return err
 	}
@@ -69,6 +84,37 @@ func (r *param) UnmarshalBinary(data []byte) error {
 	return r.payload.UnmarshalBinary(pBin)
 }
 
+func marshalBinaryHalfValue(h *mat.HalfDense, w io.Writer) error {
+	bin, err := h.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 5)
+	header[0] = binaryHalfValue
+	binary.LittleEndian.PutUint32(header[1:], uint32(len(bin)))
+	if _, err = w.Write(header); err != nil {
+		return err
+	}
+	_, err = w.Write(bin)
+	return err
+}
+
+func unmarshalBinaryHalfValue(r io.Reader) (*mat.HalfDense, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	bin := make([]byte, binary.LittleEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r, bin); err != nil {
+		return nil, err
+	}
+	h := new(mat.HalfDense)
+	if err := h.UnmarshalBinary(bin); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
 // MarshalBinaryParam encodes a Param into binary form.
 func MarshalBinaryParam(p Param, w io.Writer) error {
 	if p == nil {
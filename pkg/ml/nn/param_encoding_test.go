@@ -7,7 +7,9 @@ package nn
 import (
 	"bytes"
 	"encoding/gob"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat64"
 	"github.com/nlpodyssey/spago/pkg/utils/kvdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,6 +45,28 @@ func TestParam_Gob(t *testing.T) {
 		assert.Equal(t, mat.Float(34), payload.Data[0].Scalar())
 	})
 
+	t.Run("payload with bytes", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		paramToEncode := NewParam(mat.NewScalar(12))
+		paramToEncode.SetPayload(&Payload{
+			Label: 42,
+			Data:  []mat.Matrix{mat.NewScalar(34)},
+			Bytes: [][]byte{{1, 2, 3}, {}},
+		})
+
+		err := gob.NewEncoder(&buf).Encode(&paramToEncode)
+		require.Nil(t, err)
+
+		var decodedParam Param
+		err = gob.NewDecoder(&buf).Decode(&decodedParam)
+		require.Nil(t, err)
+
+		payload := decodedParam.Payload()
+		assert.Equal(t, mat.Float(34), payload.Data[0].Scalar())
+		assert.Equal(t, [][]byte{{1, 2, 3}, {}}, payload.Bytes)
+	})
+
 	t.Run("nil value and payload", func(t *testing.T) {
 		var buf bytes.Buffer
 
@@ -105,6 +129,22 @@ func TestParamInterfaceBinaryMarshaling(t *testing.T) {
 		assert.Equal(t, mat.Float(42), decodedParam.Value().Scalar())
 	})
 
+	t.Run("half-precision value", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+
+		paramToEncode := NewParam(mat.NewVecDense([]mat.Float{1, 2, 3}), StorageDType(mat.DTypeFloat16))
+		paramToEncode.SetPayload(NewPayload())
+		err := MarshalBinaryParam(paramToEncode, buf)
+		require.Nil(t, err)
+
+		decodedParam, err := UnmarshalBinaryParam(buf)
+		require.Nil(t, err)
+		require.NotNil(t, decodedParam)
+		assert.Equal(t, mat.DTypeFloat16, decodedParam.DType())
+		assert.Equal(t, []mat.Float{1, 2, 3}, decodedParam.Value().Data())
+		assert.NotNil(t, decodedParam.Payload())
+	})
+
 	t.Run("nil", func(t *testing.T) {
 		buf := new(bytes.Buffer)
 
@@ -117,3 +157,26 @@ func TestParamInterfaceBinaryMarshaling(t *testing.T) {
 		assert.Nil(t, decodedParam)
 	})
 }
+
+func TestParam_UnmarshalBinary_Precision(t *testing.T) {
+	// the binary form of a param without payload, marshaled with either precision
+	marshalers := map[string]func(buf *bytes.Buffer) error{
+		"float32": func(buf *bytes.Buffer) error {
+			return mat32.MarshalBinaryMatrix(mat32.NewVecDense([]float32{1.5, -0.25}), buf)
+		},
+		"float64": func(buf *bytes.Buffer) error {
+			return mat64.MarshalBinaryMatrix(mat64.NewVecDense([]float64{1.5, -0.25}), buf)
+		},
+	}
+	for name, marshal := range marshalers {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			require.Nil(t, marshal(buf))
+			buf.WriteByte(0)
+
+			p := new(param)
+			require.Nil(t, p.UnmarshalBinary(buf.Bytes()))
+			assert.Equal(t, []mat.Float{1.5, -0.25}, p.Value().Data())
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParam_SetDType(t *testing.T) {
+	p := NewParam(mat.NewVecDense([]mat.Float{0.1, 0.2, 0.3}))
+	assert.Equal(t, mat.DTypeFloat, p.DType())
+
+	p.SetDType(mat.DTypeBFloat16)
+	assert.Equal(t, mat.DTypeBFloat16, p.DType())
+	assert.InDeltaSlice(t, []mat.Float{0.1, 0.2, 0.3}, p.Value().Data(), 0.002)
+
+	p.SetDType(mat.DTypeFloat16)
+	assert.Equal(t, mat.DTypeFloat16, p.DType())
+	assert.InDeltaSlice(t, []mat.Float{0.1, 0.2, 0.3}, p.Value().Data(), 0.002)
+
+	p.SetDType(mat.DTypeFloat)
+	assert.Equal(t, mat.DTypeFloat, p.DType())
+	assert.InDeltaSlice(t, []mat.Float{0.1, 0.2, 0.3}, p.Value().Data(), 0.002)
+
+	empty := NewParam(nil, StorageDType(mat.DTypeFloat16))
+	assert.Equal(t, mat.DTypeFloat, empty.DType())
+}
+
+func TestParam_HalfPrecision(t *testing.T) {
+	p := NewParam(mat.NewVecDense([]mat.Float{1, 2, 3}), StorageDType(mat.DTypeFloat16))
+
+	p.ApplyDelta(mat.NewVecDense([]mat.Float{0.5, 0.5, 0.5}))
+	assert.Equal(t, []mat.Float{0.5, 1.5, 2.5}, p.Value().Data())
+	assert.Equal(t, mat.DTypeFloat16, p.DType())
+
+	p.ReplaceValue(mat.NewVecDense([]mat.Float{4, 5, 6}))
+	assert.Equal(t, []mat.Float{4, 5, 6}, p.Value().Data())
+	assert.Equal(t, mat.DTypeFloat16, p.DType())
+
+	p.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1, 1}))
+	assert.Equal(t, []mat.Float{1, 1, 1}, p.Grad().Data())
+
+	g := ag.NewGraph()
+	wp := p.(*param).wrappedParam(g)
+	value := wp.Value()
+	assert.Equal(t, []mat.Float{4, 5, 6}, value.Data())
+	assert.Same(t, value, wp.Value(), "the upcast value is cached within the graph")
+	assert.Equal(t, []mat.Float{8, 10, 12}, g.Add(wp, wp).Value().Data())
+}
+
+func TestParam_ReadValue(t *testing.T) {
+	value := mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6})
+	p := NewParam(value)
+	rows, cols := p.Dims()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 3, cols)
+	p.ReadValue(func(v mat.Matrix) {
+		assert.Same(t, value, v, "a float value is not copied")
+	})
+
+	p.SetDType(mat.DTypeFloat16)
+	rows, cols = p.Dims()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 3, cols)
+	p.ReadValue(func(v mat.Matrix) {
+		assert.Equal(t, []mat.Float{1, 2, 3, 4, 5, 6}, v.Data())
+	})
+
+	// the value of a half-precision param is a copy
+	p.Value().SetData([]mat.Float{0, 0, 0, 0, 0, 0})
+	assert.Equal(t, []mat.Float{1, 2, 3, 4, 5, 6}, p.Value().Data())
+
+	rows, cols = NewParam(nil).Dims()
+	assert.Equal(t, 0, rows)
+	assert.Equal(t, 0, cols)
+}
+
+func TestSetParamsDType(t *testing.T) {
+	type testModel struct {
+		ParamsTraversalBaseModel
+		A Param
+		B Param
+	}
+	m := &testModel{
+		A: NewParam(mat.NewScalar(1)),
+		B: NewParam(mat.NewScalar(2)),
+	}
+	SetParamsDType(m, mat.DTypeBFloat16)
+	assert.Equal(t, mat.DTypeBFloat16, m.A.DType())
+	assert.Equal(t, mat.DTypeBFloat16, m.B.DType())
+
+	vector := DumpParamsVector(m)
+	vector.SetData([]mat.Float{3, 4})
+	LoadParamsVector(m, vector)
+	assert.Equal(t, mat.Float(3), m.A.ScalarValue())
+	assert.Equal(t, mat.Float(4), m.B.ScalarValue())
+}
@@ -5,7 +5,7 @@
 package nn
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/nlp/embeddings/syncmap"
 	"reflect"
 	"sync"
@@ -7,13 +7,18 @@ package nn
 import (
 	"bytes"
 	"encoding/binary"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"io"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 // Payload contains the support data used for example by the optimization methods
 type Payload struct {
 	Label int
 	Data  []mat.Matrix
+	// Bytes contains additional raw data, such as the codes of quantized
+	// values (can be nil).
+	Bytes [][]byte
 }
 
 // NewPayload returns an empty support structure, not connected to any optimization method.
@@ -43,6 +48,17 @@ func (p Payload) MarshalBinary() ([]byte, error) {
 		}
 	}
 
+	// Bytes are optional, to read the payloads encoded without them
+	if len(p.Bytes) > 0 {
+		binary.LittleEndian.PutUint32(binLen, uint32(len(p.Bytes)))
+		buf.Write(binLen)
+		for _, b := range p.Bytes {
+			binary.LittleEndian.PutUint32(binLen, uint32(len(b)))
+			buf.Write(binLen)
+			buf.Write(b)
+		}
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -61,5 +77,24 @@ func (p *Payload) UnmarshalBinary(data []byte) error {
 			return err
 		}
 	}
+
+	p.Bytes = nil
+	if r.Len() == 0 {
+		return nil
+	}
+	binLen := make([]byte, 4)
+	if _, err := io.ReadFull(r, binLen); err != nil {
+		return err
+	}
+	p.Bytes = make([][]byte, binary.LittleEndian.Uint32(binLen))
+	for i := range p.Bytes {
+		if _, err := io.ReadFull(r, binLen); err != nil {
+			return err
+		}
+		p.Bytes[i] = make([]byte, binary.LittleEndian.Uint32(binLen))
+		if _, err := io.ReadFull(r, p.Bytes[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
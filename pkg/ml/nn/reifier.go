@@ -12,6 +12,8 @@ import (
 type reifier struct {
 	g    *ag.Graph
 	mode ProcessingMode
+	// grads, if not nil, collects the gradients of the params (see ReifyWithLocalGrads).
+	grads *LocalGrads
 }
 
 func newReifier(g *ag.Graph, mode ProcessingMode) *reifier {
@@ -108,7 +110,7 @@ func (r *reifier) reifyModel(sourceField Model) Model {
 	if isNil(sourceField) {
 		return sourceField
 	}
-	p := Reify(sourceField, r.g, r.mode)
+	p := r.reify(sourceField)
 	p.InitProcessor()
 	return p
 }
@@ -122,6 +124,9 @@ func (r *reifier) reifyModelSlice(sourceField []Model) []Model {
 }
 
 func (r *reifier) reifyParam(sourceField *param) Param {
+	if r.grads != nil {
+		return r.grads.wrap(r.g, sourceField)
+	}
 	return sourceField.wrappedParam(r.g)
 }
 
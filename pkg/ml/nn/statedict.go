@@ -0,0 +1,395 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+// ForEachNamedParam calls the callback for each parameter of m, including the
+// ones of the nested models, along with its name.
+//
+// The name of a parameter is the path of the model declaring it (see
+// ForEachModel) joined with the (lowercase) name of the field holding it.
+// The items of slices are always followed by their index, so that the name
+// of a param doesn't depend on the length of the slice holding it, and the
+// ones of maps by their key, keeping its case. Maps are visited in the order
+// of their keys: the names and their order only depend on the structure of m.
+// A param shared by multiple fields or models is visited only once, with the
+// first name found in this order.
+func ForEachNamedParam(m Model, callback func(name string, param Param)) {
+	forEachModelNamedParams(m, func(_ string, _ Model, names []string, params []Param) {
+		for i, param := range params {
+			callback(names[i], param)
+		}
+	})
+}
+
+// forEachModelNamedParams calls the callback for m and for each Model nested
+// in it (see ForEachModel), along with the parameters declared by the model
+// and their names (see ForEachNamedParam).
+func forEachModelNamedParams(m Model, callback func(path string, model Model, names []string, params []Param)) {
+	visited := make(map[Param]bool)
+	ForEachModel(m, func(path string, model Model) {
+		var params []Param
+		var names []string
+		namedParamsTraversal{
+			callback: func(name string, param *param) {
+				if visited[param] {
+					return
+				}
+				visited[param] = true
+				params = append(params, param)
+				names = append(names, joinPath(path, name))
+			},
+		}.walk(model, "")
+		callback(path, model, names, params)
+	})
+}
+
+// stateDictMagic identifies the files written by WriteStateDict.
+const stateDictMagic = "spagosd\x01"
+
+// The numeric types of the values in a state dict.
+const (
+	stateDictFloat32 byte = iota + 1
+	stateDictFloat64
+	stateDictFloat16
+	stateDictBFloat16
+)
+
+// StateDictEntry is the value of a parameter read from a state dict.
+type StateDictEntry struct {
+	// Name of the parameter (see ForEachNamedParam).
+	Name string
+	// DType is the numeric type the value was stored with.
+	DType string
+	// Rows and Columns are the shape of the value.
+	Rows, Columns int
+	// Data contains the Rows x Columns values, in row-major order.
+	Data []mat.Float
+}
+
+// StateDictReport describes the differences between a state dict and the
+// parameters of the model it has been loaded into.
+type StateDictReport struct {
+	// Missing are the names of the model parameters not found in the state dict.
+	Missing []string
+	// Unexpected are the names of the state dict entries without a matching parameter.
+	Unexpected []string
+	// Mismatched are the names of the parameters whose shape differs from
+	// the one of the corresponding state dict entry.
+	Mismatched []string
+}
+
+// IsEmpty reports whether the state dict matches the model exactly.
+func (r StateDictReport) IsEmpty() bool {
+	return len(r.Missing) == 0 && len(r.Unexpected) == 0 && len(r.Mismatched) == 0
+}
+
+// String returns a human-readable description of the differences.
+func (r StateDictReport) String() string {
+	var parts []string
+	if len(r.Missing) > 0 {
+		parts = append(parts, "missing: "+strings.Join(r.Missing, ", "))
+	}
+	if len(r.Unexpected) > 0 {
+		parts = append(parts, "unexpected: "+strings.Join(r.Unexpected, ", "))
+	}
+	if len(r.Mismatched) > 0 {
+		parts = append(parts, "shape mismatch: "+strings.Join(r.Mismatched, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SaveStateDict writes the parameters of m to a new file (see WriteStateDict).
+func SaveStateDict(m Model, filename string) (err error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+	w := bufio.NewWriter(f)
+	if err := WriteStateDict(m, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// WriteStateDict writes the parameters of m in a self-describing binary
+// format, which records the name (see ForEachNamedParam), the numeric type
+// and the shape of each value. Unlike the gob serialization of the model, it
+// does not depend on the Go types, so it can be loaded (see LoadStateDict)
+// into any model declaring parameters with the same names.
+//
+// The values are written with the precision they are stored with, i.e.
+// mat.Float, float16 or bfloat16. The parameters without value are skipped.
+func WriteStateDict(m Model, w io.Writer) error {
+	return WriteStateDictFiltered(m, w, nil)
+}
+
+// WriteStateDictFiltered is like WriteStateDict, but writes only the
+// parameters for which filter returns true. If filter is nil, all the
+// parameters are written.
+func WriteStateDictFiltered(m Model, w io.Writer, filter func(name string, param Param) bool) error {
+	type entry struct {
+		name  string
+		param Param
+	}
+	var entries []entry
+	names := make(map[string]bool)
+	var err error
+	ForEachNamedParam(m, func(name string, param Param) {
+		if err == nil && names[name] {
+			err = fmt.Errorf("nn: duplicate param name %q", name)
+		}
+		names[name] = true
+		if rows, cols := param.Dims(); (rows != 0 || cols != 0) && (filter == nil || filter(name, param)) {
+			entries = append(entries, entry{name: name, param: param})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, stateDictMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(entries))); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := writeStateDictEntry(w, e.name, e.param); err != nil {
+			return fmt.Errorf("nn: error writing param %q: %w", e.name, err)
+		}
+	}
+	return nil
+}
+
+func writeStateDictEntry(w io.Writer, name string, param Param) (err error) {
+	param.ReadValue(func(value mat.Matrix) {
+		err = writeStateDictValue(w, name, param.DType(), value)
+	})
+	return err
+}
+
+func writeStateDictValue(w io.Writer, name string, paramDType mat.DType, value mat.Matrix) error {
+	rows, cols := value.Dims()
+	dtype := floatDType()
+	switch paramDType {
+	case mat.DTypeFloat16:
+		dtype = stateDictFloat16
+	case mat.DTypeBFloat16:
+		dtype = stateDictBFloat16
+	}
+
+	header := make([]byte, 4+len(name)+1+8)
+	binary.LittleEndian.PutUint32(header, uint32(len(name)))
+	copy(header[4:], name)
+	header[4+len(name)] = dtype
+	binary.LittleEndian.PutUint32(header[5+len(name):], uint32(rows))
+	binary.LittleEndian.PutUint32(header[9+len(name):], uint32(cols))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	data := value.Data()
+	var buf []byte
+	switch dtype {
+	case stateDictFloat32:
+		buf = make([]byte, len(data)*4)
+		for i, v := range data {
+			binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(v)))
+		}
+	case stateDictFloat64:
+		buf = make([]byte, len(data)*8)
+		for i, v := range data {
+			binary.LittleEndian.PutUint64(buf[i*8:], math.Float64bits(float64(v)))
+		}
+	case stateDictFloat16:
+		buf = make([]byte, len(data)*2)
+		for i, v := range data {
+			binary.LittleEndian.PutUint16(buf[i*2:], mat.Float16bits(v))
+		}
+	case stateDictBFloat16:
+		buf = make([]byte, len(data)*2)
+		for i, v := range data {
+			binary.LittleEndian.PutUint16(buf[i*2:], mat.BFloat16bits(v))
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadStateDict reads all the entries written by WriteStateDict.
+func ReadStateDict(r io.Reader) ([]StateDictEntry, error) {
+	magic := make([]byte, len(stateDictMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != stateDictMagic {
+		return nil, errors.New("nn: invalid state dict")
+	}
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	entries := make([]StateDictEntry, n)
+	for i := range entries {
+		if err := readStateDictEntry(r, &entries[i]); err != nil {
+			return nil, fmt.Errorf("nn: error reading state dict entry %d: %w", i, err)
+		}
+	}
+	return entries, nil
+}
+
+func readStateDictEntry(r io.Reader, e *StateDictEntry) error {
+	var nameLen uint32
+	if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+		return err
+	}
+	header := make([]byte, int(nameLen)+1+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	e.Name = string(header[:nameLen])
+	dtype := header[nameLen]
+	e.Rows = int(binary.LittleEndian.Uint32(header[nameLen+1:]))
+	e.Columns = int(binary.LittleEndian.Uint32(header[nameLen+5:]))
+	e.Data = make([]mat.Float, e.Rows*e.Columns)
+
+	var size int
+	switch dtype {
+	case stateDictFloat32:
+		e.DType, size = "float32", 4
+	case stateDictFloat64:
+		e.DType, size = "float64", 8
+	case stateDictFloat16:
+		e.DType, size = mat.DTypeFloat16.String(), 2
+	case stateDictBFloat16:
+		e.DType, size = mat.DTypeBFloat16.String(), 2
+	default:
+		return fmt.Errorf("unknown dtype %d", dtype)
+	}
+	buf := make([]byte, len(e.Data)*size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	for i := range e.Data {
+		switch dtype {
+		case stateDictFloat32:
+			e.Data[i] = mat.Float(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:])))
+		case stateDictFloat64:
+			e.Data[i] = mat.Float(math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:])))
+		case stateDictFloat16:
+			e.Data[i] = mat.Float16frombits(binary.LittleEndian.Uint16(buf[i*2:]))
+		case stateDictBFloat16:
+			e.Data[i] = mat.BFloat16frombits(binary.LittleEndian.Uint16(buf[i*2:]))
+		}
+	}
+	return nil
+}
+
+// LoadStateDict reads a file written by SaveStateDict, and replaces the
+// values of the parameters of m with the ones of the same name.
+// The values are converted to the numeric type of the destination params.
+//
+// The returned report lists the missing, unexpected and shape-mismatched
+// parameters. If strict is true and the report is not empty, an error is
+// returned without modifying the model. Otherwise, all the parameters found
+// with the expected shape are loaded, and the others are left untouched.
+// The parameters without value accept any shape.
+func LoadStateDict(m Model, filename string, strict bool) (StateDictReport, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return StateDictReport{}, err
+	}
+	defer f.Close()
+	entries, err := ReadStateDict(bufio.NewReader(f))
+	if err != nil {
+		return StateDictReport{}, err
+	}
+	return LoadStateDictEntries(m, entries, strict)
+}
+
+// LoadStateDictEntries is like LoadStateDict, but loads the given entries.
+// It returns an error, without modifying the model, if two entries or two
+// params of m have the same name.
+func LoadStateDictEntries(m Model, entries []StateDictEntry, strict bool) (StateDictReport, error) {
+	byName := make(map[string]*StateDictEntry, len(entries))
+	for i := range entries {
+		if _, ok := byName[entries[i].Name]; ok {
+			return StateDictReport{}, fmt.Errorf("nn: duplicate state dict entry %q", entries[i].Name)
+		}
+		byName[entries[i].Name] = &entries[i]
+	}
+
+	type match struct {
+		param Param
+		entry *StateDictEntry
+	}
+	var matches []match
+	var report StateDictReport
+	names := make(map[string]bool)
+	var err error
+	ForEachNamedParam(m, func(name string, param Param) {
+		if err == nil && names[name] {
+			err = fmt.Errorf("nn: duplicate param name %q", name)
+		}
+		names[name] = true
+		e, ok := byName[name]
+		if !ok {
+			report.Missing = append(report.Missing, name)
+			return
+		}
+		delete(byName, name)
+		if rows, cols := param.Dims(); rows != 0 || cols != 0 {
+			if rows != e.Rows || cols != e.Columns {
+				report.Mismatched = append(report.Mismatched,
+					fmt.Sprintf("%s (%dx%d, found %dx%d)", name, rows, cols, e.Rows, e.Columns))
+				return
+			}
+		}
+		matches = append(matches, match{param: param, entry: e})
+	})
+	if err != nil {
+		return StateDictReport{}, err
+	}
+	for name := range byName {
+		report.Unexpected = append(report.Unexpected, name)
+	}
+	sort.Strings(report.Unexpected)
+
+	if strict && !report.IsEmpty() {
+		return report, fmt.Errorf("nn: state dict does not match the model: %s", report)
+	}
+	for _, mt := range matches {
+		mt.param.ReplaceValue(mat.NewDense(mt.entry.Rows, mt.entry.Columns, mt.entry.Data))
+	}
+	return report, nil
+}
+
+// floatDType returns the state dict numeric type corresponding to mat.Float.
+func floatDType() byte {
+	var f interface{} = mat.Float(0)
+	if _, ok := f.(float64); ok {
+		return stateDictFloat64
+	}
+	return stateDictFloat32
+}
@@ -0,0 +1,243 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type StateDictLeaf struct {
+	BaseModel
+	W Param `spago:"type:weights"`
+	B Param `spago:"type:biases"`
+}
+
+type StateDictRoot struct {
+	BaseModel
+	Leaf   *StateDictLeaf
+	Layers []Model
+	Ws     []Param `spago:"type:weights"`
+	Shared Param   `spago:"type:weights"`
+}
+
+func newStateDictTestModel(offset mat.Float) *StateDictRoot {
+	value := func(rows, cols int) mat.Matrix {
+		m := mat.NewEmptyDense(rows, cols)
+		for i := range m.Data() {
+			m.Data()[i] = mat.Float(i)/4 + offset
+		}
+		return m
+	}
+	leaf := func() *StateDictLeaf {
+		return &StateDictLeaf{W: NewParam(value(2, 3)), B: NewParam(value(2, 1))}
+	}
+	m := &StateDictRoot{
+		Leaf:   leaf(),
+		Layers: []Model{leaf(), leaf()},
+		Ws:     []Param{NewParam(value(1, 2)), NewParam(value(1, 3))},
+	}
+	m.Shared = m.Leaf.W
+	return m
+}
+
+func TestForEachNamedParam(t *testing.T) {
+	m := newStateDictTestModel(0)
+	var names []string
+	var params []Param
+	ForEachNamedParam(m, func(name string, param Param) {
+		names = append(names, name)
+		params = append(params, param)
+	})
+	assert.Equal(t, []string{
+		"ws.0", "ws.1", "shared", // leaf.w is the shared param, already visited
+		"leaf.b",
+		"layers.0.w", "layers.0.b",
+		"layers.1.w", "layers.1.b",
+	}, names)
+	assert.Same(t, m.Ws[1], params[1])
+
+	t.Run("slice items are always indexed", func(t *testing.T) {
+		m := newStateDictTestModel(0)
+		m.Ws = m.Ws[:1]
+		assert.Equal(t, "ws.0", namedParams(m)[0])
+	})
+
+	t.Run("names don't depend on previous traversals", func(t *testing.T) {
+		m := newStateDictTestModel(0)
+		ForEachParam(m.Layers[0], func(Param) {}) // assigns Param.Name
+		expected := namedParams(newStateDictTestModel(0))
+		assert.Equal(t, expected, namedParams(m))
+	})
+
+	t.Run("map items are visited in the order of their keys", func(t *testing.T) {
+		type mapModel struct {
+			BaseModel
+			Params map[string]Param `spago:"type:weights"`
+			Leaves map[int]Model
+		}
+		m := &mapModel{Params: make(map[string]Param), Leaves: make(map[int]Model)}
+		for _, key := range []string{"c", "a", "b"} {
+			m.Params[key] = NewParam(mat.NewEmptyVecDense(1))
+		}
+		for _, key := range []int{10, 2} {
+			m.Leaves[key] = &StateDictLeaf{W: NewParam(mat.NewEmptyVecDense(1))}
+		}
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, []string{"params.a", "params.b", "params.c", "leaves.2.w", "leaves.10.w"}, namedParams(m))
+		}
+	})
+
+	t.Run("map keys keep their case", func(t *testing.T) {
+		type mapModel struct {
+			BaseModel
+			Params map[string]Param `spago:"type:weights"`
+		}
+		m := &mapModel{Params: map[string]Param{
+			"Foo": NewParam(mat.NewScalar(1)),
+			"foo": NewParam(mat.NewScalar(2)),
+		}}
+		assert.Equal(t, []string{"params.Foo", "params.foo"}, namedParams(m))
+		assert.Equal(t, "params.foo", m.Params["Foo"].Name(), "Param.Name is still lowercase")
+
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteStateDict(m, buf))
+		entries, err := ReadStateDict(buf)
+		require.NoError(t, err)
+		dst := &mapModel{Params: map[string]Param{"Foo": NewParam(nil), "foo": NewParam(nil)}}
+		_, err = LoadStateDictEntries(dst, entries, true)
+		require.NoError(t, err)
+		assert.Equal(t, mat.Float(1), dst.Params["Foo"].ScalarValue())
+		assert.Equal(t, mat.Float(2), dst.Params["foo"].ScalarValue())
+	})
+}
+
+func TestStateDict_DuplicateNames(t *testing.T) {
+	type duplicateModel struct {
+		BaseModel
+		WX Param `spago:"type:weights"`
+		Wx Param `spago:"type:weights"`
+	}
+	m := &duplicateModel{WX: NewParam(mat.NewScalar(1)), Wx: NewParam(mat.NewScalar(2))}
+	assert.Error(t, WriteStateDict(m, new(bytes.Buffer)))
+	entries := []StateDictEntry{{Name: "wx", Rows: 1, Columns: 1, Data: []mat.Float{3}}}
+	_, err := LoadStateDictEntries(m, entries, false)
+	assert.Error(t, err)
+	assert.Equal(t, mat.Float(1), m.WX.ScalarValue())
+	assert.Equal(t, mat.Float(2), m.Wx.ScalarValue())
+
+	dst := newStateDictTestModel(0)
+	entries = []StateDictEntry{
+		{Name: "shared", Rows: 2, Columns: 3, Data: make([]mat.Float, 6)},
+		{Name: "shared", Rows: 2, Columns: 3, Data: make([]mat.Float, 6)},
+	}
+	_, err = LoadStateDictEntries(dst, entries, false)
+	assert.Error(t, err)
+}
+
+func namedParams(m Model) []string {
+	var names []string
+	ForEachNamedParam(m, func(name string, _ Param) {
+		names = append(names, name)
+	})
+	return names
+}
+
+func TestStateDict_RoundTrip(t *testing.T) {
+	src := newStateDictTestModel(1)
+	src.Layers[1].(*StateDictLeaf).W.SetDType(mat.DTypeFloat16)
+	filename := filepath.Join(t.TempDir(), "model.sd")
+	require.NoError(t, SaveStateDict(src, filename))
+
+	dst := newStateDictTestModel(0)
+	report, err := LoadStateDict(dst, filename, true)
+	require.NoError(t, err)
+	assert.True(t, report.IsEmpty())
+
+	ForEachNamedParam(src, func(name string, param Param) {
+		found := false
+		ForEachNamedParam(dst, func(dstName string, dstParam Param) {
+			if dstName == name {
+				found = true
+				assert.Equal(t, param.Value().Data(), dstParam.Value().Data(), name)
+			}
+		})
+		assert.True(t, found, name)
+	})
+	assert.Equal(t, mat.DTypeFloat, dst.Layers[1].(*StateDictLeaf).W.DType())
+}
+
+func TestStateDict_Entries(t *testing.T) {
+	src := newStateDictTestModel(1)
+	src.Leaf.B.SetDType(mat.DTypeBFloat16)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteStateDict(src, buf))
+
+	entries, err := ReadStateDict(buf)
+	require.NoError(t, err)
+	require.Len(t, entries, 8)
+	assert.Equal(t, "leaf.b", entries[3].Name)
+	assert.Equal(t, "bfloat16", entries[3].DType)
+	assert.Equal(t, 2, entries[3].Rows)
+	assert.Equal(t, 1, entries[3].Columns)
+	assert.Equal(t, []mat.Float{1, 1.25}, entries[3].Data)
+
+	_, err = ReadStateDict(bytes.NewReader([]byte("not a state dict")))
+	assert.Error(t, err)
+}
+
+func TestWriteStateDictFiltered(t *testing.T) {
+	src := newStateDictTestModel(1)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteStateDictFiltered(src, buf, func(name string, param Param) bool {
+		return param == src.Shared || name == "layers.1.b"
+	}))
+
+	entries, err := ReadStateDict(buf)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "shared", entries[0].Name)
+	assert.Equal(t, "layers.1.b", entries[1].Name)
+}
+
+func TestLoadStateDictEntries(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteStateDict(newStateDictTestModel(1), buf))
+	entries, err := ReadStateDict(buf)
+	require.NoError(t, err)
+	entries = append(entries, StateDictEntry{Name: "foo", Rows: 1, Columns: 1, Data: []mat.Float{42}})
+
+	newDst := func() *StateDictRoot {
+		m := newStateDictTestModel(0)
+		m.Layers = append(m.Layers, &StateDictLeaf{W: NewParam(mat.NewEmptyDense(2, 3)), B: NewParam(nil)})
+		m.Leaf.B = NewParam(mat.NewEmptyVecDense(3))
+		return m
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		dst := newDst()
+		report, err := LoadStateDictEntries(dst, entries, true)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"layers.2.w", "layers.2.b"}, report.Missing)
+		assert.Equal(t, []string{"foo"}, report.Unexpected)
+		assert.Equal(t, []string{"leaf.b (3x1, found 2x1)"}, report.Mismatched)
+		assert.Equal(t, mat.Float(0), dst.Shared.Value().Data()[0], "the model must be untouched")
+	})
+
+	t.Run("non-strict", func(t *testing.T) {
+		dst := newDst()
+		report, err := LoadStateDictEntries(dst, entries, false)
+		assert.NoError(t, err)
+		assert.False(t, report.IsEmpty())
+		assert.Equal(t, []mat.Float{1, 1.25, 1.5, 1.75, 2, 2.25}, dst.Shared.Value().Data())
+		assert.Equal(t, []mat.Float{0, 0, 0}, dst.Leaf.B.Value().Data())
+		assert.Nil(t, dst.Layers[2].(*StateDictLeaf).B.Value())
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"unsafe"
+
+	"github.com/dustin/go-humanize"
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+// ModelSummary describes a model, its parameters and its sub-models.
+type ModelSummary struct {
+	// Path of the model (see ForEachModel).
+	Path string `json:"path"`
+	// Type is the Go type of the model.
+	Type string `json:"type"`
+	// Params are the parameters declared by the model itself.
+	Params []ParamSummary `json:"params,omitempty"`
+	// Models are the nested sub-models.
+	Models []*ModelSummary `json:"models,omitempty"`
+	// Totals of the model, including its sub-models.
+	Totals SummaryTotals `json:"totals"`
+}
+
+// ParamSummary describes a parameter.
+type ParamSummary struct {
+	// Path of the param (see ForEachNamedParam).
+	Path    string `json:"path"`
+	Rows    int    `json:"rows"`
+	Columns int    `json:"columns"`
+	// Type is the ParamsType of the param (e.g. "weights").
+	Type string `json:"type"`
+	// DType is the numeric type used to store the value.
+	DType     string `json:"dtype"`
+	Trainable bool   `json:"trainable"`
+	// Bytes is the size of the value in memory.
+	Bytes int `json:"bytes"`
+}
+
+// SummaryTotals are the aggregated sizes of a set of parameters.
+type SummaryTotals struct {
+	// Params is the number of scalar values of all the parameters.
+	Params int `json:"params"`
+	// TrainableParams is the number of scalar values of the trainable parameters.
+	TrainableParams int `json:"trainable_params"`
+	// Bytes is the size of all the values in memory.
+	Bytes int `json:"bytes"`
+}
+
+func (t *SummaryTotals) add(o SummaryTotals) {
+	t.Params += o.Params
+	t.TrainableParams += o.TrainableParams
+	t.Bytes += o.Bytes
+}
+
+// Summary walks the model, returning the tree of its sub-models with their
+// parameters (like ForEachModel and ForEachNamedParam), and the totals of
+// each sub-tree. The parameters without value (e.g. the weights of a
+// quantized layer) have zero size.
+//
+// The result can be printed with String, or marshaled to JSON.
+func Summary(m Model) *ModelSummary {
+	var root *ModelSummary
+	var stack []*ModelSummary
+	forEachModelNamedParams(m, func(path string, model Model, names []string, params []Param) {
+		node := &ModelSummary{
+			Path:   path,
+			Type:   reflect.TypeOf(model).String(),
+			Params: make([]ParamSummary, len(params)),
+		}
+		for i, param := range params {
+			node.Params[i] = summarizeParam(names[i], param)
+			node.Totals.add(node.Params[i].totals())
+		}
+		for len(stack) > 0 && !isSubPath(stack[len(stack)-1].Path, path) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			root = node
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Models = append(parent.Models, node)
+		}
+		stack = append(stack, node)
+	})
+	root.computeTotals()
+	return root
+}
+
+// isSubPath reports whether path is equal to, or nested in, parent.
+// The root path is the empty string.
+func isSubPath(parent, path string) bool {
+	return parent == "" || path == parent || strings.HasPrefix(path, parent+".")
+}
+
+func (s *ModelSummary) computeTotals() {
+	for _, sub := range s.Models {
+		sub.computeTotals()
+		s.Totals.add(sub.Totals)
+	}
+}
+
+func summarizeParam(name string, param Param) ParamSummary {
+	ps := ParamSummary{
+		Path:      name,
+		Type:      param.Type().String(),
+		DType:     param.DType().String(),
+		Trainable: param.RequiresGrad(),
+	}
+	ps.Rows, ps.Columns = param.Dims() // (0, 0) without a value
+	if param.DType().IsHalf() {
+		ps.Bytes = ps.Rows * ps.Columns * 2
+	} else {
+		ps.Bytes = ps.Rows * ps.Columns * int(unsafe.Sizeof(mat.Float(0)))
+	}
+	return ps
+}
+
+func (p ParamSummary) totals() SummaryTotals {
+	t := SummaryTotals{Params: p.Rows * p.Columns, Bytes: p.Bytes}
+	if p.Trainable {
+		t.TrainableParams = t.Params
+	}
+	return t
+}
+
+// String returns the summary as an indented table.
+func (s *ModelSummary) String() string {
+	var b strings.Builder
+	_ = s.Write(&b)
+	return b.String()
+}
+
+// Write writes the summary to w as an indented table, one line for each
+// model and each param, followed by the totals.
+func (s *ModelSummary) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSHAPE\tTYPE\tDTYPE\tTRAINABLE\tPARAMS\tSIZE")
+	s.writeRows(tw, 0)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nTotal params: %d\nTrainable params: %d\nNon-trainable params: %d\nTotal size: %s\n",
+		s.Totals.Params, s.Totals.TrainableParams, s.Totals.Params-s.Totals.TrainableParams,
+		humanize.IBytes(uint64(s.Totals.Bytes)))
+	return err
+}
+
+func (s *ModelSummary) writeRows(w io.Writer, depth int) {
+	indent := strings.Repeat("  ", depth)
+	path := s.Path
+	if path == "" {
+		path = "(root)"
+	}
+	fmt.Fprintf(w, "%s%s\t%s\t\t\t\t%d\t%s\n", indent, path, s.Type, s.Totals.Params,
+		humanize.IBytes(uint64(s.Totals.Bytes)))
+	for _, p := range s.Params {
+		trainable := "no"
+		if p.Trainable {
+			trainable = "yes"
+		}
+		fmt.Fprintf(w, "%s  %s\t%dx%d\t%s\t%s\t%s\t%d\t%s\n", indent, p.Path, p.Rows, p.Columns,
+			p.Type, p.DType, trainable, p.Rows*p.Columns, humanize.IBytes(uint64(p.Bytes)))
+	}
+	for _, sub := range s.Models {
+		sub.writeRows(w, depth+1)
+	}
+}
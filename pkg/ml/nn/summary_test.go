@@ -0,0 +1,58 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"encoding/json"
+	"testing"
+	"unsafe"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	m := newStateDictTestModel(0)
+	m.Layers[1].(*StateDictLeaf).W.SetRequiresGrad(false)
+	m.Layers[1].(*StateDictLeaf).B.SetDType(mat.DTypeFloat16)
+	floatSize := int(unsafe.Sizeof(mat.Float(0)))
+
+	s := Summary(m)
+	assert.Equal(t, "", s.Path)
+	assert.Equal(t, "*nn.StateDictRoot", s.Type)
+	require.Len(t, s.Params, 3)
+	assert.Equal(t, ParamSummary{
+		Path: "ws.1", Rows: 1, Columns: 3, Type: "weights", DType: mat.DTypeFloat.String(), Trainable: true, Bytes: 3 * floatSize,
+	}, s.Params[1])
+	assert.Equal(t, "shared", s.Params[2].Path)
+
+	require.Len(t, s.Models, 3)
+	assert.Equal(t, "leaf", s.Models[0].Path)
+	assert.Len(t, s.Models[0].Params, 1) // leaf.w is the shared param, already visited
+	assert.Equal(t, "layers.1", s.Models[2].Path)
+	assert.Equal(t, SummaryTotals{Params: 8, TrainableParams: 2, Bytes: 6*floatSize + 2*2}, s.Models[2].Totals)
+
+	// ws (2+3), shared (6), leaf.b (2), layers (8+8)
+	assert.Equal(t, SummaryTotals{Params: 29, TrainableParams: 23, Bytes: 27*floatSize + 2*2}, s.Totals)
+
+	out := s.String()
+	assert.Contains(t, out, "Total params: 29\n")
+	assert.Contains(t, out, "Non-trainable params: 6\n")
+	assert.Contains(t, out, "    layers.1.b")
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	var decoded ModelSummary
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, s, &decoded)
+}
+
+func TestSummary_NilValue(t *testing.T) {
+	m := &StateDictLeaf{W: NewParam(nil), B: NewParam(mat.NewEmptyVecDense(4))}
+	s := Summary(m)
+	assert.Equal(t, 0, s.Params[0].Bytes)
+	assert.Equal(t, 4, s.Totals.Params)
+}
@@ -0,0 +1,373 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmaes
+
+import (
+	"math"
+	"sort"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/mat/rand/normal"
+)
+
+// CMAES implements the Covariance Matrix Adaptation Evolution Strategy, a
+// derivative-free method for the optimization of non-linear, non-convex
+// functions over continuous spaces. At each generation, a population is
+// sampled from a multivariate normal distribution, whose mean, step size and
+// covariance matrix are adapted to the best members. Learning the covariance
+// matrix makes it invariant to the rotations and the scaling of the search
+// space, so that it converges quickly on ill-conditioned problems.
+//
+// To optimize the params of a model (neuroevolution), use the vector of
+// nn.DumpParamsVector as the initial mean, and load the solutions to
+// evaluate with nn.LoadParamsVector.
+//
+// Reference: "The CMA Evolution Strategy: A Tutorial" (Hansen, 2016)
+// https://arxiv.org/abs/1604.00772
+type CMAES struct {
+	// The initial configuration
+	Config
+	// The population of the current generation, sorted by score after the evaluation
+	population []*ScoredVector
+	// The fitness function to minimize
+	fitnessFunc func(solution mat.Matrix) mat.Float
+	// Method to call after finding a new best solution (can be nil)
+	onNewBest func(solution *ScoredVector)
+	// The current best solution (can be nil)
+	bestSolution *ScoredVector
+	// Optimization state
+	state  *State
+	rndGen *rand.LockedRand
+	params strategyParams
+	// The mean of the distribution
+	mean mat.Matrix
+	// The evolution paths of the covariance matrix and of the step size
+	pc, ps mat.Matrix
+	// The covariance matrix (its diagonal, if Diagonal is true)
+	cov mat.Matrix
+	// The eigenvectors (nil if Diagonal is true) and the square roots of
+	// the eigenvalues of the covariance matrix
+	b, d mat.Matrix
+	// The generation of the last eigendecomposition
+	eigenGeneration int
+}
+
+// State represents a status of the optimization process.
+type State struct {
+	// The current generation
+	CurGeneration int
+	// The current step size
+	Sigma mat.Float
+	// The number of evaluations of the fitness function
+	Evaluations int
+}
+
+// Config provides configuration settings for a CMAES optimizer.
+type Config struct {
+	// The number of members of the population. If 0, the default 4+3*ln(VectorSize) is used.
+	PopulationSize int
+	// The size of the dense vector
+	VectorSize int
+	// The maximum number of generations
+	MaxGenerations int
+	// The initial mean (e.g. the params of a model). If nil, the zero vector is used.
+	InitialMean mat.Matrix
+	// The initial step size (e.g. a third of the width of the search space)
+	Sigma mat.Float
+	// Whether to adapt only the diagonal of the covariance matrix (separable
+	// CMA-ES), which takes linear time and space in VectorSize, for large vectors
+	Diagonal bool
+	// Stop when the step size, times the largest standard deviation of the
+	// covariance matrix, is below this value. If 0, run all the generations.
+	Tolerance mat.Float
+	// The random seed
+	Seed uint64
+}
+
+// ScoredVector is a pair which associates a Score to a Vector corresponding to a specific solution.
+type ScoredVector struct {
+	Vector mat.Matrix
+	Score  mat.Float
+}
+
+// strategyParams are the constant parameters of the strategy, derived from
+// the vector size and the population size.
+type strategyParams struct {
+	// The number of members selected to update the distribution
+	mu int
+	// The recombination weights of the selected members
+	weights []mat.Float
+	// The variance effective selection mass
+	muEff mat.Float
+	// The learning rates of the evolution paths
+	cc, cs mat.Float
+	// The learning rates of the rank-one and the rank-mu updates
+	c1, cmu mat.Float
+	// The damping of the step size
+	damps mat.Float
+	// The expected norm of a N(0,I) vector
+	chiN mat.Float
+}
+
+// NewOptimizer returns a new CMAES ready to optimize your problem.
+func NewOptimizer(
+	config Config,
+	fitness func(solution mat.Matrix) mat.Float,
+	onNewBest func(solution *ScoredVector),
+) *CMAES {
+	n := config.VectorSize
+	if n < 1 {
+		panic("cmaes: the vector size must be greater than zero")
+	}
+	if config.Sigma <= 0 {
+		panic("cmaes: the initial step size must be positive")
+	}
+	if config.PopulationSize == 0 {
+		config.PopulationSize = 4 + int(3*math.Log(float64(n)))
+	}
+	if config.PopulationSize < 2 {
+		panic("cmaes: the population size must be at least 2")
+	}
+	mean := mat.NewEmptyVecDense(n)
+	if config.InitialMean != nil {
+		if config.InitialMean.Size() != n {
+			panic("cmaes: the initial mean doesn't match the vector size")
+		}
+		mean.SetData(config.InitialMean.Data())
+	}
+	o := &CMAES{
+		Config:      config,
+		fitnessFunc: fitness,
+		onNewBest:   onNewBest,
+		state:       &State{Sigma: config.Sigma},
+		rndGen:      rand.NewLockedRand(config.Seed),
+		params:      newStrategyParams(n, config.PopulationSize, config.Diagonal),
+		mean:        mean,
+		pc:          mat.NewEmptyVecDense(n),
+		ps:          mat.NewEmptyVecDense(n),
+		d:           mat.NewInitVecDense(n, 1),
+	}
+	if config.Diagonal {
+		o.cov = mat.NewInitVecDense(n, 1)
+	} else {
+		o.cov = mat.I(n)
+		o.b = mat.I(n)
+	}
+	return o
+}
+
+func newStrategyParams(n, lambda int, diagonal bool) strategyParams {
+	p := strategyParams{mu: lambda / 2}
+	p.weights = make([]mat.Float, p.mu)
+	sum, sumSquares := mat.Float(0), mat.Float(0)
+	for i := range p.weights {
+		p.weights[i] = mat.Log(mat.Float(lambda+1)/2) - mat.Log(mat.Float(i+1))
+		sum += p.weights[i]
+	}
+	for i := range p.weights {
+		p.weights[i] /= sum
+		sumSquares += p.weights[i] * p.weights[i]
+	}
+	p.muEff = 1 / sumSquares
+
+	nf := mat.Float(n)
+	p.cc = (4 + p.muEff/nf) / (nf + 4 + 2*p.muEff/nf)
+	p.cs = (p.muEff + 2) / (nf + p.muEff + 5)
+	p.c1 = 2 / ((nf+1.3)*(nf+1.3) + p.muEff)
+	p.cmu = 2 * (p.muEff - 2 + 1/p.muEff) / ((nf+2)*(nf+2) + p.muEff)
+	if diagonal {
+		// the diagonal has fewer degrees of freedom, so it can be learned faster
+		p.c1 *= (nf + 2) / 3
+		p.cmu *= (nf + 2) / 3
+	}
+	if p.c1 > 1 {
+		p.c1 = 1
+	}
+	if p.cmu > 1-p.c1 {
+		p.cmu = 1 - p.c1
+	}
+	p.damps = 1 + 2*mat.Max(0, mat.Sqrt((p.muEff-1)/(nf+1))-1) + p.cs
+	p.chiN = mat.Sqrt(nf) * (1 - 1/(4*nf) + 1/(21*nf*nf))
+	return p
+}
+
+// Optimize performs the optimization process, until the last generation or
+// the convergence (see Config.Tolerance).
+func (o *CMAES) Optimize() {
+	for g := 0; g < o.MaxGenerations; g++ {
+		o.Step()
+		if o.Converged() {
+			break
+		}
+	}
+}
+
+// Step samples, evaluates and selects a new generation, and adapts the distribution.
+func (o *CMAES) Step() {
+	steps := o.samplePopulation()
+	o.evaluatePopulation(steps)
+	o.checkForBetterSolution()
+	o.adapt(steps)
+	o.state.CurGeneration++
+}
+
+// Converged reports whether the step size has fallen below the tolerance.
+func (o *CMAES) Converged() bool {
+	return o.Tolerance > 0 && o.state.Sigma*o.d.Max() < o.Tolerance
+}
+
+// Best returns the best solution found so far, or nil.
+func (o *CMAES) Best() *ScoredVector {
+	return o.bestSolution
+}
+
+// Population returns the members of the current generation, sorted by score.
+func (o *CMAES) Population() []*ScoredVector {
+	return o.population
+}
+
+// Mean returns the mean of the current distribution.
+func (o *CMAES) Mean() mat.Matrix {
+	return o.mean
+}
+
+// State returns the state of the optimization process.
+func (o *CMAES) State() *State {
+	return o.state
+}
+
+// samplePopulation samples the members of a new generation, returning their
+// steps from the mean, before the scaling by sigma.
+func (o *CMAES) samplePopulation() []mat.Matrix {
+	if !o.Diagonal {
+		o.updateEigen()
+	}
+	dist := normal.New(1, 0, o.rndGen)
+	o.population = make([]*ScoredVector, o.PopulationSize)
+	steps := make([]mat.Matrix, o.PopulationSize)
+	for i := range steps {
+		z := mat.NewEmptyVecDense(o.VectorSize)
+		for j := 0; j < o.VectorSize; j++ {
+			z.SetVec(j, dist.Next())
+		}
+		y := z.ProdInPlace(o.d) // D z
+		if !o.Diagonal {
+			y = o.b.Mul(y) // B D z
+		}
+		steps[i] = y
+		o.population[i] = &ScoredVector{Vector: o.mean.Add(y.ProdScalar(o.state.Sigma))}
+	}
+	return steps
+}
+
+// evaluatePopulation scores the members of the population and sorts them
+// (and their steps) from the best to the worst.
+func (o *CMAES) evaluatePopulation(steps []mat.Matrix) {
+	for _, member := range o.population {
+		member.Score = o.fitnessFunc(member.Vector)
+		if math.IsNaN(float64(member.Score)) {
+			member.Score = mat.Inf(1)
+		}
+		o.state.Evaluations++
+	}
+	sort.Sort(byScore{population: o.population, steps: steps})
+}
+
+// checkForBetterSolution compares the overall best solution with the best of the population, updating it if better.
+func (o *CMAES) checkForBetterSolution() {
+	best := o.population[0]
+	if o.bestSolution == nil || best.Score < o.bestSolution.Score {
+		o.bestSolution = &ScoredVector{
+			Vector: best.Vector.Clone(),
+			Score:  best.Score,
+		}
+		if o.onNewBest != nil {
+			o.onNewBest(o.bestSolution)
+		}
+	}
+}
+
+// adapt updates the mean, the evolution paths, the covariance matrix and the
+// step size, from the steps of the selected members.
+func (o *CMAES) adapt(steps []mat.Matrix) {
+	p := o.params
+	n := mat.Float(o.VectorSize)
+
+	// the weighted mean of the selected steps
+	yw := mat.NewEmptyVecDense(o.VectorSize)
+	for i, w := range p.weights {
+		yw.AddInPlace(steps[i].ProdScalar(w))
+	}
+	o.mean.AddInPlace(yw.ProdScalar(o.state.Sigma))
+
+	// the step size path uses C^(-1/2) yw = B D^(-1) B^T yw
+	invSqrtYw := yw.Clone()
+	if !o.Diagonal {
+		invSqrtYw = o.b.T().Mul(invSqrtYw)
+	}
+	invSqrtYw.DivInPlace(o.d)
+	if !o.Diagonal {
+		invSqrtYw = o.b.Mul(invSqrtYw)
+	}
+	o.ps.ProdScalarInPlace(1 - p.cs).AddInPlace(invSqrtYw.ProdScalarInPlace(mat.Sqrt(p.cs * (2 - p.cs) * p.muEff)))
+	psNorm := o.ps.Norm(2)
+
+	// the stall of the covariance path when the step size is increasing quickly
+	g := mat.Float(o.state.CurGeneration + 1)
+	hsig := psNorm/mat.Sqrt(1-mat.Pow(1-p.cs, 2*g))/p.chiN < 1.4+2/(n+1)
+	o.pc.ProdScalarInPlace(1 - p.cc)
+	deltaH := p.cc * (2 - p.cc)
+	if hsig {
+		o.pc.AddInPlace(yw.ProdScalar(mat.Sqrt(p.cc * (2 - p.cc) * p.muEff)))
+		deltaH = 0
+	}
+
+	// the rank-one and rank-mu updates of the covariance matrix
+	o.cov.ProdScalarInPlace(1 - p.c1 - p.cmu + p.c1*deltaH)
+	if o.Diagonal {
+		o.cov.AddInPlace(o.pc.Prod(o.pc).ProdScalarInPlace(p.c1))
+		for i, w := range p.weights {
+			o.cov.AddInPlace(steps[i].Prod(steps[i]).ProdScalarInPlace(p.cmu * w))
+		}
+		o.d = o.cov.Sqrt()
+	} else {
+		o.cov.AddInPlace(o.pc.Mul(o.pc.T()).ProdScalarInPlace(p.c1))
+		for i, w := range p.weights {
+			o.cov.AddInPlace(steps[i].Mul(steps[i].T()).ProdScalarInPlace(p.cmu * w))
+		}
+	}
+
+	o.state.Sigma *= mat.Exp((p.cs / p.damps) * (psNorm/p.chiN - 1))
+}
+
+// updateEigen updates the eigendecomposition of the covariance matrix, once
+// in a while to amortize its cost.
+func (o *CMAES) updateEigen() {
+	p := o.params
+	interval := mat.Float(o.PopulationSize) / (p.c1 + p.cmu) / mat.Float(o.VectorSize) / 10
+	if mat.Float(o.state.CurGeneration-o.eigenGeneration) < interval {
+		return
+	}
+	o.eigenGeneration = o.state.CurGeneration
+	values, vectors := eigen(o.cov)
+	o.b = vectors
+	for i, v := range values {
+		o.d.SetVec(i, mat.Float(math.Sqrt(math.Max(v, 1e-20))))
+	}
+}
+
+// byScore sorts the population and the steps of its members by score.
+type byScore struct {
+	population []*ScoredVector
+	steps      []mat.Matrix
+}
+
+func (s byScore) Len() int           { return len(s.population) }
+func (s byScore) Less(i, j int) bool { return s.population[i].Score < s.population[j].Score }
+func (s byScore) Swap(i, j int) {
+	s.population[i], s.population[j] = s.population[j], s.population[i]
+	s.steps[i], s.steps[j] = s.steps[j], s.steps[i]
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmaes
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ellipsoid is an ill-conditioned quadratic function, whose condition number
+// is 1e4, with the minimum in (1, 1, ..., 1).
+func ellipsoid(x mat.Matrix) mat.Float {
+	n := x.Size()
+	sum := mat.Float(0)
+	for i, v := range x.Data() {
+		scale := mat.Pow(1e4, mat.Float(i)/mat.Float(n-1))
+		sum += scale * (v - 1) * (v - 1)
+	}
+	return sum
+}
+
+// rosenbrock is the Rosenbrock function, with the minimum in (1, 1).
+func rosenbrock(x mat.Matrix) mat.Float {
+	a, b := x.AtVec(0), x.AtVec(1)
+	return (1-a)*(1-a) + 100*(b-a*a)*(b-a*a)
+}
+
+func TestCMAES_Ellipsoid(t *testing.T) {
+	for _, diagonal := range []bool{false, true} {
+		var improvements int
+		o := NewOptimizer(Config{
+			VectorSize:     6,
+			MaxGenerations: 1000,
+			Sigma:          1,
+			Diagonal:       diagonal,
+			Tolerance:      1e-5,
+			Seed:           1,
+		}, ellipsoid, func(solution *ScoredVector) {
+			improvements++
+		})
+		o.Optimize()
+		require.NotNil(t, o.Best())
+		assert.Less(t, float64(o.Best().Score), 1e-6, "diagonal: %v", diagonal)
+		assert.InDeltaSlice(t, []mat.Float{1, 1, 1, 1, 1, 1}, o.Best().Vector.Data(), 1e-3)
+		assert.True(t, o.Converged())
+		assert.Less(t, o.State().CurGeneration, 1000)
+		assert.Equal(t, o.State().CurGeneration*o.PopulationSize, o.State().Evaluations)
+		assert.Greater(t, improvements, 1)
+	}
+}
+
+func TestCMAES_Rosenbrock(t *testing.T) {
+	o := NewOptimizer(Config{
+		VectorSize:     2,
+		PopulationSize: 10,
+		MaxGenerations: 500,
+		InitialMean:    mat.NewVecDense([]mat.Float{-1, 2}),
+		Sigma:          0.5,
+		Seed:           2,
+	}, rosenbrock, nil)
+	o.Optimize()
+	assert.Equal(t, 500, o.State().CurGeneration)
+	assert.InDeltaSlice(t, []mat.Float{1, 1}, o.Best().Vector.Data(), 1e-2)
+
+	population := o.Population()
+	require.Len(t, population, 10)
+	for i := 1; i < len(population); i++ {
+		assert.LessOrEqual(t, float64(population[i-1].Score), float64(population[i].Score))
+	}
+}
+
+func TestCMAES_Neuroevolution(t *testing.T) {
+	// y = 2*x1 - 3*x2 + 1
+	xs := [][]mat.Float{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {-1, 2}, {2, -1}}
+	model := linear.New(2, 1)
+	loss := func(solution mat.Matrix) mat.Float {
+		nn.LoadParamsVector(model, solution)
+		sum := mat.Float(0)
+		for _, x := range xs {
+			y := model.W.Value().Mul(mat.NewVecDense(x)).Add(model.B.Value()).Scalar()
+			diff := y - (2*x[0] - 3*x[1] + 1)
+			sum += diff * diff
+		}
+		return sum
+	}
+
+	initial := nn.DumpParamsVector(model)
+	o := NewOptimizer(Config{
+		VectorSize:     initial.Size(),
+		MaxGenerations: 300,
+		InitialMean:    initial,
+		Sigma:          1,
+		Tolerance:      1e-6,
+		Seed:           3,
+	}, loss, nil)
+	o.Optimize()
+
+	nn.LoadParamsVector(model, o.Best().Vector)
+	assert.InDeltaSlice(t, []mat.Float{2, -3}, model.W.Value().Data(), 1e-3)
+	assert.InDeltaSlice(t, []mat.Float{1}, model.B.Value().Data(), 1e-3)
+}
+
+func TestNewOptimizer(t *testing.T) {
+	o := NewOptimizer(Config{VectorSize: 10, Sigma: 1}, ellipsoid, nil)
+	assert.Equal(t, 10, o.PopulationSize)
+	assert.Equal(t, 5, o.params.mu)
+	sum := mat.Float(0)
+	for i, w := range o.params.weights {
+		sum += w
+		if i > 0 {
+			assert.Less(t, float64(w), float64(o.params.weights[i-1]))
+		}
+	}
+	assert.InDelta(t, 1, sum, 1e-5)
+
+	assert.Panics(t, func() { NewOptimizer(Config{VectorSize: 0, Sigma: 1}, ellipsoid, nil) })
+	assert.Panics(t, func() { NewOptimizer(Config{VectorSize: 2, Sigma: 0}, ellipsoid, nil) })
+	assert.Panics(t, func() {
+		NewOptimizer(Config{VectorSize: 2, Sigma: 1, InitialMean: mat.NewEmptyVecDense(3)}, ellipsoid, nil)
+	})
+}
+
+func TestEigen(t *testing.T) {
+	a := mat.NewDense(3, 3, []mat.Float{
+		4, 1, 2,
+		1, 3, 0,
+		2, 0, 5,
+	})
+	values, vectors := eigen(a)
+	for i, value := range values {
+		v := mat.NewEmptyVecDense(3)
+		for j := 0; j < 3; j++ {
+			v.SetVec(j, vectors.At(j, i))
+		}
+		assert.InDelta(t, 1, v.Norm(2), 1e-5)
+		assert.InDeltaSlice(t, v.ProdScalar(mat.Float(value)).Data(), a.Mul(v).Data(), 1e-4)
+	}
+	assert.InDelta(t, 12, values[0]+values[1]+values[2], 1e-4) // the trace
+}
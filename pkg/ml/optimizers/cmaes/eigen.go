@@ -0,0 +1,84 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmaes
+
+import (
+	"math"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+// eigen returns the eigenvalues and the eigenvectors (as columns) of the
+// symmetric matrix a, computed with the cyclic Jacobi method. The
+// computation is done in double precision, regardless of mat.Float.
+func eigen(a mat.Matrix) (values []float64, vectors mat.Matrix) {
+	n := a.Rows()
+	s := make([][]float64, n)
+	v := make([][]float64, n)
+	for i := range s {
+		s[i] = make([]float64, n)
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+		for j := range s[i] {
+			// symmetrize the rounding errors of the updates
+			s[i][j] = (float64(a.At(i, j)) + float64(a.At(j, i))) / 2
+		}
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off, norm := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i != j {
+					off += s[i][j] * s[i][j]
+				}
+				norm += s[i][j] * s[i][j]
+			}
+		}
+		if off <= 1e-30*norm || off == 0 {
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if s[p][q] == 0 {
+					continue
+				}
+				// the rotation which zeroes s[p][q]
+				theta := (s[q][q] - s[p][p]) / (2 * s[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				sn := t * c
+				for k := 0; k < n; k++ {
+					skp, skq := s[k][p], s[k][q]
+					s[k][p] = c*skp - sn*skq
+					s[k][q] = sn*skp + c*skq
+				}
+				for k := 0; k < n; k++ {
+					spk, sqk := s[p][k], s[q][k]
+					s[p][k] = c*spk - sn*sqk
+					s[q][k] = sn*spk + c*sqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - sn*vkq
+					v[k][q] = sn*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	values = make([]float64, n)
+	vectors = mat.NewEmptyDense(n, n)
+	for i := 0; i < n; i++ {
+		values[i] = s[i][i]
+		for j := 0; j < n; j++ {
+			vectors.Set(i, j, mat.Float(v[i][j]))
+		}
+	}
+	return values, vectors
+}
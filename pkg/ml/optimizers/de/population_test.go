@@ -5,7 +5,7 @@
 package de
 
 import (
-	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
 	"testing"
 )
 
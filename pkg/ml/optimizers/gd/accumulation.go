@@ -0,0 +1,144 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// AccumulationConfig configures the accumulation of the gradients across
+// several micro-batches before each update (see WithGradientAccumulation).
+type AccumulationConfig struct {
+	// MicroBatches is the number of micro-batches of each update.
+	MicroBatches int
+	// Average divides the accumulated gradients by the number of
+	// micro-batches, so that the update is the one of a single batch of
+	// MicroBatches times the size.
+	Average bool
+	// LossScale multiplies the loss before the back-propagation (see
+	// GradientDescent.Backward), so that small gradients don't underflow in
+	// low precision. The gradients are unscaled before the clipping and the
+	// update. If 0, the loss is not scaled.
+	LossScale mat.Float
+}
+
+// accumulation is the state of the gradient accumulation.
+type accumulation struct {
+	AccumulationConfig
+	mu    sync.Mutex
+	count int
+}
+
+// WithGradientAccumulation is an option to accumulate the gradients across
+// c.MicroBatches micro-batches before each update, e.g. when a batch of the
+// required size doesn't fit in a single graph.
+//
+// The gradients of each micro-batch are accumulated in the params as usual,
+// by the back-propagation. Each micro-batch must then be counted with
+// AddMicroBatch (or Backward, which does both), and Step applies the update
+// as soon as the micro-batches are complete. The micro-batches can be processed
+// in separate graphs on different goroutines: in that case, wait for all of
+// them to be counted before calling Optimize.
+func WithGradientAccumulation(c AccumulationConfig) Option {
+	if c.MicroBatches < 1 {
+		panic("gd: the number of micro-batches must be greater than zero")
+	}
+	if c.LossScale < 0 {
+		panic("gd: the loss scale must be positive")
+	}
+	return func(o *GradientDescent) {
+		o.accumulation = &accumulation{AccumulationConfig: c}
+	}
+}
+
+// LossScale returns the factor the loss is multiplied by before the
+// back-propagation (1 without loss scaling).
+func (o *GradientDescent) LossScale() mat.Float {
+	if o.accumulation == nil || o.accumulation.LossScale == 0 {
+		return 1
+	}
+	return o.accumulation.LossScale
+}
+
+// Backward performs the back-propagation of the loss of a micro-batch,
+// applying the loss scaling, and counts the micro-batch. It is safe to call
+// it concurrently on different graphs.
+func (o *GradientDescent) Backward(g *ag.Graph, loss ag.Node, opts ...ag.BackwardOption) {
+	if scale := o.LossScale(); scale != 1 {
+		outputGrad := loss.Value().OnesLike()
+		defer mat.ReleaseMatrix(outputGrad)
+		outputGrad.ProdScalarInPlace(scale)
+		opts = append([]ag.BackwardOption{ag.OutputGrad(outputGrad)}, opts...)
+	}
+	g.Backward(loss, opts...)
+	o.AddMicroBatch()
+}
+
+// AddMicroBatch counts a micro-batch, whose gradients have been accumulated
+// in the params. It is safe to call it concurrently.
+func (o *GradientDescent) AddMicroBatch() {
+	if o.accumulation == nil {
+		return
+	}
+	o.accumulation.mu.Lock()
+	defer o.accumulation.mu.Unlock()
+	o.accumulation.count++
+}
+
+// MicroBatches returns the number of micro-batches accumulated since the
+// last update.
+func (o *GradientDescent) MicroBatches() int {
+	if o.accumulation == nil {
+		return 0
+	}
+	o.accumulation.mu.Lock()
+	defer o.accumulation.mu.Unlock()
+	return o.accumulation.count
+}
+
+// Ready reports whether the accumulated micro-batches are complete. Without
+// gradient accumulation, it is always true.
+func (o *GradientDescent) Ready() bool {
+	return o.accumulation == nil || o.MicroBatches() >= o.accumulation.MicroBatches
+}
+
+// Step optimizes the params if the accumulated micro-batches are complete
+// (see Ready), and reports whether it did.
+func (o *GradientDescent) Step() bool {
+	if !o.Ready() {
+		return false
+	}
+	o.Optimize()
+	return true
+}
+
+// unscaleGrads divides the gradients of the observed parameters by the loss
+// scale and, if required, by the number of accumulated micro-batches, and
+// resets the count of the micro-batches.
+func (o *GradientDescent) unscaleGrads() {
+	if o.accumulation == nil {
+		return
+	}
+	o.accumulation.mu.Lock()
+	count := o.accumulation.count
+	o.accumulation.count = 0
+	o.accumulation.mu.Unlock()
+
+	factor := o.LossScale()
+	if o.accumulation.Average && count > 1 {
+		factor *= mat.Float(count)
+	}
+	if factor == 1 {
+		return
+	}
+	for _, param := range o.paramsToOptimize {
+		if param.HasGrad() {
+			param.Grad().ProdScalarInPlace(1 / factor)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithGradientAccumulation(t *testing.T) {
+	xs := [][]mat.Float{{1, 2}, {3, 4}, {-1, 0}, {0, 2}}
+	m := &groupsTestLayer{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{0})),
+	}
+	optimizer := NewOptimizer(&plainSGD{lr: 1}, nn.NewDefaultParamsIterator(m),
+		WithGradientAccumulation(AccumulationConfig{MicroBatches: 4, Average: true, LossScale: 1024}),
+	)
+	assert.Equal(t, mat.Float(1024), optimizer.LossScale())
+
+	// the first three micro-batches run concurrently in separate graphs
+	var wg sync.WaitGroup
+	for _, x := range xs[:3] {
+		wg.Add(1)
+		go func(x []mat.Float) {
+			defer wg.Done()
+			g := ag.NewGraph()
+			loss := g.Dot(g.NewWrap(m.W), g.NewVariable(mat.NewVecDense(x), false))
+			optimizer.Backward(g, loss)
+		}(x)
+	}
+	wg.Wait()
+	assert.Equal(t, 3, optimizer.MicroBatches())
+	assert.Equal(t, []mat.Float{3 * 1024, 6 * 1024}, m.W.Grad().Data())
+	assert.False(t, optimizer.Step())
+	assert.Equal(t, []mat.Float{1, 2}, m.W.Value().Data())
+
+	g := ag.NewGraph()
+	optimizer.Backward(g, g.Dot(g.NewWrap(m.W), g.NewVariable(mat.NewVecDense(xs[3]), false)))
+	assert.True(t, optimizer.Step())
+	// the mean of the gradients is [0.75, 2]
+	assert.InDeltaSlice(t, []mat.Float{0.25, 0}, m.W.Value().Data(), 1e-6)
+	assert.False(t, m.W.HasGrad())
+	assert.Equal(t, 0, optimizer.MicroBatches())
+}
+
+func TestGradientDescent_Step(t *testing.T) {
+	m := &groupsTestLayer{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{0})),
+	}
+	optimizer := NewOptimizer(&plainSGD{lr: 1}, nn.NewDefaultParamsIterator(m))
+	assert.Equal(t, mat.Float(1), optimizer.LossScale())
+	m.W.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1}))
+	assert.True(t, optimizer.Step())
+	assert.Equal(t, []mat.Float{0, 1}, m.W.Value().Data())
+}
+
+func TestWithGradientAccumulation_Panics(t *testing.T) {
+	assert.Panics(t, func() { WithGradientAccumulation(AccumulationConfig{}) })
+	assert.Panics(t, func() { WithGradientAccumulation(AccumulationConfig{MicroBatches: 2, LossScale: -1}) })
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package adafactor
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+var _ gd.MethodConfig = &Config{}
+
+// Config provides configuration settings for an Adafactor optimizer.
+type Config struct {
+	gd.MethodConfig
+	// LR is the external learning rate, used if RelativeStep is false.
+	LR mat.Float
+	// Epsilon1 is added to the squared gradients.
+	Epsilon1 mat.Float
+	// Epsilon2 is the lower bound of the scale of the params (see ScaleParameter).
+	Epsilon2 mat.Float
+	// ClipThreshold is the threshold of the root mean square of the update.
+	ClipThreshold mat.Float
+	// DecayRate is the exponent of the decay of the second moment coefficient.
+	DecayRate mat.Float
+	// Beta1 is the coefficient of the first moment. If 0, the first moment
+	// is not computed (and not stored).
+	Beta1 mat.Float
+	// WeightDecay is the decoupled weight decay rate.
+	WeightDecay mat.Float
+	// ScaleParameter multiplies the learning rate by the root mean square of the param.
+	ScaleParameter bool
+	// RelativeStep computes the learning rate from the time step, instead of using LR.
+	RelativeStep bool
+	// WarmupInit linearly increases the relative step during the first steps.
+	WarmupInit bool
+}
+
+// NewConfig returns a new Adafactor Config, with the given external
+// learning rate.
+func NewConfig(lr, beta1, weightDecay mat.Float) Config {
+	if !(beta1 >= 0.0 && beta1 < 1.0) {
+		panic("adafactor: `beta1` must be in the range [0.0, 1.0)")
+	}
+	c := NewDefaultConfig()
+	c.LR = lr
+	c.Beta1 = beta1
+	c.WeightDecay = weightDecay
+	c.RelativeStep = false
+	return c
+}
+
+// NewDefaultConfig returns a new Config with the default values of the
+// paper, i.e. with relative step sizes and no first moment.
+func NewDefaultConfig() Config {
+	return Config{
+		Epsilon1:       1.0e-30,
+		Epsilon2:       1.0e-3,
+		ClipThreshold:  1.0,
+		DecayRate:      -0.8,
+		ScaleParameter: true,
+		RelativeStep:   true,
+	}
+}
+
+var _ gd.Method = &Adafactor{}
+
+// Adafactor implements the Adafactor gradient descent optimization method.
+// The second moment of the matrices is factored in the moving averages of
+// its rows and columns, so that the memory it requires is sublinear.
+// References:
+//     Adafactor: Adaptive Learning Rates with Sublinear Memory Cost
+//     https://arxiv.org/pdf/1804.04235.pdf
+type Adafactor struct {
+	Config
+	TimeStep int
+}
+
+// New returns a new Adafactor optimizer, initialized according to the given configuration.
+func New(c Config) *Adafactor {
+	return &Adafactor{
+		Config:   c,
+		TimeStep: 1,
+	}
+}
+
+// Label returns the enumeration-like value which identifies this gradient descent method.
+func (o *Adafactor) Label() int {
+	return gd.Adafactor
+}
+
+var _ gd.LearningRateSetter = &Adafactor{}
+
+// LearningRate returns the current learning rate (LR).
+func (o *Adafactor) LearningRate() mat.Float {
+	return o.LR
+}
+
+// SetLearningRate sets a new learning rate (LR). It is used only if
+// RelativeStep is false.
+func (o *Adafactor) SetLearningRate(lr mat.Float) {
+	o.LR = lr
+}
+
+var _ gd.TimeStepper = &Adafactor{}
+
+// CurrentTimeStep returns the current time step.
+func (o *Adafactor) CurrentTimeStep() int {
+	return o.TimeStep
+}
+
+// SetTimeStep sets the current time step.
+func (o *Adafactor) SetTimeStep(t int) {
+	o.TimeStep = t
+}
+
+// IncExample beats the occurrence of a new example.
+func (o *Adafactor) IncExample() {
+	o.TimeStep++
+}
+
+// The indices of the support structure. The matrices (both dimensions
+// greater than 1) store the moving averages of the rows and of the columns of
+// the second moment, the other params store the whole second moment.
+const (
+	vr    int = 0 // rows of the second moment, or the whole second moment
+	vc    int = 1 // columns of the second moment, or an empty matrix
+	delta int = 2
+	m     int = 3 // first moment, only if Beta1 > 0
+)
+
+// NewSupport returns a new support structure with the given dimensions.
+func (o *Adafactor) NewSupport(r, c int) *nn.Payload {
+	supp := make([]mat.Matrix, 3, 4)
+	if isFactored(r, c) {
+		supp[vr] = mat.NewEmptyVecDense(r)
+		supp[vc] = mat.NewEmptyDense(1, c)
+	} else {
+		supp[vr] = mat.NewEmptyDense(r, c)
+		supp[vc] = mat.NewEmptyVecDense(0)
+	}
+	supp[delta] = mat.NewEmptyDense(r, c)
+	if o.Beta1 > 0 {
+		supp = append(supp, mat.NewEmptyDense(r, c))
+	}
+	return &nn.Payload{
+		Label: o.Label(),
+		Data:  supp,
+	}
+}
+
+func isFactored(r, c int) bool {
+	return r > 1 && c > 1
+}
+
+// Delta returns the difference between the current params and where the method wants it to be.
+func (o *Adafactor) Delta(param nn.Param) mat.Matrix {
+	return o.calcDelta(param.Grad(), gd.GetOrSetPayload(param, o).Data, param.Value())
+}
+
+func (o *Adafactor) calcDelta(grads mat.Matrix, supp []mat.Matrix, weights mat.Matrix) mat.Matrix {
+	rows, cols := grads.Dims()
+	g := grads.Data()
+	d := supp[delta].Data()
+	beta2 := 1 - mat.Pow(mat.Float(o.TimeStep), o.DecayRate)
+
+	if isFactored(rows, cols) {
+		// the moving averages of the means of the rows and of the columns of grads^2 + eps1
+		r, c := supp[vr].Data(), supp[vc].Data()
+		for i := range r {
+			sum := mat.Float(0)
+			for _, x := range g[i*cols : (i+1)*cols] {
+				sum += x*x + o.Epsilon1
+			}
+			r[i] = beta2*r[i] + (1-beta2)*sum/mat.Float(cols)
+		}
+		for j := range c {
+			sum := mat.Float(0)
+			for i := 0; i < rows; i++ {
+				x := g[i*cols+j]
+				sum += x*x + o.Epsilon1
+			}
+			c[j] = beta2*c[j] + (1-beta2)*sum/mat.Float(rows)
+		}
+		// v = (r c) / mean(r)
+		rMean := mat.Float(0)
+		for _, x := range r {
+			rMean += x
+		}
+		rMean /= mat.Float(rows)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				k := i*cols + j
+				d[k] = g[k] / mat.Sqrt(r[i]*c[j]/rMean)
+			}
+		}
+	} else {
+		v := supp[vr].Data()
+		for k, x := range g {
+			v[k] = beta2*v[k] + (1-beta2)*(x*x+o.Epsilon1)
+			d[k] = x / mat.Sqrt(v[k])
+		}
+	}
+
+	// update clipping
+	if clip := rms(d) / o.ClipThreshold; clip > 1 {
+		for k := range d {
+			d[k] /= clip
+		}
+	}
+
+	lr := o.stepSize(weights)
+	for k := range d {
+		d[k] *= lr
+	}
+	if o.Beta1 > 0 {
+		mv := supp[m].Data()
+		for k := range d {
+			mv[k] = o.Beta1*mv[k] + (1-o.Beta1)*d[k]
+			d[k] = mv[k]
+		}
+	}
+	if o.WeightDecay != 0 {
+		w := weights.Data()
+		for k := range d {
+			d[k] += o.WeightDecay * lr * w[k]
+		}
+	}
+	return supp[delta]
+}
+
+// stepSize returns the learning rate of the current time step.
+func (o *Adafactor) stepSize(weights mat.Matrix) mat.Float {
+	lr := o.LR
+	if o.RelativeStep {
+		minStep := mat.Float(1.0e-2)
+		if o.WarmupInit {
+			minStep = 1.0e-6 * mat.Float(o.TimeStep)
+		}
+		lr = 1 / mat.Sqrt(mat.Float(o.TimeStep))
+		if minStep < lr {
+			lr = minStep
+		}
+	}
+	if o.ScaleParameter {
+		lr *= mat.Max(o.Epsilon2, rms(weights.Data()))
+	}
+	return lr
+}
+
+// rms returns the root mean square of x.
+func rms(x []mat.Float) mat.Float {
+	if len(x) == 0 {
+		return 0
+	}
+	sum := mat.Float(0)
+	for _, v := range x {
+		sum += v * v
+	}
+	return mat.Sqrt(sum / mat.Float(len(x)))
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package adafactor
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdafactor_NewSupport(t *testing.T) {
+	updater := New(NewDefaultConfig())
+	supp := updater.NewSupport(3, 4).Data
+	assert.Len(t, supp, 3)
+	assert.Equal(t, 3, supp[vr].Size())
+	assert.Equal(t, 4, supp[vc].Size())
+
+	updater = New(NewConfig(0.1, 0.9, 0))
+	supp = updater.NewSupport(5, 1).Data
+	assert.Len(t, supp, 4)
+	assert.Equal(t, 5, supp[vr].Size())
+	assert.Equal(t, 0, supp[vc].Size())
+}
+
+func TestAdafactor_Delta(t *testing.T) {
+	c := NewConfig(0.1, 0, 0)
+	c.ScaleParameter = false
+	updater := New(c)
+
+	// at the first step the second moment is grads^2, and the factorization
+	// of a rank-1 matrix is exact: the update is the sign of the gradients
+	weights := mat.NewDense(2, 3, []mat.Float{1, 1, 1, 1, 1, 1})
+	grads := mat.NewDense(2, 3, []mat.Float{1, -2, 3, 2, -4, 6})
+	supp := updater.NewSupport(2, 3).Data
+	delta := updater.calcDelta(grads, supp, weights)
+	assert.InDeltaSlice(t, []mat.Float{0.1, -0.1, 0.1, 0.1, -0.1, 0.1}, delta.Data(), 1.0e-6)
+
+	vector := mat.NewVecDense([]mat.Float{0.5, -0.25})
+	supp = updater.NewSupport(2, 1).Data
+	delta = updater.calcDelta(vector, supp, mat.NewVecDense([]mat.Float{1, 1}))
+	assert.InDeltaSlice(t, []mat.Float{0.1, -0.1}, delta.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.25, 0.0625}, supp[vr].Data(), 1.0e-6)
+}
+
+func TestAdafactor_UpdateClipping(t *testing.T) {
+	c := NewConfig(1, 0, 0)
+	c.ScaleParameter = false
+	c.ClipThreshold = 0.5
+	updater := New(c)
+	supp := updater.NewSupport(2, 1).Data
+	delta := updater.calcDelta(mat.NewVecDense([]mat.Float{1, -1}), supp, mat.NewVecDense([]mat.Float{0, 0}))
+	assert.InDeltaSlice(t, []mat.Float{0.5, -0.5}, delta.Data(), 1.0e-6)
+}
+
+func TestAdafactor_RelativeStep(t *testing.T) {
+	updater := New(NewDefaultConfig())
+	weights := mat.NewVecDense([]mat.Float{3, -4})
+	rmsWeights := mat.Sqrt(12.5)
+	assert.InDelta(t, 1.0e-2*rmsWeights, updater.stepSize(weights), 1.0e-6)
+	updater.TimeStep = 10000
+	assert.InDelta(t, 1.0e-2*rmsWeights, updater.stepSize(weights), 1.0e-6)
+	updater.TimeStep = 40000
+	assert.InDelta(t, 0.005*rmsWeights, updater.stepSize(weights), 1.0e-6)
+}
+
+func TestAdafactor_Optimize(t *testing.T) {
+	updater := New(NewConfig(0.05, 0.9, 0.01))
+	param := nn.NewParam(mat.NewDense(2, 2, []mat.Float{1, -1, 0.5, 2}))
+	target := []mat.Float{0, 0.5, -1, 1}
+	for i := 0; i < 200; i++ {
+		grads := param.Value().Clone()
+		for k := range grads.Data() {
+			grads.Data()[k] -= target[k]
+		}
+		param.PropagateGrad(grads)
+		param.ApplyDelta(updater.Delta(param))
+		param.ZeroGrad()
+		updater.IncExample()
+	}
+	assert.InDeltaSlice(t, target, param.Value().Data(), 0.1)
+}
@@ -5,7 +5,7 @@
 package adagrad
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
@@ -57,6 +57,18 @@ func (o *AdaGrad) Label() int {
 	return gd.AdaGrad
 }
 
+var _ gd.LearningRateSetter = &AdaGrad{}
+
+// LearningRate returns the current learning rate (LR).
+func (o *AdaGrad) LearningRate() mat.Float {
+	return o.LR
+}
+
+// SetLearningRate sets a new learning rate (LR).
+func (o *AdaGrad) SetLearningRate(lr mat.Float) {
+	o.LR = lr
+}
+
 // NewSupport returns a new support structure with the given dimensions.
 func (o *AdaGrad) NewSupport(r, c int) *nn.Payload {
 	return &nn.Payload{
@@ -5,7 +5,7 @@
 package adam
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
@@ -20,6 +20,12 @@ type Config struct {
 	Beta2    mat.Float
 	Epsilon  mat.Float
 	Lambda   mat.Float // AdamW
+	// Quantize8Bit stores the moments in blockwise-quantized 8-bit form,
+	// reducing the memory of the support structure (see NewSupport) to about
+	// 2 bytes per value, plus a scale per block of QuantizationBlockSize
+	// values. At each step, the moments of a param are still dequantized to
+	// temporary full-precision workspaces, along with the delta.
+	Quantize8Bit bool
 }
 
 // NewConfig returns a new Adam Config.
@@ -39,7 +45,11 @@ func NewConfig(stepSize, beta1, beta2, epsilon mat.Float) Config {
 	}
 }
 
-// NewAdamWConfig returns a new Adam Config.
+// NewAdamWConfig returns a new Adam Config, with the weight decay rate lambda.
+//
+// Deprecated: use weightdecay.NewAdamWConfig, which decouples the weight
+// decay from any gd.Method (see package weightdecay). This one only works
+// with Adam, and scales the weight decay by the bias-corrected step size.
 func NewAdamWConfig(stepSize, beta1, beta2, epsilon, lambda mat.Float) Config {
 	if !(beta1 >= 0.0 && beta1 < 1.0) {
 		panic("adam: `beta1` must be in the range [0.0, 1.0)")
@@ -89,9 +99,25 @@ func New(c Config) *Adam {
 
 // Label returns the enumeration-like value which identifies this gradient descent method.
 func (o *Adam) Label() int {
+	if o.Quantize8Bit {
+		return gd.Adam8Bit
+	}
 	return gd.Adam
 }
 
+var _ gd.LearningRateSetter = &Adam{}
+
+// LearningRate returns the current learning rate (StepSize).
+func (o *Adam) LearningRate() mat.Float {
+	return o.StepSize
+}
+
+// SetLearningRate sets a new learning rate (StepSize).
+func (o *Adam) SetLearningRate(lr mat.Float) {
+	o.StepSize = lr
+	o.updateAlpha()
+}
+
 const (
 	v    int = 0
 	m    int = 1
@@ -102,6 +128,9 @@ const (
 
 // NewSupport returns a new support structure with the given dimensions.
 func (o *Adam) NewSupport(r, c int) *nn.Payload {
+	if o.Quantize8Bit {
+		return o.newQuantizedSupport(r, c)
+	}
 	supp := make([]mat.Matrix, 5)
 	supp[v] = mat.NewEmptyDense(r, c)
 	supp[m] = mat.NewEmptyDense(r, c)
@@ -114,6 +143,19 @@ func (o *Adam) NewSupport(r, c int) *nn.Payload {
 	}
 }
 
+var _ gd.TimeStepper = &Adam{}
+
+// CurrentTimeStep returns the current time step.
+func (o *Adam) CurrentTimeStep() int {
+	return o.TimeStep
+}
+
+// SetTimeStep sets the current time step.
+func (o *Adam) SetTimeStep(t int) {
+	o.TimeStep = t
+	o.updateAlpha()
+}
+
 // IncExample beats the occurrence of a new example.
 func (o *Adam) IncExample() {
 	o.TimeStep++
@@ -126,6 +168,9 @@ func (o *Adam) updateAlpha() {
 
 // Delta returns the difference between the current params and where the method wants it to be.
 func (o *Adam) Delta(param nn.Param) mat.Matrix {
+	if o.Quantize8Bit {
+		return o.quantizedDelta(param)
+	}
 	if o.adamw {
 		return o.calcDeltaW(param.Grad(), gd.GetOrSetPayload(param, o).Data, param.Value())
 	}
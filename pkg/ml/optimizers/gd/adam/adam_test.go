@@ -5,7 +5,9 @@
 package adam
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -212,3 +214,66 @@ func Test_AdamWUpdate2(t *testing.T) {
 		0.69796675, -0.3994073, 0.19821806,
 	}, params.Data(), 1.0e-5)
 }
+
+func TestAdam_SetLearningRate(t *testing.T) {
+	updater := New(NewConfig(0.001, 0.9, 0.999, 1.0e-8))
+	alpha := updater.Alpha
+	updater.SetLearningRate(0.002)
+	assert.Equal(t, mat.Float(0.002), updater.LearningRate())
+	assert.InDelta(t, alpha*2, updater.Alpha, 1.0e-9)
+}
+
+func TestQuantizeBlockwise(t *testing.T) {
+	x := make([]mat.Float, QuantizationBlockSize+10)
+	for i := range x {
+		x[i] = mat.Float(i%7-3) * mat.Pow(10, -mat.Float(i%5))
+	}
+	for i := QuantizationBlockSize; i < len(x); i++ {
+		x[i] /= 3000 // the last block has a different scale
+	}
+	blocks := 2
+	codes := make([]byte, len(x))
+	scales := make([]mat.Float, blocks)
+	quantizeBlockwise(x, firstMomentExp, codes, scales)
+	assert.Equal(t, []mat.Float{3, 0.001}, scales)
+
+	y := make([]mat.Float, len(x))
+	dequantizeBlockwise(codes, scales, firstMomentExp, y)
+	for i := range x {
+		// the error of the codes is at most 0.5/127 in sqrt(|x| / scale)
+		scale := scales[i/QuantizationBlockSize]
+		step := mat.Sqrt(mat.Abs(x[i])/scale) + 0.5/127
+		assert.InDelta(t, x[i], y[i], float64(scale*(step*step-(step-0.5/127)*(step-0.5/127)))+1e-9, i)
+	}
+}
+
+func TestAdam_Quantize8Bit(t *testing.T) {
+	config := NewConfig(0.01, 0.9, 0.999, 1.0e-8)
+	qconfig := config
+	qconfig.Quantize8Bit = true
+	updater, qupdater := New(config), New(qconfig)
+	assert.Equal(t, gd.Adam8Bit, qupdater.Label())
+
+	newParam := func() nn.Param {
+		return nn.NewParam(mat.NewDense(2, 3, []mat.Float{0.4, -0.4, 0.5, 1.0, 0.8, -0.2}))
+	}
+	param, qparam := newParam(), newParam()
+	for step := 0; step < 5; step++ {
+		for _, p := range []nn.Param{param, qparam} {
+			p.PropagateGrad(mat.NewDense(2, 3, []mat.Float{0.9, 0.7, -0.4, 0.8, 0.001, 0.3}).ProdScalar(mat.Float(step + 1)))
+		}
+		param.ApplyDelta(updater.Delta(param))
+		qparam.ApplyDelta(qupdater.Delta(qparam))
+		param.ZeroGrad()
+		qparam.ZeroGrad()
+		updater.IncExample()
+		qupdater.IncExample()
+	}
+	// each param moved by about 0.05
+	assert.InDeltaSlice(t, param.Value().Data(), qparam.Value().Data(), 5.0e-3)
+
+	payload := qparam.Payload()
+	assert.Len(t, payload.Data, 2) // the scales only, no full-size buffer
+	assert.Len(t, payload.Bytes, 2)
+	assert.Len(t, payload.Bytes[qv], 6)
+}
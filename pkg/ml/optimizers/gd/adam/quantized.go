@@ -0,0 +1,122 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package adam
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+// QuantizationBlockSize is the number of values sharing the same scale in
+// the blockwise quantization of the moments (see Config.Quantize8Bit).
+const QuantizationBlockSize = 2048
+
+// The exponents of the power-law companding of the quantized moments.
+// The codes are uniform in (|x| / absmax)^(1/p): the higher p, the wider
+// the dynamic range, which must be large for the second moment, since a
+// value quantized to zero would cause a huge update.
+const (
+	firstMomentExp  = 2
+	secondMomentExp = 8
+)
+
+// The indices of the support structure of the quantized Adam.
+// The codes of the moments are stored in the payload Bytes, with the same
+// indices of the scales.
+const (
+	qv int = 0 // scales of the first moment
+	qm int = 1 // scales of the second moment
+)
+
+func (o *Adam) newQuantizedSupport(r, c int) *nn.Payload {
+	blocks := (r*c + QuantizationBlockSize - 1) / QuantizationBlockSize
+	return &nn.Payload{
+		Label: o.Label(),
+		Data: []mat.Matrix{
+			qv: mat.NewEmptyVecDense(blocks),
+			qm: mat.NewEmptyVecDense(blocks),
+		},
+		Bytes: [][]byte{
+			qv: make([]byte, r*c),
+			qm: make([]byte, r*c),
+		},
+	}
+}
+
+// quantizedDelta dequantizes the moments of the param, computes the delta as
+// usual, and quantizes the updated moments. The full-precision moments and
+// buffers are workspaces, released at the end of the step; the delta is a
+// new matrix, since it is applied by the caller.
+func (o *Adam) quantizedDelta(param nn.Param) mat.Matrix {
+	payload := gd.GetOrSetPayload(param, o)
+	r, c := param.Dims()
+	supp := make([]mat.Matrix, 5)
+	supp[v] = mat.GetEmptyDenseWorkspace(r, c)
+	supp[m] = mat.GetEmptyDenseWorkspace(r, c)
+	supp[buf1] = mat.GetEmptyDenseWorkspace(r, c)
+	supp[buf2] = mat.GetEmptyDenseWorkspace(r, c)
+	supp[buf3] = mat.NewEmptyDense(r, c)
+	defer func() {
+		for _, i := range []int{v, m, buf1, buf2} {
+			mat.ReleaseMatrix(supp[i])
+		}
+	}()
+
+	dequantizeBlockwise(payload.Bytes[qv], payload.Data[qv].Data(), firstMomentExp, supp[v].Data())
+	dequantizeBlockwise(payload.Bytes[qm], payload.Data[qm].Data(), secondMomentExp, supp[m].Data())
+	var delta mat.Matrix
+	if o.adamw {
+		delta = o.calcDeltaW(param.Grad(), supp, param.Value())
+	} else {
+		delta = o.calcDelta(param.Grad(), supp)
+	}
+	quantizeBlockwise(supp[v].Data(), firstMomentExp, payload.Bytes[qv], payload.Data[qv].Data())
+	quantizeBlockwise(supp[m].Data(), secondMomentExp, payload.Bytes[qm], payload.Data[qm].Data())
+	return delta
+}
+
+// quantizeBlockwise quantizes x to int8 codes, in blocks of
+// QuantizationBlockSize values. The scale of each block is its largest
+// absolute value, and the codes are uniform in (|x| / scale)^(1/p).
+func quantizeBlockwise(x []mat.Float, p mat.Float, codes []byte, scales []mat.Float) {
+	for b := range scales {
+		block := x[b*QuantizationBlockSize : minInt((b+1)*QuantizationBlockSize, len(x))]
+		absMax := mat.Float(0)
+		for _, val := range block {
+			absMax = mat.Max(absMax, mat.Abs(val))
+		}
+		scales[b] = absMax
+		for i, val := range block {
+			var q int8
+			if absMax > 0 {
+				q = int8(mat.Round(127 * mat.Pow(mat.Abs(val)/absMax, 1/p)))
+			}
+			if val < 0 {
+				q = -q
+			}
+			codes[b*QuantizationBlockSize+i] = byte(q)
+		}
+	}
+}
+
+// dequantizeBlockwise is the inverse of quantizeBlockwise.
+func dequantizeBlockwise(codes []byte, scales []mat.Float, p mat.Float, x []mat.Float) {
+	for i, code := range codes {
+		q := int8(code)
+		val := scales[i/QuantizationBlockSize] * mat.Pow(mat.Abs(mat.Float(q))/127, p)
+		if q < 0 {
+			val = -val
+		}
+		x[i] = val
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package averaging provides wrappers of gd.GradientDescent maintaining an
+// average of the params of a model during the training: the exponential
+// moving average (EMA), the stochastic weight averaging (SWA), and the slow
+// weights of Lookahead.
+//
+// The averaged weights can be swapped into the model, e.g. to evaluate or
+// to serialize it:
+//     ema.WithAveraged(func() {
+//         err = utils.SerializeToFile(filename, model)
+//     })
+package averaging
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+// averages holds a copy of the values of the params of a model, which can be
+// swapped with the values of the params.
+//
+// The averages are kept in full precision even when swapped into params
+// with a half-precision value, along with the trained values they replace.
+type averages struct {
+	mu      sync.Mutex
+	names   []string
+	params  []nn.Param
+	values  []*mat.Dense
+	trained []*mat.Dense // the values of the params while swapped
+	swapped bool
+}
+
+// newAverages returns the averages of the params of m with a value,
+// initialized with a copy of the values.
+func newAverages(m nn.Model) *averages {
+	a := &averages{}
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		if rows, cols := param.Dims(); rows == 0 && cols == 0 {
+			return
+		}
+		a.names = append(a.names, name)
+		a.params = append(a.params, param)
+		a.values = append(a.values, copyValue(param))
+	})
+	return a
+}
+
+// copyValue returns a copy of the value of the param.
+func copyValue(param nn.Param) *mat.Dense {
+	var out *mat.Dense
+	param.ReadValue(func(value mat.Matrix) {
+		out = mat.NewDense(value.Rows(), value.Columns(), value.Data())
+	})
+	return out
+}
+
+// optimize calls fn, which optimizes the params and updates the averages,
+// holding the lock, so that the averages can't be swapped in the meanwhile.
+// It panics, before calling fn, if the averages are swapped into the model:
+// the optimizer would update the averaged values instead of the trained ones.
+func (a *averages) optimize(fn func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.swapped {
+		panic("averaging: the averaged weights are swapped into the model")
+	}
+	fn()
+}
+
+// update calls fn for each param, with its value and its average.
+// It must be called by the fn of optimize.
+func (a *averages) update(fn func(value []mat.Float, avg []mat.Float)) {
+	for i, param := range a.params {
+		param.ReadValue(func(value mat.Matrix) {
+			fn(value.Data(), a.values[i].Data())
+		})
+	}
+}
+
+// copyToParams sets the values of the params to the averages.
+// It must be called by the fn of optimize.
+func (a *averages) copyToParams() {
+	for i, param := range a.params {
+		setValue(param, a.values[i])
+	}
+}
+
+// swap swaps the averages into the params, or restores the trained values
+// of the params if the averages are already swapped.
+func (a *averages) swap() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.swapped {
+		for i, param := range a.params {
+			setValue(param, a.trained[i])
+			mat.ReleaseDense(a.trained[i])
+		}
+		a.trained = nil
+	} else {
+		a.trained = make([]*mat.Dense, len(a.params))
+		for i, param := range a.params {
+			a.trained[i] = copyValue(param)
+			setValue(param, a.values[i])
+		}
+	}
+	a.swapped = !a.swapped
+}
+
+// setValue sets the value of the param, preserving its payload.
+func setValue(param nn.Param, value mat.Matrix) {
+	var delta mat.Matrix
+	param.ReadValue(func(current mat.Matrix) {
+		delta = current.Sub(value)
+	})
+	param.ApplyDelta(delta)
+	mat.ReleaseMatrix(delta)
+}
+
+// export returns a copy of the averages, by param name (see nn.ForEachNamedParam).
+func (a *averages) export() map[string]mat.Matrix {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make(map[string]mat.Matrix, len(a.values))
+	for i, value := range a.values {
+		out[a.names[i]] = value.Clone()
+	}
+	return out
+}
+
+// exportData returns a copy of the data of the averages, by param name.
+func (a *averages) exportData() map[string][]mat.Float {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make(map[string][]mat.Float, len(a.values))
+	for i, value := range a.values {
+		out[a.names[i]] = append([]mat.Float(nil), value.Data()...)
+	}
+	return out
+}
+
+// importData sets the averages to the data returned by exportData.
+// It returns an error, without modifying the averages, if a param is
+// missing or has a different size.
+func (a *averages) importData(data map[string][]mat.Float) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.swapped {
+		return fmt.Errorf("averaging: the averaged weights are swapped into the model")
+	}
+	for i, name := range a.names {
+		values, ok := data[name]
+		if !ok {
+			return fmt.Errorf("averaging: missing averaged param %q", name)
+		}
+		if len(values) != a.values[i].Size() {
+			return fmt.Errorf("averaging: averaged param %q has size %d, found %d", name, a.values[i].Size(), len(values))
+		}
+	}
+	for i, name := range a.names {
+		copy(a.values[i].Data(), data[name])
+	}
+	return nil
+}
+
+// State is the state of an Averager, e.g. to save it in a checkpoint along
+// with the model and the optimizer.
+type State struct {
+	// Steps is the number of optimization steps.
+	Steps int
+	// Snapshots is the number of snapshots averaged so far (SWA only).
+	Snapshots int
+	// Averaged are the averaged values, by param name (see nn.ForEachNamedParam).
+	Averaged map[string][]mat.Float
+}
+
+// Averager is implemented by the optimizers maintaining an average of the
+// params.
+type Averager interface {
+	// SwapAveraged exchanges the values of the params with the averaged ones.
+	// Calling it again restores the trained values, which are kept aside in
+	// full precision along with the averages: the averages swapped into the
+	// half-precision params are rounded, but not the ones kept by the
+	// Averager. The optimization panics while the averaged values are
+	// swapped into the model.
+	SwapAveraged()
+	// WithAveraged calls fn with the averaged values swapped into the model.
+	WithAveraged(fn func())
+	// Averaged returns a copy of the averaged values, by param name (see
+	// nn.ForEachNamedParam).
+	Averaged() map[string]mat.Matrix
+	// State returns a copy of the state of the Averager.
+	State() State
+	// SetState restores a state returned by State, e.g. to resume a
+	// training. It returns an error, without modifying the Averager, if
+	// the averaged params don't match, or if they are swapped into the model.
+	SetState(state State) error
+}
+
+// withAveraged calls fn with the averages swapped into the model.
+func (a *averages) withAveraged(fn func()) {
+	a.swap()
+	defer a.swap()
+	fn()
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package averaging
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+// newTestOptimizer returns a model with two params, and a SGD optimizer
+// with learning rate 1.
+func newTestOptimizer() (*testModel, *gd.GradientDescent) {
+	m := &testModel{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{0})),
+	}
+	m.B.SetDType(mat.DTypeBFloat16)
+	method := sgd.New(sgd.NewConfig(1, 0, false))
+	return m, gd.NewOptimizer(method, nn.NewDefaultParamsIterator(m), gd.ConcurrentComputations(1))
+}
+
+// step propagates a gradient of 1 to all the params, and optimizes them.
+func step(m *testModel, o interface{ Optimize() }) {
+	nn.ForEachParam(m, func(param nn.Param) {
+		param.PropagateGrad(mat.NewInitVecDense(param.Value().Size(), 1))
+	})
+	o.Optimize()
+}
+
+func TestEMA(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	ema := NewEMA(optimizer, m, 0.5)
+	step(m, ema) // w = [0, 1], avg = [0.5, 1.5]
+	step(m, ema) // w = [-1, 0], avg = [-0.25, 0.75]
+	assert.InDeltaSlice(t, []mat.Float{-1, 0}, m.W.Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{-0.25, 0.75}, ema.Averaged()["w"].Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{-1.25}, ema.Averaged()["b"].Data(), 1.0e-6)
+
+	ema.WithAveraged(func() {
+		assert.InDeltaSlice(t, []mat.Float{-0.25, 0.75}, m.W.Value().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, []mat.Float{-1.25}, m.B.Value().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, []mat.Float{-0.25, 0.75}, ema.Averaged()["w"].Data(), 1.0e-6)
+		assert.Panics(t, func() { step(m, ema) })
+	})
+	assert.InDeltaSlice(t, []mat.Float{-1, 0}, m.W.Value().Data(), 1.0e-6)
+	assert.Equal(t, mat.DTypeBFloat16, m.B.DType())
+	assert.NotNil(t, m.W.Payload(), "the optimizer state must be preserved")
+}
+
+func TestEMA_WarmupDecay(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	ema := NewEMA(optimizer, m, 0.99)
+	ema.WarmupDecay = true
+	step(m, ema) // decay = 2/11
+	assert.InDeltaSlice(t, []mat.Float{2.0 / 11, 1 + 2.0/11}, ema.Averaged()["w"].Data(), 1.0e-6)
+}
+
+func TestSWA(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	swa := NewSWA(optimizer, m, 2, 2)
+	for i := 0; i < 6; i++ {
+		step(m, swa)
+	}
+	// snapshots at the steps 2, 4 and 6: w = [-1, 0], [-3, -2], [-5, -4]
+	assert.Equal(t, 3, swa.Snapshots)
+	assert.InDeltaSlice(t, []mat.Float{-3, -2}, swa.Averaged()["w"].Data(), 1.0e-6)
+
+	swa.SwapAveraged()
+	assert.InDeltaSlice(t, []mat.Float{-3, -2}, m.W.Value().Data(), 1.0e-6)
+	swa.SwapAveraged()
+	assert.InDeltaSlice(t, []mat.Float{-5, -4}, m.W.Value().Data(), 1.0e-6)
+}
+
+func TestLookahead(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	lookahead := NewLookahead(optimizer, m, 2, 0.5)
+	step(m, lookahead)
+	assert.InDeltaSlice(t, []mat.Float{0, 1}, m.W.Value().Data(), 1.0e-6)
+	step(m, lookahead) // fast = [-1, 0], slow = [0, 1]
+	assert.InDeltaSlice(t, []mat.Float{0, 1}, m.W.Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0, 1}, lookahead.Averaged()["w"].Data(), 1.0e-6)
+	step(m, lookahead)
+	step(m, lookahead) // fast = [-2, -1], slow = [-1, 0]
+	assert.InDeltaSlice(t, []mat.Float{-1, 0}, m.W.Value().Data(), 1.0e-6)
+}
+
+func TestOptimize_Swapped(t *testing.T) {
+	for name, newAverager := range map[string]func(m *testModel, o *gd.GradientDescent) Averager{
+		"ema":       func(m *testModel, o *gd.GradientDescent) Averager { return NewEMA(o, m, 0.5) },
+		"swa":       func(m *testModel, o *gd.GradientDescent) Averager { return NewSWA(o, m, 1, 1) },
+		"lookahead": func(m *testModel, o *gd.GradientDescent) Averager { return NewLookahead(o, m, 1, 0.5) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, _ := newTestOptimizer()
+			method := sgd.New(sgd.NewConfig(1, 0.9, false)) // with a payload
+			a := newAverager(m, gd.NewOptimizer(method, nn.NewDefaultParamsIterator(m)))
+			step(m, a.(interface{ Optimize() }))
+			trained := m.W.Value().Clone().Data()
+			payload := m.W.Payload().Data[0].Clone().Data()
+			state := a.State()
+
+			a.WithAveraged(func() {
+				averaged := m.W.Value().Clone().Data()
+				assert.Panics(t, func() { step(m, a.(interface{ Optimize() })) })
+				assert.Equal(t, averaged, m.W.Value().Data(), "the averaged values must not be optimized")
+				m.W.ZeroGrad()
+				m.B.ZeroGrad()
+			})
+			assert.Equal(t, trained, m.W.Value().Data())
+			assert.Equal(t, payload, m.W.Payload().Data[0].Data(), "the payload must not advance")
+			assert.Equal(t, state, a.State())
+		})
+	}
+}
+
+func TestEMA_HalfPrecision(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	ema := NewEMA(optimizer, m, 0.3)
+	step(m, ema) // b = -1, avg = -0.7, which is not a bfloat16
+	ema.WithAveraged(func() {
+		assert.InDeltaSlice(t, []mat.Float{-0.7}, m.B.Value().Data(), 1.0e-2)
+	})
+	assert.Equal(t, []mat.Float{-1}, m.B.Value().Data())
+	assert.InDeltaSlice(t, []mat.Float{-0.7}, ema.Averaged()["b"].Data(), 1.0e-6)
+}
+
+func TestEMA_State(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	ema := NewEMA(optimizer, m, 0.5)
+	step(m, ema)
+	step(m, ema)
+	state := ema.State()
+	assert.Equal(t, 2, state.Steps)
+
+	m2, optimizer2 := newTestOptimizer()
+	resumed := NewEMA(optimizer2, m2, 0.5)
+	require.NoError(t, resumed.SetState(state))
+	assert.Equal(t, 2, resumed.Steps)
+	assert.Equal(t, ema.Averaged()["w"].Data(), resumed.Averaged()["w"].Data())
+
+	assert.Error(t, resumed.SetState(State{Averaged: map[string][]mat.Float{"w": {1, 2}}}))
+	assert.Error(t, resumed.SetState(State{Averaged: map[string][]mat.Float{"w": {1}, "b": {1}}}))
+	resumed.WithAveraged(func() {
+		assert.Error(t, resumed.SetState(state))
+	})
+}
+
+func TestSWA_State(t *testing.T) {
+	m, optimizer := newTestOptimizer()
+	swa := NewSWA(optimizer, m, 1, 1)
+	step(m, swa)
+	step(m, swa)
+	state := swa.State()
+	assert.Equal(t, 2, state.Snapshots)
+
+	m2, optimizer2 := newTestOptimizer()
+	resumed := NewSWA(optimizer2, m2, 1, 1)
+	require.NoError(t, resumed.SetState(state))
+	assert.Equal(t, 2, resumed.Snapshots)
+	assert.Equal(t, swa.Averaged()["w"].Data(), resumed.Averaged()["w"].Data())
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package averaging
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+var _ Averager = &EMA{}
+
+// EMA wraps a GradientDescent, maintaining the exponential moving average of
+// the params after each optimization step:
+//     avg = decay * avg + (1 - decay) * value
+// If WarmupDecay is true, the decay is min(decay, (1 + t) / (10 + t)) at step
+// t, so that the average follows the params more closely at the beginning.
+type EMA struct {
+	*gd.GradientDescent
+	Decay       mat.Float
+	WarmupDecay bool
+	Steps       int
+	avg         *averages
+}
+
+// NewEMA returns a new EMA of the params of m, optimized by optimizer.
+func NewEMA(optimizer *gd.GradientDescent, m nn.Model, decay mat.Float) *EMA {
+	if !(decay > 0 && decay < 1) {
+		panic("averaging: the EMA `decay` must be in the range (0, 1)")
+	}
+	return &EMA{
+		GradientDescent: optimizer,
+		Decay:           decay,
+		avg:             newAverages(m),
+	}
+}
+
+// Optimize optimizes the params, then updates the averages.
+func (e *EMA) Optimize() {
+	e.avg.optimize(func() {
+		e.GradientDescent.Optimize()
+		e.Steps++
+		decay := e.Decay
+		if e.WarmupDecay {
+			if d := mat.Float(1+e.Steps) / mat.Float(10+e.Steps); d < decay {
+				decay = d
+			}
+		}
+		e.avg.update(func(value, avg []mat.Float) {
+			for i, v := range value {
+				avg[i] = decay*avg[i] + (1-decay)*v
+			}
+		})
+	})
+}
+
+// Step optimizes the params, as Optimize, if the accumulated micro-batches
+// are complete (see gd.GradientDescent.Ready), and reports whether it did.
+func (e *EMA) Step() bool {
+	if !e.Ready() {
+		return false
+	}
+	e.Optimize()
+	return true
+}
+
+// SwapAveraged exchanges the values of the params with the averaged ones.
+func (e *EMA) SwapAveraged() {
+	e.avg.swap()
+}
+
+// WithAveraged calls fn with the averaged values swapped into the model.
+func (e *EMA) WithAveraged(fn func()) {
+	e.avg.withAveraged(fn)
+}
+
+// Averaged returns a copy of the averaged values, by param name.
+func (e *EMA) Averaged() map[string]mat.Matrix {
+	return e.avg.export()
+}
+
+// State returns a copy of the state of the EMA.
+func (e *EMA) State() State {
+	return State{
+		Steps:    e.Steps,
+		Averaged: e.avg.exportData(),
+	}
+}
+
+// SetState restores a state returned by State.
+func (e *EMA) SetState(state State) error {
+	if err := e.avg.importData(state.Averaged); err != nil {
+		return err
+	}
+	e.Steps = state.Steps
+	return nil
+}
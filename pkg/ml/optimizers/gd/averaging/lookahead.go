@@ -0,0 +1,104 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package averaging
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+var _ Averager = &Lookahead{}
+
+// Lookahead wraps a GradientDescent, which updates the "fast" weights of the
+// model, implementing the Lookahead optimizer (Zhang et al., 2019): every K
+// optimization steps, the "slow" weights move towards the fast ones
+//     slow = slow + alpha * (fast - slow)
+// and the fast weights are reset to the slow ones.
+// The slow weights are the averaged ones.
+type Lookahead struct {
+	*gd.GradientDescent
+	K     int
+	Alpha mat.Float
+	Steps int
+	slow  *averages
+}
+
+// NewLookahead returns a new Lookahead of the params of m, optimized by optimizer.
+// Usual values are k = 5 and alpha = 0.5.
+func NewLookahead(optimizer *gd.GradientDescent, m nn.Model, k int, alpha mat.Float) *Lookahead {
+	if k < 1 {
+		panic("averaging: the Lookahead `k` must be >= 1")
+	}
+	if !(alpha > 0 && alpha <= 1) {
+		panic("averaging: the Lookahead `alpha` must be in the range (0, 1]")
+	}
+	return &Lookahead{
+		GradientDescent: optimizer,
+		K:               k,
+		Alpha:           alpha,
+		slow:            newAverages(m),
+	}
+}
+
+// Optimize optimizes the fast weights, then synchronizes them with the slow
+// ones, every K steps.
+func (l *Lookahead) Optimize() {
+	l.slow.optimize(func() {
+		l.GradientDescent.Optimize()
+		l.Steps++
+		if l.Steps%l.K != 0 {
+			return
+		}
+		l.slow.update(func(fast, slow []mat.Float) {
+			for i, v := range fast {
+				slow[i] += l.Alpha * (v - slow[i])
+			}
+		})
+		l.slow.copyToParams()
+	})
+}
+
+// Step optimizes the params, as Optimize, if the accumulated micro-batches
+// are complete (see gd.GradientDescent.Ready), and reports whether it did.
+func (l *Lookahead) Step() bool {
+	if !l.Ready() {
+		return false
+	}
+	l.Optimize()
+	return true
+}
+
+// SwapAveraged exchanges the values of the params with the slow weights.
+func (l *Lookahead) SwapAveraged() {
+	l.slow.swap()
+}
+
+// WithAveraged calls fn with the slow weights swapped into the model.
+func (l *Lookahead) WithAveraged(fn func()) {
+	l.slow.withAveraged(fn)
+}
+
+// Averaged returns a copy of the slow weights, by param name.
+func (l *Lookahead) Averaged() map[string]mat.Matrix {
+	return l.slow.export()
+}
+
+// State returns a copy of the state of the Lookahead.
+func (l *Lookahead) State() State {
+	return State{
+		Steps:    l.Steps,
+		Averaged: l.slow.exportData(),
+	}
+}
+
+// SetState restores a state returned by State.
+func (l *Lookahead) SetState(state State) error {
+	if err := l.slow.importData(state.Averaged); err != nil {
+		return err
+	}
+	l.Steps = state.Steps
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package averaging
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+var _ Averager = &SWA{}
+
+// SWA wraps a GradientDescent, implementing the stochastic weight averaging
+// (Izmailov et al., 2018): starting from the given step, a snapshot of the
+// params is taken every Frequency optimization steps, and the averaged
+// weights are the arithmetic mean of the snapshots.
+//
+// Since the statistics of the batch normalization layers are not averaged,
+// they should be recomputed with the averaged weights before using them.
+type SWA struct {
+	*gd.GradientDescent
+	Start     int
+	Frequency int
+	Steps     int
+	Snapshots int
+	avg       *averages
+}
+
+// NewSWA returns a new SWA of the params of m, optimized by optimizer.
+func NewSWA(optimizer *gd.GradientDescent, m nn.Model, start, frequency int) *SWA {
+	if frequency < 1 {
+		panic("averaging: the SWA `frequency` must be >= 1")
+	}
+	return &SWA{
+		GradientDescent: optimizer,
+		Start:           start,
+		Frequency:       frequency,
+		avg:             newAverages(m),
+	}
+}
+
+// Optimize optimizes the params, then takes a snapshot if needed.
+func (s *SWA) Optimize() {
+	s.avg.optimize(func() {
+		s.GradientDescent.Optimize()
+		s.Steps++
+		if s.Steps < s.Start || (s.Steps-s.Start)%s.Frequency != 0 {
+			return
+		}
+		n := mat.Float(s.Snapshots)
+		s.avg.update(func(value, avg []mat.Float) {
+			for i, v := range value {
+				avg[i] = (avg[i]*n + v) / (n + 1)
+			}
+		})
+		s.Snapshots++
+	})
+}
+
+// Step optimizes the params, as Optimize, if the accumulated micro-batches
+// are complete (see gd.GradientDescent.Ready), and reports whether it did.
+func (s *SWA) Step() bool {
+	if !s.Ready() {
+		return false
+	}
+	s.Optimize()
+	return true
+}
+
+// SwapAveraged exchanges the values of the params with the averaged ones.
+// Before the first snapshot, the averaged values are the initial ones.
+func (s *SWA) SwapAveraged() {
+	s.avg.swap()
+}
+
+// WithAveraged calls fn with the averaged values swapped into the model.
+func (s *SWA) WithAveraged(fn func()) {
+	s.avg.withAveraged(fn)
+}
+
+// Averaged returns a copy of the averaged values, by param name.
+func (s *SWA) Averaged() map[string]mat.Matrix {
+	return s.avg.export()
+}
+
+// State returns a copy of the state of the SWA.
+func (s *SWA) State() State {
+	return State{
+		Steps:     s.Steps,
+		Snapshots: s.Snapshots,
+		Averaged:  s.avg.exportData(),
+	}
+}
+
+// SetState restores a state returned by State.
+func (s *SWA) SetState(state State) error {
+	if err := s.avg.importData(state.Averaged); err != nil {
+		return err
+	}
+	s.Steps = state.Steps
+	s.Snapshots = state.Snapshots
+	return nil
+}
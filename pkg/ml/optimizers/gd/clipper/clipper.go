@@ -5,7 +5,7 @@
 package clipper
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 // GradClipper is implemented by any value that has the Clip method.
@@ -5,7 +5,8 @@
 package gd
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/clipper"
 	"github.com/nlpodyssey/spago/pkg/utils/processingqueue"
@@ -23,6 +24,38 @@ type GradientDescent struct {
 	// such as the params update step.
 	// The default size is defaultProcessingQueueSize.
 	processingQueue processingqueue.ProcessingQueue
+	// groups are the optional param groups (see WithParamGroups).
+	groups  []*paramGroup
+	groupOf map[nn.Param]*paramGroup
+	// accumulation is the optional gradient accumulation (see WithGradientAccumulation).
+	accumulation *accumulation
+	// gradientsCallbacks are called before the clipping (see WithGradientsCallback).
+	gradientsCallbacks []func(params []nn.Param)
+	// updatesCallbacks are called after the update (see WithUpdatesCallback).
+	updatesCallbacks []func(params []nn.Param, deltas []mat.Matrix)
+	// deltas are the deltas of the last update, recorded only if there are updatesCallbacks.
+	deltas []mat.Matrix
+}
+
+var _ Optimizer = &GradientDescent{}
+
+// Optimizer is implemented by GradientDescent and by the optimizers wrapping
+// it, such as the ones of the averaging package, so that a training loop can
+// use any of them.
+type Optimizer interface {
+	ExampleScheduler
+	BatchScheduler
+	EpochScheduler
+	// Backward propagates the gradients of the loss (see GradientDescent.Backward).
+	Backward(g *ag.Graph, loss ag.Node, opts ...ag.BackwardOption)
+	// Ready reports whether the accumulated gradients can be optimized (see
+	// GradientDescent.Ready).
+	Ready() bool
+	// MicroBatches returns the number of accumulated micro-batches (see
+	// GradientDescent.MicroBatches).
+	MicroBatches() int
+	// Optimize optimizes the params (see GradientDescent.Optimize).
+	Optimize()
 }
 
 // defaultProcessingQueueSize is the default size of GradientDescent.processingQueue on a new optimizer.
@@ -49,6 +82,27 @@ func ClipGradByNorm(max, normType mat.Float) Option {
 	}
 }
 
+// WithGradientsCallback is an option to call the callback at each
+// optimization step, with the params to optimize, before the clipping of the
+// gradients, e.g. to monitor them. The callback must not retain the params.
+// The option can be used multiple times.
+func WithGradientsCallback(callback func(params []nn.Param)) Option {
+	return func(f *GradientDescent) {
+		f.gradientsCallbacks = append(f.gradientsCallbacks, callback)
+	}
+}
+
+// WithUpdatesCallback is an option to call the callback after each
+// optimization step, with the updated params and the deltas applied to them
+// (nil for the params without gradients), e.g. to monitor the magnitude of
+// the updates. The callback must not retain the params and the deltas.
+// The option can be used multiple times.
+func WithUpdatesCallback(callback func(params []nn.Param, deltas []mat.Matrix)) Option {
+	return func(f *GradientDescent) {
+		f.updatesCallbacks = append(f.updatesCallbacks, callback)
+	}
+}
+
 // ConcurrentComputations sets the maximum number of concurrent computations handled by the GradientDescent
 // for heavy tasks such as the params update steps.
 // The value 1 corresponds to sequential execution.
@@ -76,22 +130,37 @@ func NewOptimizer(method Method, paramsIterator nn.ParamsGetter, opts ...Option)
 }
 
 // Optimize optimize the params, applying the optional gradient clipping.
+// With gradient accumulation, the gradients are unscaled and averaged over the
+// accumulated micro-batches first.
 // After the optimization the params have zero gradients.
 func (o *GradientDescent) Optimize() {
 	o.paramsToOptimize = o.paramsGetter.Params()
 	if o.paramsToOptimize == nil {
 		return
 	}
+	o.discardFrozen()
+	o.unscaleGrads()
+	for _, callback := range o.gradientsCallbacks {
+		callback(o.paramsToOptimize)
+	}
 	o.clipGrads()
+	if len(o.updatesCallbacks) > 0 {
+		o.deltas = make([]mat.Matrix, len(o.paramsToOptimize))
+	}
 	o.updateParams()
+	for _, callback := range o.updatesCallbacks {
+		callback(o.paramsToOptimize, o.deltas)
+	}
 	o.paramsToOptimize = nil
+	o.deltas = nil
 }
 
 // updateParamsSerial applies the optimization method to all the observed parameters.
 func (o *GradientDescent) updateParamsSerial() {
-	for _, param := range o.paramsToOptimize {
+	for i, param := range o.paramsToOptimize {
 		if param.HasGrad() {
-			delta := o.method.Delta(param) // important: don't release delta here
+			delta := o.delta(param) // important: don't release delta here
+			o.recordDelta(i, delta)
 			param.ApplyDelta(delta)
 			param.ZeroGrad()
 		}
@@ -101,23 +170,84 @@ func (o *GradientDescent) updateParamsSerial() {
 // updateParams applies the optimization method to all the observed parameters concurrently.
 func (o *GradientDescent) updateParams() {
 	var wg sync.WaitGroup
-	for _, param := range o.paramsToOptimize {
+	for i, param := range o.paramsToOptimize {
 		if !param.HasGrad() {
 			continue
 		}
 		wg.Add(1)
-		go func(param nn.Param) {
+		go func(i int, param nn.Param) {
 			defer wg.Done()
 			o.processingQueue.Run(func() {
-				delta := o.method.Delta(param)
+				delta := o.delta(param)
+				o.recordDelta(i, delta)
 				param.ApplyDelta(delta)
 			})
 			param.ZeroGrad()
-		}(param)
+		}(i, param)
 	}
 	wg.Wait()
 }
 
+// recordDelta records the delta of the i-th observed param, if required by
+// the updates callbacks.
+func (o *GradientDescent) recordDelta(i int, delta mat.Matrix) {
+	if o.deltas != nil {
+		o.deltas[i] = delta
+	}
+}
+
+// discardFrozen removes the frozen params from the observed parameters,
+// zeroing their gradients.
+func (o *GradientDescent) discardFrozen() {
+	if len(o.groups) == 0 {
+		return
+	}
+	params := o.paramsToOptimize[:0:0]
+	for _, param := range o.paramsToOptimize {
+		if g := o.groupOf[param]; g != nil && g.Frozen {
+			param.ZeroGrad()
+			continue
+		}
+		params = append(params, param)
+	}
+	o.paramsToOptimize = params
+}
+
+// delta returns the delta of the param computed by the method of its group,
+// applying the weight decay of the group.
+func (o *GradientDescent) delta(param nn.Param) mat.Matrix {
+	g := o.groupOf[param]
+	if g == nil {
+		return o.method.Delta(param)
+	}
+	method := o.method
+	if g.Method != nil {
+		method = g.Method
+	}
+	if g.WeightDecay == 0 {
+		return method.Delta(param)
+	}
+	var grad mat.Matrix
+	param.ReadValue(func(value mat.Matrix) {
+		grad = value.ProdScalar(g.WeightDecay)
+	})
+	defer mat.ReleaseMatrix(grad)
+	grad.AddInPlace(param.Grad())
+	return method.Delta(decayedParam{Param: param, grad: grad})
+}
+
+// decayedParam is a param whose gradient includes the weight decay term,
+// so that the methods see it without modifying the gradient of the param.
+type decayedParam struct {
+	nn.Param
+	grad mat.Matrix
+}
+
+// Grad returns the gradient with the weight decay term.
+func (p decayedParam) Grad() mat.Matrix {
+	return p.grad
+}
+
 // clipGrad applies the gradient clipping to all the observed parameters.
 func (o *GradientDescent) clipGrads() {
 	if o.gradClipper == nil {
@@ -134,21 +264,65 @@ func (o *GradientDescent) clipGrads() {
 
 // IncExample beats the occurrence of a new example.
 func (o *GradientDescent) IncExample() {
-	if method, ok := o.method.(ExampleScheduler); ok {
-		method.IncExample()
+	for _, m := range o.methods() {
+		if method, ok := m.(ExampleScheduler); ok {
+			method.IncExample()
+		}
 	}
 }
 
 // IncBatch beats the occurrence of a new batch.
 func (o *GradientDescent) IncBatch() {
-	if method, ok := o.method.(BatchScheduler); ok {
-		method.IncBatch()
+	for _, m := range o.methods() {
+		if method, ok := m.(BatchScheduler); ok {
+			method.IncBatch()
+		}
 	}
 }
 
 // IncEpoch beats the occurrence of a new epoch.
 func (o *GradientDescent) IncEpoch() {
-	if method, ok := o.method.(EpochScheduler); ok {
-		method.IncEpoch()
+	for _, m := range o.methods() {
+		if method, ok := m.(EpochScheduler); ok {
+			method.IncEpoch()
+		}
+	}
+	var decayed []Method
+	for _, g := range o.groups {
+		g.epoch++
+		if g.Decay == nil || containsMethod(decayed, g.Method) {
+			continue // a method shared by multiple groups is decayed once
+		}
+		decayed = append(decayed, g.Method)
+		g.decayLearningRate()
+	}
+}
+
+// TimeSteps returns the time steps of the methods of the optimizer (see
+// TimeStepper): the first is the one of the method of the optimizer, followed
+// by the ones of the param groups with a different method, in order.
+// The methods not implementing TimeStepper have time step 0.
+func (o *GradientDescent) TimeSteps() []int {
+	methods := o.methods()
+	steps := make([]int, len(methods))
+	for i, m := range methods {
+		if method, ok := m.(TimeStepper); ok {
+			steps[i] = method.CurrentTimeStep()
+		}
+	}
+	return steps
+}
+
+// SetTimeSteps restores the time steps returned by TimeSteps, e.g. to resume
+// a training. It panics if the number of steps doesn't match the methods.
+func (o *GradientDescent) SetTimeSteps(steps []int) {
+	methods := o.methods()
+	if len(steps) != len(methods) {
+		panic("gd: the number of time steps doesn't match the methods")
+	}
+	for i, m := range methods {
+		if method, ok := m.(TimeStepper); ok {
+			method.SetTimeStep(steps[i])
+		}
 	}
 }
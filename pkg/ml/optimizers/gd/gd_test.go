@@ -0,0 +1,59 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithGradientsCallback(t *testing.T) {
+	m := &groupsTestLayer{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{0})),
+	}
+	var grads [][]mat.Float
+	optimizer := NewOptimizer(&plainSGD{lr: 1}, nn.NewDefaultParamsIterator(m),
+		ClipGradByValue(0.5),
+		WithGradientsCallback(func(params []nn.Param) {
+			for _, param := range params {
+				if param.HasGrad() {
+					grads = append(grads, param.Grad().Clone().Data())
+				}
+			}
+		}),
+	)
+	m.W.PropagateGrad(mat.NewVecDense([]mat.Float{1, -2}))
+	optimizer.Optimize()
+	assert.Equal(t, [][]mat.Float{{1, -2}}, grads) // before the clipping
+	assert.Equal(t, []mat.Float{0.5, 2.5}, m.W.Value().Data())
+}
+
+func TestWithUpdatesCallback(t *testing.T) {
+	m := &groupsTestLayer{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})),
+		B: nn.NewParam(mat.NewVecDense([]mat.Float{0})),
+	}
+	var deltas []mat.Matrix
+	optimizer := NewOptimizer(&plainSGD{lr: 0.5}, nn.NewDefaultParamsIterator(m),
+		WithUpdatesCallback(func(params []nn.Param, ds []mat.Matrix) {
+			assert.Len(t, ds, len(params))
+			for i, param := range params {
+				if param == m.W {
+					deltas = append(deltas, ds[i])
+				} else {
+					assert.Nil(t, ds[i])
+				}
+			}
+		}),
+	)
+	m.W.PropagateGrad(mat.NewVecDense([]mat.Float{1, -2}))
+	optimizer.Optimize()
+	assert.Len(t, deltas, 1)
+	assert.Equal(t, []mat.Float{0.5, -1}, deltas[0].Data())
+}
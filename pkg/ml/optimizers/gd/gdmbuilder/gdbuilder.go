@@ -6,12 +6,14 @@ package gdmbuilder
 
 import (
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adafactor"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adagrad"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/lamb"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/radam"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/rmsprop"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/weightdecay"
 )
 
 // NewMethod returns a new gd.Method, chosen and initialized according to
@@ -19,6 +21,8 @@ import (
 // It panics if the config type is unknown or unsupported.
 func NewMethod(config gd.MethodConfig) gd.Method {
 	switch config := config.(type) {
+	case adafactor.Config:
+		return adafactor.New(config)
 	case adagrad.Config:
 		return adagrad.New(config)
 	case adam.Config:
@@ -31,6 +35,8 @@ func NewMethod(config gd.MethodConfig) gd.Method {
 		return lamb.New(config)
 	case sgd.Config:
 		return sgd.New(config)
+	case weightdecay.Config:
+		return weightdecay.New(config, NewMethod(config.Method))
 	default:
 		panic("gd: unknown method configuration")
 	}
@@ -0,0 +1,180 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/decay"
+)
+
+// ParamGroup configures the optimization of a subset of the parameters of a
+// model, e.g. to use a lower learning rate for the embeddings, to disable the
+// weight decay of the biases, or to freeze some layers.
+type ParamGroup struct {
+	// Patterns select the params whose name (see nn.ForEachNamedParam) matches
+	// at least one of the globs, in the syntax of path.Match. Since the names
+	// don't contain slashes, "*" matches any sequence of characters, e.g.
+	// "encoder.layers.*.norm.*". If empty, the params are selected by type only
+	// (all the remaining params, if Types is empty too).
+	Patterns []string
+	// Types, if not empty, restricts the group to the params of the given types.
+	Types []nn.ParamsType
+	// Method is the optimization method of the group (see gdmbuilder.NewMethod).
+	// If nil, the method of the optimizer is used.
+	Method Method
+	// WeightDecay, if not zero, adds WeightDecay * value to the gradient seen
+	// by the method (L2 regularization). The gradient of the params is left
+	// unchanged.
+	WeightDecay mat.Float
+	// Frozen params are not updated, and their gradients are discarded.
+	Frozen bool
+	// Decay, if not nil, decays the learning rate of Method at each new epoch.
+	// The Method must be set, and it must implement LearningRateSetter.
+	// A Method shared by multiple groups is decayed once per epoch, by the
+	// first group with a Decay.
+	Decay decay.Function
+}
+
+// paramGroup is the state of a ParamGroup in the optimizer.
+type paramGroup struct {
+	ParamGroup
+	epoch int
+}
+
+// WithParamGroups is an option to optimize the params of m (and of the
+// models nested in it) by group. Each param belongs to the first group it
+// matches; the params not matching any group are optimized with the method
+// of the optimizer, as usual.
+//
+// It panics if a pattern is malformed, or if a group with Decay has no
+// Method supporting it.
+func WithParamGroups(m nn.Model, groups ...ParamGroup) Option {
+	gs := make([]*paramGroup, len(groups))
+	for i, g := range groups {
+		for _, pattern := range g.Patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				panic(fmt.Sprintf("gd: invalid param group pattern %q: %v", pattern, err))
+			}
+		}
+		if g.Decay != nil {
+			if _, ok := g.Method.(LearningRateSetter); !ok {
+				panic("gd: the method of a param group with decay must implement LearningRateSetter")
+			}
+		}
+		gs[i] = &paramGroup{ParamGroup: g, epoch: 1}
+	}
+
+	groupOf := make(map[nn.Param]*paramGroup)
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		for _, g := range gs {
+			if g.matches(name, param) {
+				groupOf[param] = g
+				return
+			}
+		}
+	})
+
+	return func(o *GradientDescent) {
+		o.groups = gs
+		o.groupOf = groupOf
+	}
+}
+
+// matches reports whether the param with the given name belongs to the group.
+func (g *paramGroup) matches(name string, param nn.Param) bool {
+	if len(g.Types) > 0 && !containsParamsType(g.Types, param.Type()) {
+		return false
+	}
+	if len(g.Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range g.Patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsParamsType(types []nn.ParamsType, t nn.ParamsType) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+	return false
+}
+
+// decayLearningRate decays the learning rate of the method of the group for
+// the current epoch.
+func (g *paramGroup) decayLearningRate() {
+	method := g.Method.(LearningRateSetter)
+	method.SetLearningRate(g.Decay.Decay(method.LearningRate(), g.epoch))
+}
+
+// ParamGroupsState is the state of the param groups of an optimizer, e.g. to
+// save it in a checkpoint along with the params.
+type ParamGroupsState struct {
+	// Epochs are the epochs of the groups, in order.
+	Epochs []int
+	// LearningRates are the learning rates of the methods of the groups, in
+	// order, as decayed so far. They are zero for the groups without Decay.
+	LearningRates []mat.Float
+}
+
+// ParamGroupsState returns the state of the param groups, which is empty if
+// the optimizer has no groups.
+func (o *GradientDescent) ParamGroupsState() ParamGroupsState {
+	state := ParamGroupsState{
+		Epochs:        make([]int, len(o.groups)),
+		LearningRates: make([]mat.Float, len(o.groups)),
+	}
+	for i, g := range o.groups {
+		state.Epochs[i] = g.epoch
+		if g.Decay != nil {
+			state.LearningRates[i] = g.Method.(LearningRateSetter).LearningRate()
+		}
+	}
+	return state
+}
+
+// SetParamGroupsState restores a state returned by ParamGroupsState, e.g. to
+// resume a training. The optimizer must have the same number of groups.
+func (o *GradientDescent) SetParamGroupsState(state ParamGroupsState) error {
+	if len(state.Epochs) != len(o.groups) || len(state.LearningRates) != len(o.groups) {
+		return fmt.Errorf("gd: the state has %d param groups, the optimizer %d", len(state.Epochs), len(o.groups))
+	}
+	for i, g := range o.groups {
+		g.epoch = state.Epochs[i]
+		if g.Decay != nil {
+			g.Method.(LearningRateSetter).SetLearningRate(state.LearningRates[i])
+		}
+	}
+	return nil
+}
+
+// methods returns the distinct optimization methods used by o.
+func (o *GradientDescent) methods() []Method {
+	methods := []Method{o.method}
+	for _, g := range o.groups {
+		if g.Method != nil && !containsMethod(methods, g.Method) {
+			methods = append(methods, g.Method)
+		}
+	}
+	return methods
+}
+
+func containsMethod(methods []Method, m Method) bool {
+	for _, item := range methods {
+		if item == m {
+			return true
+		}
+	}
+	return false
+}
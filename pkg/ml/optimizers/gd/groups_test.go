@@ -0,0 +1,170 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+// plainSGD is a minimal method, returning lr * grad.
+type plainSGD struct {
+	lr       mat.Float
+	examples int
+}
+
+func (o *plainSGD) Label() int                      { return SGD }
+func (o *plainSGD) NewSupport(r, c int) *nn.Payload { return &nn.Payload{Label: SGD} }
+func (o *plainSGD) Delta(param nn.Param) mat.Matrix { return param.Grad().ProdScalar(o.lr) }
+func (o *plainSGD) LearningRate() mat.Float         { return o.lr }
+func (o *plainSGD) SetLearningRate(lr mat.Float)    { o.lr = lr }
+func (o *plainSGD) IncExample()                     { o.examples++ }
+
+type halveDecay struct{}
+
+func (halveDecay) Decay(lr mat.Float, t int) mat.Float { return lr / 2 }
+
+type groupsTestLayer struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+type groupsTestModel struct {
+	nn.BaseModel
+	Embeddings *groupsTestLayer
+	Head       *groupsTestLayer
+	Frozen     *groupsTestLayer
+}
+
+func newGroupsTestModel() *groupsTestModel {
+	layer := func() *groupsTestLayer {
+		return &groupsTestLayer{
+			W: nn.NewParam(mat.NewVecDense([]mat.Float{1, 2})),
+			B: nn.NewParam(mat.NewVecDense([]mat.Float{1})),
+		}
+	}
+	return &groupsTestModel{Embeddings: layer(), Head: layer(), Frozen: layer()}
+}
+
+func TestWithParamGroups(t *testing.T) {
+	m := newGroupsTestModel()
+	defaultMethod := &plainSGD{lr: 1}
+	embeddingsMethod := &plainSGD{lr: 0.1}
+	optimizer := NewOptimizer(defaultMethod, nn.NewDefaultParamsIterator(m),
+		WithParamGroups(m,
+			ParamGroup{Patterns: []string{"frozen.*"}, Frozen: true},
+			ParamGroup{Patterns: []string{"embeddings.*"}, Method: embeddingsMethod, Decay: halveDecay{}},
+			ParamGroup{Types: []nn.ParamsType{nn.Weights}, WeightDecay: 0.5},
+		),
+		ConcurrentComputations(1),
+	)
+
+	nn.ForEachParam(m, func(param nn.Param) {
+		param.PropagateGrad(mat.NewVecDense(make([]mat.Float, param.Value().Size())).AddScalar(1))
+	})
+	optimizer.Optimize()
+
+	assert.InDeltaSlice(t, []mat.Float{0.9, 1.9}, m.Embeddings.W.Value().Data(), 1e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.9}, m.Embeddings.B.Value().Data(), 1e-6)
+	// grad + 0.5 * value
+	assert.InDeltaSlice(t, []mat.Float{-0.5, 0}, m.Head.W.Value().Data(), 1e-6)
+	assert.InDeltaSlice(t, []mat.Float{0}, m.Head.B.Value().Data(), 1e-6)
+	assert.Equal(t, []mat.Float{1, 2}, m.Frozen.W.Value().Data())
+	assert.False(t, m.Frozen.W.HasGrad())
+
+	optimizer.IncExample()
+	assert.Equal(t, 1, defaultMethod.examples)
+	assert.Equal(t, 1, embeddingsMethod.examples)
+
+	optimizer.IncEpoch()
+	assert.Equal(t, mat.Float(0.05), embeddingsMethod.lr)
+	assert.Equal(t, mat.Float(1), defaultMethod.lr)
+}
+
+func TestWithParamGroups_WeightDecay(t *testing.T) {
+	m := newGroupsTestModel()
+	optimizer := NewOptimizer(&plainSGD{lr: 1}, nn.NewDefaultParamsIterator(m),
+		WithParamGroups(m, ParamGroup{Patterns: []string{"head.w"}, WeightDecay: 0.5}),
+	)
+	m.Head.W.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1}))
+	delta := optimizer.delta(m.Head.W)
+	assert.InDeltaSlice(t, []mat.Float{1.5, 2}, delta.Data(), 1e-6)
+	assert.Equal(t, []mat.Float{1, 1}, m.Head.W.Grad().Data(), "the gradient is not modified")
+}
+
+func TestWithParamGroups_SharedMethodDecay(t *testing.T) {
+	m := newGroupsTestModel()
+	method := &plainSGD{lr: 1}
+	optimizer := NewOptimizer(&plainSGD{lr: 1}, nn.NewDefaultParamsIterator(m),
+		WithParamGroups(m,
+			ParamGroup{Patterns: []string{"embeddings.*"}, Method: method, Decay: halveDecay{}},
+			ParamGroup{Patterns: []string{"head.*"}, Method: method, Decay: halveDecay{}},
+		),
+	)
+	optimizer.IncEpoch()
+	assert.Equal(t, mat.Float(0.5), method.lr)
+	optimizer.IncEpoch()
+	assert.Equal(t, mat.Float(0.25), method.lr)
+}
+
+func TestWithParamGroups_Panics(t *testing.T) {
+	m := newGroupsTestModel()
+	assert.Panics(t, func() { WithParamGroups(m, ParamGroup{Patterns: []string{"["}}) })
+	assert.Panics(t, func() { WithParamGroups(m, ParamGroup{Decay: halveDecay{}}) })
+}
+
+// stepper is a method with a time step.
+type stepper struct {
+	plainSGD
+	t int
+}
+
+func (o *stepper) CurrentTimeStep() int { return o.t }
+func (o *stepper) SetTimeStep(t int)    { o.t = t }
+
+func TestGradientDescent_TimeSteps(t *testing.T) {
+	m := newGroupsTestModel()
+	defaultMethod := &stepper{t: 3}
+	optimizer := NewOptimizer(defaultMethod, nn.NewDefaultParamsIterator(m),
+		WithParamGroups(m,
+			ParamGroup{Patterns: []string{"frozen.*"}, Method: &plainSGD{}},
+			ParamGroup{Patterns: []string{"head.*"}, Method: defaultMethod},
+		),
+	)
+	assert.Equal(t, []int{3, 0}, optimizer.TimeSteps())
+	optimizer.SetTimeSteps([]int{7, 1})
+	assert.Equal(t, 7, defaultMethod.t)
+	assert.Panics(t, func() { optimizer.SetTimeSteps([]int{1}) })
+}
+
+func TestGradientDescent_ParamGroupsState(t *testing.T) {
+	newOptimizer := func(method *plainSGD) *GradientDescent {
+		m := newGroupsTestModel()
+		return NewOptimizer(&plainSGD{lr: 1}, nn.NewDefaultParamsIterator(m),
+			WithParamGroups(m,
+				ParamGroup{Patterns: []string{"embeddings.*"}, Method: method, Decay: halveDecay{}},
+				ParamGroup{Patterns: []string{"head.*"}},
+			),
+		)
+	}
+	reference := newOptimizer(&plainSGD{lr: 1})
+	reference.IncEpoch()
+	reference.IncEpoch()
+	state := reference.ParamGroupsState()
+	assert.Equal(t, []int{3, 3}, state.Epochs)
+	assert.Equal(t, []mat.Float{0.25, 0}, state.LearningRates)
+
+	method := &plainSGD{lr: 1}
+	resumed := newOptimizer(method)
+	assert.NoError(t, resumed.SetParamGroupsState(state))
+	assert.Equal(t, mat.Float(0.25), method.lr)
+	assert.Equal(t, 3, resumed.groups[0].epoch)
+	assert.Error(t, resumed.SetParamGroupsState(ParamGroupsState{}))
+	assert.Empty(t, NewOptimizer(&plainSGD{}, nil).ParamGroupsState().Epochs)
+}
@@ -5,7 +5,7 @@
 package lamb
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
@@ -74,6 +74,19 @@ func (o *Lamb) Label() int {
 	return gd.Lamb
 }
 
+var _ gd.LearningRateSetter = &Lamb{}
+
+// LearningRate returns the current learning rate (StepSize).
+func (o *Lamb) LearningRate() mat.Float {
+	return o.StepSize
+}
+
+// SetLearningRate sets a new learning rate (StepSize).
+func (o *Lamb) SetLearningRate(lr mat.Float) {
+	o.StepSize = lr
+	o.updateAlpha()
+}
+
 const (
 	v    int = 0
 	m    int = 1
@@ -96,6 +109,19 @@ func (o *Lamb) NewSupport(r, c int) *nn.Payload {
 	}
 }
 
+var _ gd.TimeStepper = &Lamb{}
+
+// CurrentTimeStep returns the current time step.
+func (o *Lamb) CurrentTimeStep() int {
+	return o.TimeStep
+}
+
+// SetTimeStep sets the current time step.
+func (o *Lamb) SetTimeStep(t int) {
+	o.TimeStep = t
+	o.updateAlpha()
+}
+
 // IncExample beats the occurrence of a new example.
 func (o *Lamb) IncExample() {
 	o.TimeStep++
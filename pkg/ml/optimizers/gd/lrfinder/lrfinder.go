@@ -0,0 +1,220 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lrfinder implements the learning rate range test: a short training
+// in which the learning rate increases exponentially at each step, recording
+// the loss, to find the range of learning rates in which the model learns
+// quickly before the loss diverges.
+//
+// Reference:
+//     Cyclical Learning Rates for Training Neural Networks (Smith, 2017)
+//     https://arxiv.org/pdf/1506.01186.pdf
+package lrfinder
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/checkpoint"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/stats"
+)
+
+// Config provides configuration settings for the range test.
+type Config struct {
+	// StartLR is the learning rate of the first step.
+	StartLR mat.Float
+	// EndLR is the learning rate of the last step.
+	EndLR mat.Float
+	// Steps is the number of steps.
+	Steps int
+	// Smoothing is the smoothing factor of the exponential moving average of
+	// the loss (see stats.MovingAvg).
+	Smoothing mat.Float
+	// DivergenceFactor stops the test as soon as the smoothed loss is greater
+	// than DivergenceFactor times the minimum one. The losses can be negative
+	// too: the test stops when the smoothed loss exceeds the minimum by more
+	// than DivergenceFactor-1 times its absolute value. If 0, the test never
+	// stops early.
+	DivergenceFactor mat.Float
+}
+
+// NewDefaultConfig returns a new Config, with learning rates from 1e-7 to 10
+// in 100 steps.
+func NewDefaultConfig() Config {
+	return Config{
+		StartLR:          1e-7,
+		EndLR:            10,
+		Steps:            100,
+		Smoothing:        0.98,
+		DivergenceFactor: 4,
+	}
+}
+
+// LossFunc returns the loss of the given step of the test, e.g. of the next
+// batch of the training set. The processor is reified for training on a new graph.
+// The loss must not be nil.
+type LossFunc func(proc nn.Model, step int) ag.Node
+
+// Result contains the outcome of the range test.
+type Result struct {
+	// LearningRates are the learning rates of the steps.
+	LearningRates []mat.Float
+	// Losses are the losses of the steps.
+	Losses []mat.Float
+	// SmoothedLosses are the smoothed losses of the steps.
+	SmoothedLosses []mat.Float
+	// Suggested is the suggested learning rate, where the smoothed loss
+	// decreases the most steeply (with respect to the log of the learning rate).
+	Suggested mat.Float
+	// MinLossLR is the learning rate of the minimum smoothed loss. A common
+	// rule of thumb is to use a learning rate about ten times lower.
+	MinLossLR mat.Float
+}
+
+// Run runs the range test of the model m, optimized with the given method,
+// which must implement gd.LearningRateSetter. The optimizer options, if
+// any, are applied too (e.g. the gradient clipping).
+// At the end of the test, the values of the params, their payloads, the time
+// steps and the learning rate of the method are restored.
+func Run(m nn.Model, method gd.Method, loss LossFunc, config Config, opts ...gd.Option) (*Result, error) {
+	lrSetter, ok := method.(gd.LearningRateSetter)
+	if !ok {
+		return nil, fmt.Errorf("lrfinder: the method doesn't implement gd.LearningRateSetter")
+	}
+	if config.Steps < 2 || !(config.StartLR > 0 && config.StartLR < config.EndLR) {
+		return nil, fmt.Errorf("lrfinder: invalid configuration")
+	}
+	optimizer := gd.NewOptimizer(method, nn.NewDefaultParamsIterator(m), opts...)
+
+	restore, err := snapshot(m, optimizer)
+	if err != nil {
+		return nil, err
+	}
+	originalLR := lrSetter.LearningRate()
+	defer lrSetter.SetLearningRate(originalLR)
+
+	r := &Result{}
+	factor := mat.Pow(config.EndLR/config.StartLR, 1/mat.Float(config.Steps-1))
+	smoothed := &stats.MovingAvg{Beta: config.Smoothing}
+	minLoss := mat.Inf(1)
+	for step := 0; step < config.Steps; step++ {
+		lr := config.StartLR * mat.Pow(factor, mat.Float(step))
+		lrSetter.SetLearningRate(lr)
+		var value mat.Float
+		value, err = trainStep(m, optimizer, loss, step)
+		if err != nil {
+			break
+		}
+		smoothed.Add(value)
+
+		r.LearningRates = append(r.LearningRates, lr)
+		r.Losses = append(r.Losses, value)
+		r.SmoothedLosses = append(r.SmoothedLosses, smoothed.Mean)
+		if smoothed.Mean < minLoss {
+			minLoss = smoothed.Mean
+			r.MinLossLR = lr
+		}
+		if math.IsNaN(float64(value)) || (config.DivergenceFactor > 0 && diverged(smoothed.Mean, minLoss, config.DivergenceFactor)) {
+			break
+		}
+	}
+	r.Suggested = r.steepest()
+
+	if restoreErr := restore(); restoreErr != nil {
+		return nil, restoreErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// diverged reports whether the loss exceeds the minimum by more than factor-1
+// times its absolute value, i.e. whether loss > factor * minLoss for a
+// positive minimum.
+func diverged(loss, minLoss, factor mat.Float) bool {
+	return loss > minLoss+(factor-1)*mat.Abs(minLoss)
+}
+
+// trainStep performs a training step, returning the loss.
+func trainStep(m nn.Model, optimizer *gd.GradientDescent, loss LossFunc, step int) (mat.Float, error) {
+	g := ag.NewGraph()
+	defer g.Clear()
+	l := loss(nn.ReifyForTraining(m, g), step)
+	if l == nil {
+		return 0, fmt.Errorf("lrfinder: nil loss at step %d", step)
+	}
+	g.Backward(l)
+	optimizer.IncBatch()
+	optimizer.IncExample()
+	optimizer.Optimize()
+	return l.ScalarValue(), nil
+}
+
+// snapshot returns a function which restores the current state of the model
+// and of the optimizer. The state is copied, since the payloads are updated in place.
+func snapshot(m nn.Model, optimizer *gd.GradientDescent) (func() error, error) {
+	c, err := checkpoint.New(m, optimizer)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(c); err != nil {
+		return nil, err
+	}
+	return func() error {
+		restored := new(checkpoint.Checkpoint)
+		if err := gob.NewDecoder(buf).Decode(restored); err != nil {
+			return err
+		}
+		return restored.Restore(m, optimizer)
+	}, nil
+}
+
+// steepest returns the learning rate where the smoothed loss decreases the
+// most steeply, before its minimum.
+func (r *Result) steepest() mat.Float {
+	best := mat.Inf(1)
+	suggested := r.MinLossLR
+	for i := 1; i < len(r.SmoothedLosses); i++ {
+		if r.LearningRates[i] > r.MinLossLR {
+			break
+		}
+		slope := (r.SmoothedLosses[i] - r.SmoothedLosses[i-1]) /
+			(mat.Log(r.LearningRates[i]) - mat.Log(r.LearningRates[i-1]))
+		if slope < best {
+			best = slope
+			suggested = r.LearningRates[i]
+		}
+	}
+	return suggested
+}
+
+// WriteCSV writes the data to plot the loss as a function of the learning
+// rate, in CSV format, with the columns "lr", "loss" and "smoothed_loss".
+func (r *Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"lr", "loss", "smoothed_loss"}); err != nil {
+		return err
+	}
+	format := func(x mat.Float) string {
+		return strconv.FormatFloat(float64(x), 'g', -1, 64)
+	}
+	for i, lr := range r.LearningRates {
+		if err := cw.Write([]string{format(lr), format(r.Losses[i]), format(r.SmoothedLosses[i])}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
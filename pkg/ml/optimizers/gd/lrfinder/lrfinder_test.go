@@ -0,0 +1,127 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lrfinder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+var xs = []mat.Float{-1, -0.5, 0, 0.5, 1, 1.5, 2}
+
+// loss is the loss of y = 3x + 1 on the whole dataset.
+func loss(proc nn.Model, step int) ag.Node {
+	p := proc.(*testModel)
+	g := p.Graph()
+	var loss ag.Node
+	for _, x := range xs {
+		y := g.Add(g.Prod(p.W, g.NewScalar(x)), p.B)
+		loss = g.Add(loss, losses.MSE(g, y, g.NewScalar(3*x+1), false))
+	}
+	return g.DivScalar(loss, g.NewScalar(mat.Float(len(xs))))
+}
+
+func TestRun(t *testing.T) {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0.5)), B: nn.NewParam(mat.NewScalar(-0.5))}
+	method := sgd.New(sgd.NewConfig(0.001, 0.9, false))
+	config := NewDefaultConfig()
+	config.StartLR = 1e-5
+	config.EndLR = 10
+	config.Steps = 80
+
+	r, err := Run(m, method, loss, config)
+	require.NoError(t, err)
+
+	// the state is restored
+	assert.Equal(t, mat.Float(0.5), m.W.ScalarValue())
+	assert.Equal(t, mat.Float(-0.5), m.B.ScalarValue())
+	assert.Nil(t, m.W.Payload())
+	assert.Equal(t, mat.Float(0.001), method.LearningRate())
+
+	require.NotEmpty(t, r.LearningRates)
+	assert.Len(t, r.Losses, len(r.LearningRates))
+	assert.Len(t, r.SmoothedLosses, len(r.LearningRates))
+	assert.InDelta(t, 1e-5, r.LearningRates[0], 1e-9)
+	assert.InDelta(t, r.Losses[0], r.SmoothedLosses[0], 1e-5)
+	assert.Less(t, len(r.LearningRates), 80) // the loss diverged with the highest learning rates
+	assert.Less(t, float64(r.Suggested), float64(r.MinLossLR)+1e-9)
+	assert.Greater(t, float64(r.Suggested), 1e-3)
+
+	var sb strings.Builder
+	require.NoError(t, r.WriteCSV(&sb))
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	assert.Equal(t, "lr,loss,smoothed_loss", lines[0])
+	assert.Len(t, lines, len(r.LearningRates)+1)
+}
+
+func TestRun_Errors(t *testing.T) {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0)), B: nn.NewParam(mat.NewScalar(0))}
+	config := NewDefaultConfig()
+	config.EndLR = config.StartLR
+	_, err := Run(m, adam.New(adam.NewDefaultConfig()), loss, config)
+	assert.Error(t, err)
+}
+
+func TestRun_NegativeLoss(t *testing.T) {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0.5)), B: nn.NewParam(mat.NewScalar(-0.5))}
+	method := sgd.New(sgd.NewConfig(0.001, 0.9, false))
+	config := NewDefaultConfig()
+	config.StartLR = 1e-5
+	config.EndLR = 10
+	config.Steps = 80
+
+	negativeLoss := func(proc nn.Model, step int) ag.Node {
+		g := proc.Graph()
+		return g.SubScalar(loss(proc, step), g.NewScalar(10))
+	}
+	r, err := Run(m, method, negativeLoss, config)
+	require.NoError(t, err)
+	assert.Greater(t, len(r.LearningRates), 10)
+	assert.Less(t, len(r.LearningRates), 80)
+	assert.Greater(t, float64(r.Suggested), 1e-3)
+}
+
+func TestRun_NilLoss(t *testing.T) {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0.5)), B: nn.NewParam(mat.NewScalar(-0.5))}
+	method := sgd.New(sgd.NewConfig(0.001, 0.9, false))
+	config := NewDefaultConfig()
+	nilLoss := func(proc nn.Model, step int) ag.Node {
+		if step == 3 {
+			return nil
+		}
+		return loss(proc, step)
+	}
+	_, err := Run(m, method, nilLoss, config)
+	assert.Error(t, err)
+	assert.Equal(t, mat.Float(0.5), m.W.ScalarValue())
+	assert.Equal(t, mat.Float(0.001), method.LearningRate())
+}
+
+func TestRun_RestoresTimeStep(t *testing.T) {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0)), B: nn.NewParam(mat.NewScalar(0))}
+	method := adam.New(adam.NewDefaultConfig())
+	config := NewDefaultConfig()
+	config.Steps = 10
+	_, err := Run(m, method, loss, config)
+	require.NoError(t, err)
+	assert.Equal(t, 1, method.TimeStep)
+	assert.Nil(t, m.B.Payload())
+	assert.Equal(t, mat.Float(0), m.B.ScalarValue())
+}
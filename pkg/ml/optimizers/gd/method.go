@@ -5,7 +5,7 @@
 package gd
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
 
@@ -24,6 +24,11 @@ const (
 	RMSProp
 	// Lamb represents the Lamb gradient descent optimization method.
 	Lamb
+	// Adam8Bit represents the Adam gradient descent optimization method,
+	// storing the moments in blockwise-quantized 8-bit form.
+	Adam8Bit
+	// Adafactor represents the Adafactor gradient descent optimization method.
+	Adafactor
 )
 
 // MethodConfig is an empty interface implemented by the configuration structures of
@@ -46,11 +51,11 @@ func GetOrSetPayload(param nn.Param, m Method) *nn.Payload {
 	payload := param.Payload()
 	switch {
 	case payload == nil:
-		payload := m.NewSupport(param.Value().Dims())
+		payload := m.NewSupport(param.Dims())
 		param.SetPayload(payload)
 		return payload
 	case payload.Label == None:
-		payload := m.NewSupport(param.Value().Dims())
+		payload := m.NewSupport(param.Dims())
 		param.SetPayload(payload)
 		return payload
 	case payload.Label == m.Label():
@@ -59,3 +64,22 @@ func GetOrSetPayload(param nn.Param, m Method) *nn.Payload {
 		panic("gd: support structure non compatible with the optimization method")
 	}
 }
+
+// LearningRateSetter is implemented by the methods whose learning rate (or
+// step size) can be changed during the training, e.g. by a decay function.
+type LearningRateSetter interface {
+	// LearningRate returns the current learning rate.
+	LearningRate() mat.Float
+	// SetLearningRate sets a new learning rate.
+	SetLearningRate(lr mat.Float)
+}
+
+// TimeStepper is implemented by the methods whose update depends on the
+// number of steps performed (e.g. for the bias correction of the moments),
+// so that it can be restored to resume a training.
+type TimeStepper interface {
+	// CurrentTimeStep returns the current time step.
+	CurrentTimeStep() int
+	// SetTimeStep sets the current time step.
+	SetTimeStep(t int)
+}
@@ -5,7 +5,7 @@
 package radam
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
@@ -72,6 +72,18 @@ func (o *RAdam) Label() int {
 	return gd.RAdam
 }
 
+var _ gd.LearningRateSetter = &RAdam{}
+
+// LearningRate returns the current learning rate (StepSize).
+func (o *RAdam) LearningRate() mat.Float {
+	return o.StepSize
+}
+
+// SetLearningRate sets a new learning rate (StepSize).
+func (o *RAdam) SetLearningRate(lr mat.Float) {
+	o.StepSize = lr
+}
+
 const (
 	m    int = 0
 	v    int = 1
@@ -94,6 +106,18 @@ func (o *RAdam) NewSupport(r, c int) *nn.Payload {
 	}
 }
 
+var _ gd.TimeStepper = &RAdam{}
+
+// CurrentTimeStep returns the current time step.
+func (o *RAdam) CurrentTimeStep() int {
+	return o.TimeStep
+}
+
+// SetTimeStep sets the current time step.
+func (o *RAdam) SetTimeStep(t int) {
+	o.TimeStep = t
+}
+
 // IncBatch beats the occurrence of a new batch.
 func (o *RAdam) IncBatch() {
 	o.TimeStep++
@@ -5,7 +5,7 @@
 package rmsprop
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
@@ -58,6 +58,18 @@ func (o *RMSProp) Label() int {
 	return gd.RMSProp
 }
 
+var _ gd.LearningRateSetter = &RMSProp{}
+
+// LearningRate returns the current learning rate (LR).
+func (o *RMSProp) LearningRate() mat.Float {
+	return o.LR
+}
+
+// SetLearningRate sets a new learning rate (LR).
+func (o *RMSProp) SetLearningRate(lr mat.Float) {
+	o.LR = lr
+}
+
 const v = 0
 
 // NewSupport returns a new support structure with the given dimensions.
@@ -0,0 +1,212 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schedule
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+var (
+	_ Schedule = Constant(1)
+	_ Schedule = &Warmup{}
+	_ Schedule = &Linear{}
+	_ Schedule = &Polynomial{}
+	_ Schedule = &Cosine{}
+	_ Schedule = &OneCycle{}
+	_ Schedule = Product{}
+)
+
+// Constant is a schedule with a constant factor.
+type Constant mat.Float
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (c Constant) Factor(_ int) mat.Float {
+	return mat.Float(c)
+}
+
+// Warmup increases the factor linearly from 0 to the one of the wrapped
+// schedule, during the first steps:
+//     factor = step / steps * schedule.Factor(0)
+// then it follows the wrapped schedule, starting from its step 0.
+type Warmup struct {
+	steps    int
+	schedule Schedule
+}
+
+// NewWarmup returns a new Warmup of the given steps, followed by the schedule.
+// If the schedule is nil, the factor is 1 after the warmup.
+func NewWarmup(steps int, schedule Schedule) *Warmup {
+	if steps < 0 {
+		panic("schedule: the warmup steps must be >= 0")
+	}
+	if schedule == nil {
+		schedule = Constant(1)
+	}
+	return &Warmup{steps: steps, schedule: schedule}
+}
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (w *Warmup) Factor(step int) mat.Float {
+	if step < w.steps {
+		return mat.Float(step) / mat.Float(w.steps) * w.schedule.Factor(0)
+	}
+	return w.schedule.Factor(step - w.steps)
+}
+
+// Linear decreases the factor linearly from 1 to 0:
+//     factor = max(0, 1 - step / steps)
+type Linear struct {
+	steps int
+}
+
+// NewLinear returns a new Linear decay to zero in the given steps.
+func NewLinear(steps int) *Linear {
+	if steps <= 0 {
+		panic("schedule: the steps must be > 0")
+	}
+	return &Linear{steps: steps}
+}
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (l *Linear) Factor(step int) mat.Float {
+	if step >= l.steps {
+		return 0
+	}
+	return 1 - mat.Float(step)/mat.Float(l.steps)
+}
+
+// Polynomial decreases the factor from 1 to final:
+//     factor = (1 - final) * (1 - step / steps)^power + final
+// then it remains final.
+type Polynomial struct {
+	steps int
+	power mat.Float
+	final mat.Float
+}
+
+// NewPolynomial returns a new Polynomial decay.
+func NewPolynomial(steps int, power, final mat.Float) *Polynomial {
+	if steps <= 0 {
+		panic("schedule: the steps must be > 0")
+	}
+	return &Polynomial{steps: steps, power: power, final: final}
+}
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (p *Polynomial) Factor(step int) mat.Float {
+	if step >= p.steps {
+		return p.final
+	}
+	return (1-p.final)*mat.Pow(1-mat.Float(step)/mat.Float(p.steps), p.power) + p.final
+}
+
+// Cosine decreases the factor from 1 to min following a cosine curve
+// (Loshchilov and Hutter, 2017):
+//     factor = min + (1 - min) * (1 + cos(pi * t / period)) / 2
+// where t is the step within the current period. With restarts, a new
+// period begins when the previous one ends, each period multiplied by mult.
+// Without restarts, the factor remains min after the first period.
+type Cosine struct {
+	period   int
+	mult     int
+	min      mat.Float
+	restarts bool
+}
+
+// NewCosine returns a new Cosine decay, without restarts.
+func NewCosine(steps int, min mat.Float) *Cosine {
+	if steps <= 0 {
+		panic("schedule: the steps must be > 0")
+	}
+	return &Cosine{period: steps, mult: 1, min: min}
+}
+
+// NewCosineWithRestarts returns a new Cosine decay with warm restarts.
+func NewCosineWithRestarts(period, mult int, min mat.Float) *Cosine {
+	if period <= 0 {
+		panic("schedule: the period must be > 0")
+	}
+	if mult < 1 {
+		panic("schedule: the period multiplier must be >= 1")
+	}
+	return &Cosine{period: period, mult: mult, min: min, restarts: true}
+}
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (c *Cosine) Factor(step int) mat.Float {
+	period := c.period
+	if !c.restarts {
+		if step >= period {
+			return c.min
+		}
+	} else if c.mult == 1 {
+		step %= period
+	} else {
+		for step >= period {
+			step -= period
+			period *= c.mult
+		}
+	}
+	return cosineAnnealing(1, c.min, mat.Float(step)/mat.Float(period))
+}
+
+// cosineAnnealing returns the value between start and end, at the given
+// fraction of a half cosine period.
+func cosineAnnealing(start, end, fraction mat.Float) mat.Float {
+	return end + (start-end)*(1+mat.Cos(mat.Pi*fraction))/2
+}
+
+// OneCycle is the 1cycle policy (Smith and Topin, 2018): the factor
+// increases from 1/div to 1 during the first part of the steps, then it
+// decreases to 1/(div * finalDiv), both with cosine annealing.
+type OneCycle struct {
+	steps    int
+	upSteps  int
+	div      mat.Float
+	finalDiv mat.Float
+}
+
+// NewOneCycle returns a new OneCycle of the given steps, the first pctStart
+// of which increase the factor. Usual values are pctStart = 0.3, div = 25
+// and finalDiv = 1e4.
+func NewOneCycle(steps int, pctStart, div, finalDiv mat.Float) *OneCycle {
+	if steps <= 0 {
+		panic("schedule: the steps must be > 0")
+	}
+	if !(pctStart > 0 && pctStart < 1) {
+		panic("schedule: `pctStart` must be in the range (0, 1)")
+	}
+	upSteps := int(mat.Round(pctStart * mat.Float(steps)))
+	if upSteps < 1 {
+		upSteps = 1
+	}
+	return &OneCycle{steps: steps, upSteps: upSteps, div: div, finalDiv: finalDiv}
+}
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (o *OneCycle) Factor(step int) mat.Float {
+	start := 1 / o.div
+	if step < o.upSteps {
+		return cosineAnnealing(start, 1, mat.Float(step)/mat.Float(o.upSteps))
+	}
+	end := start / o.finalDiv
+	if step >= o.steps || o.steps == o.upSteps {
+		return end
+	}
+	return cosineAnnealing(1, end, mat.Float(step-o.upSteps)/mat.Float(o.steps-o.upSteps))
+}
+
+// Product is a schedule whose factor is the product of the factors of the
+// given schedules, e.g. to reduce on plateau a cosine schedule.
+type Product []Schedule
+
+// Factor returns the multiplier of the base learning rate at the given step.
+func (p Product) Factor(step int) mat.Float {
+	factor := mat.Float(1)
+	for _, s := range p {
+		factor *= s.Factor(step)
+	}
+	return factor
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schedule
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+var _ Schedule = &ReduceOnPlateau{}
+
+// PlateauConfig provides configuration settings for ReduceOnPlateau.
+type PlateauConfig struct {
+	// Maximize is true if the metric must be maximized (e.g. the accuracy),
+	// false if it must be minimized (e.g. the loss).
+	Maximize bool
+	// Reduction multiplies the factor when the metric stops improving.
+	Reduction mat.Float
+	// Patience is the number of observations without improvement after
+	// which the learning rate is reduced.
+	Patience int
+	// Threshold is the relative improvement required to consider a metric
+	// better than the best one.
+	Threshold mat.Float
+	// Cooldown is the number of observations to wait, after a reduction,
+	// before resuming the normal operation.
+	Cooldown int
+	// MinFactor is the lower bound of the factor.
+	MinFactor mat.Float
+}
+
+// NewDefaultPlateauConfig returns a new PlateauConfig with generically
+// reasonable default values, to minimize the metric.
+func NewDefaultPlateauConfig() PlateauConfig {
+	return PlateauConfig{
+		Reduction: 0.1,
+		Patience:  10,
+		Threshold: 1.0e-4,
+	}
+}
+
+// ReduceOnPlateau reduces the learning rate when a metric, such as the
+// validation loss, stops improving. Unlike the other schedules, the factor
+// doesn't depend on the step, but on the metrics observed so far (see
+// Observe).
+type ReduceOnPlateau struct {
+	PlateauConfig
+	factor     mat.Float
+	best       mat.Float
+	hasBest    bool
+	badCount   int
+	cooldown   int
+	reductions int
+}
+
+// NewReduceOnPlateau returns a new ReduceOnPlateau.
+func NewReduceOnPlateau(c PlateauConfig) *ReduceOnPlateau {
+	if !(c.Reduction > 0 && c.Reduction < 1) {
+		panic("schedule: the plateau `Reduction` must be in the range (0, 1)")
+	}
+	return &ReduceOnPlateau{PlateauConfig: c, factor: 1}
+}
+
+// Factor returns the current multiplier of the base learning rate,
+// regardless of the step.
+func (r *ReduceOnPlateau) Factor(_ int) mat.Float {
+	return r.factor
+}
+
+// Reductions returns the number of times the factor has been reduced.
+func (r *ReduceOnPlateau) Reductions() int {
+	return r.reductions
+}
+
+// Observe records a new value of the metric, reducing the factor if it
+// hasn't improved for more than Patience observations. It returns whether
+// the factor has been reduced, in which case the learning rates must be
+// updated (see Scheduler.Update).
+func (r *ReduceOnPlateau) Observe(metric mat.Float) bool {
+	if r.isBetter(metric) {
+		r.best, r.hasBest = metric, true
+		r.badCount = 0
+	} else {
+		r.badCount++
+	}
+	if r.cooldown > 0 {
+		r.cooldown--
+		r.badCount = 0
+	}
+	if r.badCount <= r.Patience {
+		return false
+	}
+	r.cooldown = r.Cooldown
+	r.badCount = 0
+	factor := mat.Max(r.factor*r.Reduction, r.MinFactor)
+	if factor == r.factor {
+		return false
+	}
+	r.factor = factor
+	r.reductions++
+	return true
+}
+
+func (r *ReduceOnPlateau) isBetter(metric mat.Float) bool {
+	if !r.hasBest {
+		return true
+	}
+	if r.Maximize {
+		return metric > r.best+mat.Abs(r.best)*r.Threshold
+	}
+	return metric < r.best-mat.Abs(r.best)*r.Threshold
+}
+
+// plateauState is the serializable state of a ReduceOnPlateau.
+type plateauState struct {
+	Factor     mat.Float
+	Best       mat.Float
+	HasBest    bool
+	BadCount   int
+	Cooldown   int
+	Reductions int
+}
+
+// MarshalBinary encodes the state of the schedule, i.e. the factor and the
+// metrics observed so far, so that it can be restored with UnmarshalBinary,
+// e.g. to resume a training. The configuration is not included.
+func (r *ReduceOnPlateau) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(plateauState{
+		Factor:     r.factor,
+		Best:       r.best,
+		HasBest:    r.hasBest,
+		BadCount:   r.badCount,
+		Cooldown:   r.cooldown,
+		Reductions: r.reductions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of the schedule encoded by
+// MarshalBinary. The learning rates must be updated afterwards (see
+// Scheduler.Update).
+func (r *ReduceOnPlateau) UnmarshalBinary(data []byte) error {
+	var state plateauState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	r.factor = state.Factor
+	r.best, r.hasBest = state.Best, state.HasBest
+	r.badCount = state.BadCount
+	r.cooldown = state.Cooldown
+	r.reductions = state.Reductions
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package schedule provides step-based learning rate schedules, which can be
+// applied to any gradient descent method implementing gd.LearningRateSetter.
+//
+// A Schedule returns a factor, which multiplies the base learning rate of
+// each method, so that the same schedule can drive param groups with
+// different learning rates. The schedules can be composed, e.g.:
+//     schedule.NewWarmup(1000, schedule.NewLinear(9000))
+// is the linear warmup followed by the linear decay to zero, usually used to
+// fine-tune the transformers.
+package schedule
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+)
+
+// Schedule is implemented by any learning rate schedule.
+type Schedule interface {
+	// Factor returns the multiplier of the base learning rate at the given
+	// step, starting from 0.
+	Factor(step int) mat.Float
+}
+
+var _ gd.BatchScheduler = &Scheduler{}
+
+// Scheduler sets the learning rate of one or more methods according to a
+// Schedule, at each step.
+type Scheduler struct {
+	schedule Schedule
+	methods  []gd.LearningRateSetter
+	baseLRs  []mat.Float
+	step     int
+}
+
+// New returns a new Scheduler, which takes the current learning rate of the
+// methods as base learning rates, and immediately sets the learning rate of
+// the step 0.
+func New(schedule Schedule, methods ...gd.LearningRateSetter) *Scheduler {
+	s := &Scheduler{
+		schedule: schedule,
+		methods:  methods,
+		baseLRs:  make([]mat.Float, len(methods)),
+	}
+	for i, method := range methods {
+		s.baseLRs[i] = method.LearningRate()
+	}
+	s.Update()
+	return s
+}
+
+// Step advances the schedule by one step (usually, an optimization step),
+// updating the learning rates.
+func (s *Scheduler) Step() {
+	s.step++
+	s.Update()
+}
+
+// IncBatch is an alias of Step, so that the scheduler can be beaten at each
+// batch together with the optimizer.
+func (s *Scheduler) IncBatch() {
+	s.Step()
+}
+
+// CurrentStep returns the current step.
+func (s *Scheduler) CurrentStep() int {
+	return s.step
+}
+
+// SetStep sets the current step, e.g. to resume a training, updating the
+// learning rates.
+func (s *Scheduler) SetStep(step int) {
+	s.step = step
+	s.Update()
+}
+
+// Update sets the learning rates of the current step. It must be called
+// explicitly only when the schedule depends on something else than the
+// step, such as ReduceOnPlateau.
+func (s *Scheduler) Update() {
+	factor := s.schedule.Factor(s.step)
+	for i, method := range s.methods {
+		method.SetLearningRate(s.baseLRs[i] * factor)
+	}
+}
+
+// Factor returns the current factor of the schedule.
+func (s *Scheduler) Factor() mat.Float {
+	return s.schedule.Factor(s.step)
+}
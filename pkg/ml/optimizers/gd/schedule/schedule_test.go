@@ -0,0 +1,130 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schedule
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+)
+
+func factors(s Schedule, steps ...int) []mat.Float {
+	out := make([]mat.Float, len(steps))
+	for i, step := range steps {
+		out[i] = s.Factor(step)
+	}
+	return out
+}
+
+func TestWarmupLinear(t *testing.T) {
+	s := NewWarmup(4, NewLinear(8))
+	assert.InDeltaSlice(t, []mat.Float{0, 0.25, 0.5, 0.75, 1, 0.875, 0.5, 0, 0},
+		factors(s, 0, 1, 2, 3, 4, 5, 8, 12, 100), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.5, 1}, factors(NewWarmup(2, nil), 1, 10), 1.0e-6)
+}
+
+func TestPolynomial(t *testing.T) {
+	s := NewPolynomial(10, 2, 0.1)
+	assert.InDeltaSlice(t, []mat.Float{1, 0.1 + 0.9*0.25, 0.1, 0.1},
+		factors(s, 0, 5, 10, 11), 1.0e-6)
+}
+
+func TestCosine(t *testing.T) {
+	s := NewCosine(10, 0.1)
+	assert.InDeltaSlice(t, []mat.Float{1, 0.55, 0.1, 0.1}, factors(s, 0, 5, 10, 20), 1.0e-6)
+
+	s = NewCosineWithRestarts(10, 1, 0)
+	assert.InDeltaSlice(t, []mat.Float{1, 0.5, 1, 0.5}, factors(s, 0, 5, 10, 25), 1.0e-6)
+
+	// periods of 4, 8, 16...
+	s = NewCosineWithRestarts(4, 2, 0)
+	assert.InDeltaSlice(t, []mat.Float{1, 0.5, 1, 0.5, 1}, factors(s, 0, 2, 4, 8, 12), 1.0e-6)
+}
+
+func TestOneCycle(t *testing.T) {
+	s := NewOneCycle(100, 0.3, 25, 1e4)
+	assert.InDeltaSlice(t, []mat.Float{0.04, 0.52, 1, 0.5 + 0.04/1e4/2, 0.04 / 1e4, 0.04 / 1e4},
+		factors(s, 0, 15, 30, 65, 100, 120), 1.0e-6)
+}
+
+func TestProduct(t *testing.T) {
+	s := Product{Constant(0.5), NewLinear(10)}
+	assert.InDelta(t, 0.25, s.Factor(5), 1.0e-6)
+}
+
+func TestScheduler(t *testing.T) {
+	embeddings := sgd.New(sgd.NewConfig(0.01, 0, false))
+	head := adam.New(adam.NewDefaultConfig())
+	s := New(NewWarmup(10, NewLinear(10)), embeddings, head)
+	assert.Equal(t, mat.Float(0), embeddings.LR)
+	assert.Equal(t, mat.Float(0), head.StepSize)
+
+	for i := 0; i < 5; i++ {
+		s.Step()
+	}
+	assert.Equal(t, 5, s.CurrentStep())
+	assert.InDelta(t, 0.005, embeddings.LR, 1.0e-6)
+	assert.InDelta(t, 0.005, embeddings.Alpha, 1.0e-6)
+	assert.InDelta(t, 0.0005, head.StepSize, 1.0e-6)
+
+	s.SetStep(15)
+	assert.InDelta(t, 0.005, embeddings.LR, 1.0e-6)
+	s.IncBatch()
+	assert.InDelta(t, 0.004, embeddings.LR, 1.0e-6)
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	c := NewDefaultPlateauConfig()
+	c.Reduction = 0.5
+	c.Patience = 1
+	c.Cooldown = 1
+	c.MinFactor = 0.2
+	plateau := NewReduceOnPlateau(c)
+	method := sgd.New(sgd.NewConfig(0.1, 0, false))
+	s := New(Product{NewCosine(100, 0), plateau}, method)
+
+	reduced := []bool{}
+	for _, loss := range []mat.Float{1, 0.9, 0.95, 0.92, 0.91, 0.93, 0.94, 0.95, 0.96, 0.97, 0.98, 0.99, 1} {
+		reduced = append(reduced, plateau.Observe(loss))
+	}
+	assert.Equal(t, []bool{
+		false, false, false, true, // 2 observations without improvement
+		false, // cooldown
+		false, true,
+		false, false, true, // down to MinFactor
+		false, false, false,
+	}, reduced)
+	assert.Equal(t, 3, plateau.Reductions())
+	assert.InDelta(t, 0.2, plateau.Factor(0), 1.0e-6)
+
+	s.Update()
+	assert.InDelta(t, 0.02, method.LR, 1.0e-6)
+}
+
+func TestReduceOnPlateau_MarshalBinary(t *testing.T) {
+	c := NewDefaultPlateauConfig()
+	c.Reduction = 0.5
+	c.Patience = 1
+	c.Cooldown = 1
+	reference := NewReduceOnPlateau(c)
+	for _, loss := range []mat.Float{1, 0.9, 0.95, 0.92, 0.91} {
+		reference.Observe(loss)
+	}
+	data, err := reference.MarshalBinary()
+	assert.NoError(t, err)
+
+	resumed := NewReduceOnPlateau(c)
+	assert.NoError(t, resumed.UnmarshalBinary(data))
+	assert.Equal(t, reference.Factor(0), resumed.Factor(0))
+	assert.Equal(t, reference.Reductions(), resumed.Reductions())
+	for _, loss := range []mat.Float{0.93, 0.94, 0.95, 0.96, 0.97} {
+		assert.Equal(t, reference.Observe(loss), resumed.Observe(loss))
+	}
+	assert.Equal(t, reference.Factor(0), resumed.Factor(0))
+	assert.Error(t, resumed.UnmarshalBinary([]byte{1, 2, 3}))
+}
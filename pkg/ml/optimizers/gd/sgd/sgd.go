@@ -5,7 +5,7 @@
 package sgd
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
@@ -47,6 +47,19 @@ func (o *SGD) Label() int {
 	return gd.SGD
 }
 
+var _ gd.LearningRateSetter = &SGD{}
+
+// LearningRate returns the current learning rate (LR).
+func (o *SGD) LearningRate() mat.Float {
+	return o.LR
+}
+
+// SetLearningRate sets a new learning rate (LR).
+func (o *SGD) SetLearningRate(lr mat.Float) {
+	o.LR = lr
+	o.Alpha = lr
+}
+
 const (
 	v     int = 0
 	buf   int = 1
@@ -0,0 +1,170 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package weightdecay implements the decoupled weight decay (Loshchilov and
+// Hutter, 2019) as a wrapper of any gradient descent method: the weights are
+// shrunk directly at each update, instead of adding an L2 penalty to the
+// gradients, which would be rescaled by adaptive methods such as Adam.
+package weightdecay
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+)
+
+var _ gd.MethodConfig = &Config{}
+
+// Config provides configuration settings for the decoupled weight decay.
+type Config struct {
+	gd.MethodConfig
+	// Method is the configuration of the wrapped method (see gdmbuilder.NewMethod).
+	Method gd.MethodConfig
+	// Lambda is the weight decay rate.
+	Lambda mat.Float
+}
+
+// NewConfig returns a new Config, wrapping the given method.
+func NewConfig(method gd.MethodConfig, lambda mat.Float) Config {
+	if lambda < 0 {
+		panic("weightdecay: `lambda` must be >= 0")
+	}
+	return Config{
+		Method: method,
+		Lambda: lambda,
+	}
+}
+
+// NewAdamWConfig returns the Config of AdamW, i.e. Adam with decoupled weight decay.
+func NewAdamWConfig(stepSize, beta1, beta2, epsilon, lambda mat.Float) Config {
+	return NewConfig(adam.NewConfig(stepSize, beta1, beta2, epsilon), lambda)
+}
+
+var (
+	_ gd.Method             = &WeightDecay{}
+	_ gd.LearningRateSetter = &WeightDecay{}
+	_ gd.ExampleScheduler   = &WeightDecay{}
+	_ gd.BatchScheduler     = &WeightDecay{}
+	_ gd.EpochScheduler     = &WeightDecay{}
+	_ gd.TimeStepper        = &WeightDecay{}
+)
+
+// WeightDecay wraps a gd.Method, adding the decoupled weight decay to its
+// deltas:
+//     d = method.Delta(param) + lr * lambda * weights
+// where lr is the current learning rate of the method, if it implements
+// gd.LearningRateSetter, or 1 otherwise. This way, the weight decay follows
+// the learning rate schedule.
+//
+// The weight decay has no state: the payload of a param is the one of the
+// wrapped method, serialized along with the param.
+type WeightDecay struct {
+	Config
+	method gd.Method
+}
+
+// New returns a new WeightDecay wrapping the given method, which should
+// have been created from c.Method.
+func New(c Config, method gd.Method) *WeightDecay {
+	return &WeightDecay{
+		Config: c,
+		method: method,
+	}
+}
+
+// Method returns the wrapped method.
+func (o *WeightDecay) Method() gd.Method {
+	return o.method
+}
+
+// Label returns the label of the wrapped method, which shares the payload.
+func (o *WeightDecay) Label() int {
+	return o.method.Label()
+}
+
+// NewSupport returns the support structure of the wrapped method.
+func (o *WeightDecay) NewSupport(r, c int) *nn.Payload {
+	return o.method.NewSupport(r, c)
+}
+
+// Delta returns the difference between the current params and where the method wants it to be.
+// The delta is a new matrix taken from the workspace pool, since the one of
+// the wrapped method may be part of its state (e.g. the velocity of SGD).
+func (o *WeightDecay) Delta(param nn.Param) mat.Matrix {
+	delta := o.method.Delta(param)
+	var d mat.Matrix
+	param.ReadValue(func(weights mat.Matrix) {
+		d = o.calcDelta(delta, weights)
+	})
+	return d
+}
+
+// d = delta + weights * lr * lambda
+func (o *WeightDecay) calcDelta(delta, weights mat.Matrix) mat.Matrix {
+	return weights.ProdScalar(o.rate()).AddInPlace(delta)
+}
+
+// rate returns the weight decay rate, scaled by the learning rate.
+func (o *WeightDecay) rate() mat.Float {
+	if method, ok := o.method.(gd.LearningRateSetter); ok {
+		return method.LearningRate() * o.Lambda
+	}
+	return o.Lambda
+}
+
+// LearningRate returns the learning rate of the wrapped method, or 0 if it
+// doesn't implement gd.LearningRateSetter.
+func (o *WeightDecay) LearningRate() mat.Float {
+	if method, ok := o.method.(gd.LearningRateSetter); ok {
+		return method.LearningRate()
+	}
+	return 0
+}
+
+// SetLearningRate sets the learning rate of the wrapped method, if it
+// implements gd.LearningRateSetter.
+func (o *WeightDecay) SetLearningRate(lr mat.Float) {
+	if method, ok := o.method.(gd.LearningRateSetter); ok {
+		method.SetLearningRate(lr)
+	}
+}
+
+// IncExample beats the occurrence of a new example.
+func (o *WeightDecay) IncExample() {
+	if method, ok := o.method.(gd.ExampleScheduler); ok {
+		method.IncExample()
+	}
+}
+
+// IncBatch beats the occurrence of a new batch.
+func (o *WeightDecay) IncBatch() {
+	if method, ok := o.method.(gd.BatchScheduler); ok {
+		method.IncBatch()
+	}
+}
+
+// IncEpoch beats the occurrence of a new epoch.
+func (o *WeightDecay) IncEpoch() {
+	if method, ok := o.method.(gd.EpochScheduler); ok {
+		method.IncEpoch()
+	}
+}
+
+// CurrentTimeStep returns the time step of the wrapped method, or 0 if it
+// doesn't implement gd.TimeStepper.
+func (o *WeightDecay) CurrentTimeStep() int {
+	if method, ok := o.method.(gd.TimeStepper); ok {
+		return method.CurrentTimeStep()
+	}
+	return 0
+}
+
+// SetTimeStep sets the time step of the wrapped method, if it implements
+// gd.TimeStepper.
+func (o *WeightDecay) SetTimeStep(t int) {
+	if method, ok := o.method.(gd.TimeStepper); ok {
+		method.SetTimeStep(t)
+	}
+}
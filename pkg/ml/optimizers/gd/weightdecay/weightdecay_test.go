@@ -0,0 +1,78 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package weightdecay
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightDecay_Delta(t *testing.T) {
+	c := NewConfig(sgd.NewConfig(0.1, 0.9, false), 0.01)
+	method := New(c, sgd.New(c.Method.(sgd.Config)))
+
+	param := nn.NewParam(mat.NewVecDense([]mat.Float{1, -2, 3}))
+	for i := 0; i < 2; i++ {
+		param.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1, 1}))
+		param.ApplyDelta(method.Delta(param))
+		param.ZeroGrad()
+	}
+	// momentum: v1 = 0.1, v2 = 0.9*0.1 + 0.1 = 0.19
+	// decay: w *= (1 - 0.1 * 0.01) at each step, on the weights before the update
+	w1 := []mat.Float{1 - 0.1 - 0.001, -2 - 0.1 + 0.002, 3 - 0.1 - 0.003}
+	for i, w := range w1 {
+		w1[i] = w - 0.19 - 0.001*w
+	}
+	assert.InDeltaSlice(t, w1, param.Value().Data(), 1.0e-6)
+
+	// the momentum must not include the weight decay
+	assert.InDeltaSlice(t, []mat.Float{0.19, 0.19, 0.19}, param.Payload().Data[0].Data(), 1.0e-6)
+	assert.Len(t, param.Payload().Data, 2, "the payload is the one of SGD")
+	assert.Equal(t, gd.SGD, param.Payload().Label)
+}
+
+func TestWeightDecay_PayloadRoundTrip(t *testing.T) {
+	c := NewAdamWConfig(0.001, 0.9, 0.999, 1.0e-8, 0.1)
+	method := New(c, adam.New(c.Method.(adam.Config)))
+	param := nn.NewParam(mat.NewVecDense([]mat.Float{1, 2}))
+	param.PropagateGrad(mat.NewVecDense([]mat.Float{0.5, -0.5}))
+	param.ApplyDelta(method.Delta(param))
+
+	data, err := param.Payload().MarshalBinary()
+	require.NoError(t, err)
+	payload := new(nn.Payload)
+	require.NoError(t, payload.UnmarshalBinary(data))
+	assert.Equal(t, gd.Adam, payload.Label)
+	require.Len(t, payload.Data, len(param.Payload().Data))
+	for i := range payload.Data {
+		assert.Equal(t, param.Payload().Data[i].Data(), payload.Data[i].Data())
+	}
+
+	// the payload is the one of the wrapped method
+	other := nn.NewParam(mat.NewVecDense([]mat.Float{1, 2}))
+	other.PropagateGrad(mat.NewVecDense([]mat.Float{0.5, -0.5}))
+	other.SetPayload(adam.New(c.Method.(adam.Config)).NewSupport(2, 1))
+	assert.NotPanics(t, func() { method.Delta(other) })
+	assert.Len(t, other.Payload().Data, len(param.Payload().Data))
+}
+
+func TestWeightDecay_LearningRate(t *testing.T) {
+	c := NewAdamWConfig(0.001, 0.9, 0.999, 1.0e-8, 0.1)
+	inner := adam.New(c.Method.(adam.Config))
+	method := New(c, inner)
+	method.SetLearningRate(0.002)
+	assert.Equal(t, mat.Float(0.002), inner.StepSize)
+	assert.InDelta(t, 0.0002, method.rate(), 1.0e-9)
+
+	method.IncExample()
+	assert.Equal(t, 2, inner.TimeStep)
+}
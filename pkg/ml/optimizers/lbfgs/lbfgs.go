@@ -0,0 +1,384 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lbfgs implements the limited-memory BFGS optimizer, a quasi-Newton
+// method for the full-batch optimization of the params of small models, or of
+// convex heads such as a logistic classifier on frozen features, which
+// converges in much fewer iterations than the first-order methods of gd.
+//
+// The search direction is computed from the last few changes of the params
+// and of the gradients, and the step along it is found by a line search which
+// satisfies the strong Wolfe conditions.
+//
+// Reference: "Numerical Optimization", chapters 3 and 7 (Nocedal & Wright, 2006)
+package lbfgs
+
+import (
+	"math"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+// LossFunc returns the loss to minimize, e.g. on the whole training set. The
+// processor is reified for training on a new graph. The loss must be a
+// deterministic function of the params (e.g. without dropout).
+type LossFunc func(proc nn.Model) ag.Node
+
+// Config provides configuration settings for an LBFGS optimizer.
+type Config struct {
+	// HistorySize is the number of the last changes of the params and of the
+	// gradients used to approximate the inverse Hessian.
+	HistorySize int
+	// MaxIterations is the maximum number of iterations of Optimize.
+	MaxIterations int
+	// MaxLineSearchSteps is the maximum number of evaluations of the loss of
+	// each line search.
+	MaxLineSearchSteps int
+	// GradientTolerance stops the optimization when the maximum absolute
+	// value of the gradients is below it.
+	GradientTolerance mat.Float
+	// ChangeTolerance stops the optimization when the change of the loss, or
+	// of the params, is below it.
+	ChangeTolerance mat.Float
+	// C1 is the constant of the sufficient decrease (Armijo) condition.
+	C1 mat.Float
+	// C2 is the constant of the curvature condition.
+	C2 mat.Float
+}
+
+// NewDefaultConfig returns a new Config with the usual settings.
+func NewDefaultConfig() Config {
+	return Config{
+		HistorySize:        10,
+		MaxIterations:      100,
+		MaxLineSearchSteps: 25,
+		GradientTolerance:  1e-5,
+		ChangeTolerance:    1e-9,
+		C1:                 1e-4,
+		C2:                 0.9,
+	}
+}
+
+// Result is the outcome of Optimize.
+type Result struct {
+	// Iterations is the number of iterations.
+	Iterations int
+	// Evaluations is the number of evaluations of the loss and of the gradients.
+	Evaluations int
+	// Loss is the final loss.
+	Loss mat.Float
+	// GradMax is the final maximum absolute value of the gradients.
+	GradMax mat.Float
+	// Converged is true if the optimization stopped because of the tolerances,
+	// instead of the maximum number of iterations or of a failed line search.
+	Converged bool
+}
+
+// LBFGS implements the limited-memory BFGS optimizer. The params which don't
+// require gradients are left untouched.
+type LBFGS struct {
+	Config
+	model     nn.Model
+	loss      LossFunc
+	graphOpts []ag.GraphOption
+	// s and y are the last changes of the params and of the gradients.
+	s, y [][]mat.Float
+	// rho are the inverses of the dot products of s and y.
+	rho         []mat.Float
+	evaluations int
+}
+
+// New returns a new LBFGS optimizer of the params of the model m, which
+// minimizes the loss. The graph options are used to create the graph of each
+// evaluation of the loss.
+func New(m nn.Model, loss LossFunc, config Config, graphOpts ...ag.GraphOption) *LBFGS {
+	if config.HistorySize < 1 {
+		panic("lbfgs: the history size must be greater than zero")
+	}
+	return &LBFGS{
+		Config:    config,
+		model:     m,
+		loss:      loss,
+		graphOpts: graphOpts,
+	}
+}
+
+// Reset clears the history of the changes, e.g. after the params have been
+// modified from outside of the optimizer.
+func (o *LBFGS) Reset() {
+	o.s, o.y, o.rho = nil, nil, nil
+}
+
+// Optimize performs up to MaxIterations iterations, leaving the model with
+// the best params found. The history is retained across the calls.
+func (o *LBFGS) Optimize() Result {
+	o.evaluations = 0
+	x := nn.DumpParamsVector(o.model).Data()
+	f, g := o.evaluate(x)
+	r := Result{Loss: f, GradMax: maxAbs(g)}
+	if r.GradMax <= o.GradientTolerance {
+		r.Converged = true
+		r.Evaluations = o.evaluations
+		return r
+	}
+
+	for r.Iterations < o.MaxIterations {
+		r.Iterations++
+		d := o.direction(g)
+		gtd := dot(g, d)
+		if gtd > -1e-20 {
+			// not a descent direction: restart from the steepest descent
+			o.Reset()
+			d = scale(g, -1)
+			gtd = dot(g, d)
+		}
+		t := mat.Float(1)
+		if len(o.s) == 0 {
+			// the first step is scaled, since there is no curvature information yet
+			t = mat.Float(math.Min(1, 1/float64(sumAbs(g))))
+		}
+
+		t, fNew, gNew := o.lineSearch(x, f, g, d, gtd, t)
+		if fNew > f || t == 0 {
+			break // the line search failed
+		}
+		s := scale(d, t)
+		xNew := add(x, s)
+		o.update(s, sub(gNew, g))
+
+		fChange := mat.Abs(fNew - f)
+		x, f, g = xNew, fNew, gNew
+		r.Loss, r.GradMax = f, maxAbs(g)
+		if r.GradMax <= o.GradientTolerance || fChange < o.ChangeTolerance || maxAbs(s) < o.ChangeTolerance {
+			r.Converged = true
+			break
+		}
+	}
+	nn.LoadParamsVector(o.model, mat.NewVecDense(x))
+	r.Evaluations = o.evaluations
+	return r
+}
+
+// evaluate returns the loss and the gradients with the params x.
+func (o *LBFGS) evaluate(x []mat.Float) (mat.Float, []mat.Float) {
+	o.evaluations++
+	nn.LoadParamsVector(o.model, mat.NewVecDense(x))
+	nn.ZeroGrad(o.model)
+	g := ag.NewGraph(o.graphOpts...)
+	defer g.Clear()
+	loss := o.loss(nn.ReifyForTraining(o.model, g))
+	g.Backward(loss)
+
+	grads := make([]mat.Float, 0, len(x))
+	nn.ForEachParam(o.model, func(param nn.Param) {
+		if param.HasGrad() {
+			grads = append(grads, param.Grad().Data()...)
+		} else {
+			grads = append(grads, make([]mat.Float, param.Value().Size())...)
+		}
+	})
+	nn.ZeroGrad(o.model)
+	value := loss.ScalarValue()
+	if math.IsNaN(float64(value)) {
+		value = mat.Inf(1)
+	}
+	return value, grads
+}
+
+// direction returns the search direction -Hg, with the two-loop recursion.
+func (o *LBFGS) direction(g []mat.Float) []mat.Float {
+	k := len(o.s)
+	q := scale(g, 1)
+	alpha := make([]mat.Float, k)
+	for i := k - 1; i >= 0; i-- {
+		alpha[i] = o.rho[i] * dot(o.s[i], q)
+		axpy(-alpha[i], o.y[i], q)
+	}
+	if k > 0 {
+		// the initial Hessian approximation is a scaled identity
+		gamma := 1 / (o.rho[k-1] * dot(o.y[k-1], o.y[k-1]))
+		q = scale(q, gamma)
+	}
+	for i := 0; i < k; i++ {
+		beta := o.rho[i] * dot(o.y[i], q)
+		axpy(alpha[i]-beta, o.s[i], q)
+	}
+	return scale(q, -1)
+}
+
+// update adds the changes of the params and of the gradients to the history,
+// unless the curvature condition doesn't hold.
+func (o *LBFGS) update(s, y []mat.Float) {
+	ys := dot(y, s)
+	if ys <= 1e-10 {
+		return
+	}
+	if len(o.s) == o.HistorySize {
+		o.s, o.y, o.rho = o.s[1:], o.y[1:], o.rho[1:]
+	}
+	o.s = append(o.s, s)
+	o.y = append(o.y, y)
+	o.rho = append(o.rho, 1/ys)
+}
+
+// lineSearch finds a step t along the direction d which satisfies the strong
+// Wolfe conditions, returning it with the loss and the gradients at x+td.
+// If the conditions are not met within MaxLineSearchSteps evaluations, it
+// returns the best step found.
+func (o *LBFGS) lineSearch(x []mat.Float, f0 mat.Float, g0, d []mat.Float, gtd0, t mat.Float) (mat.Float, mat.Float, []mat.Float) {
+	phi := func(t mat.Float) (mat.Float, []mat.Float, mat.Float) {
+		f, g := o.evaluate(add(x, scale(d, t)))
+		return f, g, dot(g, d)
+	}
+	sufficient := func(t, f mat.Float) bool { return f <= f0+o.C1*t*gtd0 }
+	curvature := func(gtd mat.Float) bool { return mat.Abs(gtd) <= -o.C2*gtd0 }
+
+	// the bracketing phase
+	tPrev, fPrev, gPrev, gtdPrev := mat.Float(0), f0, g0, gtd0
+	var lo, hi point
+	steps := 0
+	for {
+		f, g, gtd := phi(t)
+		steps++
+		current := point{t: t, f: f, g: g, gtd: gtd}
+		if !sufficient(t, f) || (steps > 1 && f >= fPrev) {
+			lo, hi = point{t: tPrev, f: fPrev, g: gPrev, gtd: gtdPrev}, current
+			break
+		}
+		if curvature(gtd) {
+			return t, f, g
+		}
+		if gtd >= 0 {
+			lo, hi = current, point{t: tPrev, f: fPrev, g: gPrev, gtd: gtdPrev}
+			break
+		}
+		if steps >= o.MaxLineSearchSteps {
+			return t, f, g
+		}
+		// extrapolate, within [t + 0.01(t - tPrev), 10t]
+		next := cubicMinimizer(tPrev, fPrev, gtdPrev, t, f, gtd, t+0.01*(t-tPrev), 10*t)
+		tPrev, fPrev, gPrev, gtdPrev = t, f, g, gtd
+		t = next
+	}
+
+	// the zoom phase: lo satisfies the sufficient decrease, and the minimizer is between lo and hi
+	for steps < o.MaxLineSearchSteps {
+		if mat.Abs(hi.t-lo.t)*maxAbs(d) < o.ChangeTolerance {
+			break
+		}
+		a, b := lo.t, hi.t
+		if a > b {
+			a, b = b, a
+		}
+		margin := 0.1 * (b - a)
+		t := cubicMinimizer(lo.t, lo.f, lo.gtd, hi.t, hi.f, hi.gtd, a+margin, b-margin)
+		f, g, gtd := phi(t)
+		steps++
+		current := point{t: t, f: f, g: g, gtd: gtd}
+		if !sufficient(t, f) || f >= lo.f {
+			hi = current
+			continue
+		}
+		if curvature(gtd) {
+			return t, f, g
+		}
+		if gtd*(hi.t-lo.t) >= 0 {
+			hi = lo
+		}
+		lo = current
+	}
+	if lo.t == 0 {
+		return 0, f0, g0
+	}
+	return lo.t, lo.f, lo.g
+}
+
+// point is an evaluated step of the line search.
+type point struct {
+	t, f, gtd mat.Float
+	g         []mat.Float
+}
+
+// cubicMinimizer returns the minimizer of the cubic interpolation of the
+// function at t1 and t2, given its values and derivatives, clamped within
+// [min, max]. If the cubic has no minimizer, it returns the midpoint.
+func cubicMinimizer(t1, f1, d1, t2, f2, d2, min, max mat.Float) mat.Float {
+	x1, y1, g1 := float64(t1), float64(f1), float64(d1)
+	x2, y2, g2 := float64(t2), float64(f2), float64(d2)
+	lo, hi := float64(min), float64(max)
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	e1 := g1 + g2 - 3*(y1-y2)/(x1-x2)
+	radicand := e1*e1 - g1*g2
+	if radicand < 0 || math.IsNaN(radicand) || math.IsInf(radicand, 0) {
+		return mat.Float((lo + hi) / 2)
+	}
+	e2 := math.Sqrt(radicand)
+	if x1 > x2 {
+		e2 = -e2
+	}
+	t := x2 - (x2-x1)*((g2+e2-e1)/(g2-g1+2*e2))
+	if math.IsNaN(t) || math.IsInf(t, 0) {
+		return mat.Float((lo + hi) / 2)
+	}
+	return mat.Float(math.Min(math.Max(t, lo), hi))
+}
+
+func dot(a, b []mat.Float) mat.Float {
+	sum := mat.Float(0)
+	for i, v := range a {
+		sum += v * b[i]
+	}
+	return sum
+}
+
+// axpy adds alpha*x to y.
+func axpy(alpha mat.Float, x, y []mat.Float) {
+	for i, v := range x {
+		y[i] += alpha * v
+	}
+}
+
+func scale(a []mat.Float, alpha mat.Float) []mat.Float {
+	result := make([]mat.Float, len(a))
+	for i, v := range a {
+		result[i] = alpha * v
+	}
+	return result
+}
+
+func add(a, b []mat.Float) []mat.Float {
+	result := make([]mat.Float, len(a))
+	for i, v := range a {
+		result[i] = v + b[i]
+	}
+	return result
+}
+
+func sub(a, b []mat.Float) []mat.Float {
+	result := make([]mat.Float, len(a))
+	for i, v := range a {
+		result[i] = v - b[i]
+	}
+	return result
+}
+
+func maxAbs(a []mat.Float) mat.Float {
+	max := mat.Float(0)
+	for _, v := range a {
+		max = mat.Max(max, mat.Abs(v))
+	}
+	return max
+}
+
+func sumAbs(a []mat.Float) mat.Float {
+	sum := mat.Float(0)
+	for _, v := range a {
+		sum += mat.Abs(v)
+	}
+	return sum
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lbfgs
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rosenbrockModel struct {
+	nn.BaseModel
+	A nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:weights"`
+}
+
+// loss is the Rosenbrock function, with the minimum in (1, 1).
+func (m *rosenbrockModel) loss() ag.Node {
+	g := m.Graph()
+	x := g.Sub(g.NewScalar(1), m.A)
+	y := g.Sub(m.B, g.Square(m.A))
+	return g.Add(g.Square(x), g.ProdScalar(g.Square(y), g.NewScalar(100)))
+}
+
+func TestLBFGS_Rosenbrock(t *testing.T) {
+	m := &rosenbrockModel{
+		A: nn.NewParam(mat.NewScalar(-1.2)),
+		B: nn.NewParam(mat.NewScalar(1)),
+	}
+	config := NewDefaultConfig()
+	config.GradientTolerance = 1e-4
+	o := New(m, func(proc nn.Model) ag.Node {
+		return proc.(*rosenbrockModel).loss()
+	}, config)
+	r := o.Optimize()
+
+	assert.True(t, r.Converged)
+	assert.Less(t, r.Iterations, 100)
+	assert.Greater(t, r.Evaluations, r.Iterations)
+	assert.Less(t, float64(r.Loss), 1e-6)
+	assert.InDelta(t, 1, m.A.Value().Scalar(), 1e-3)
+	assert.InDelta(t, 1, m.B.Value().Scalar(), 1e-3)
+	assert.False(t, m.A.HasGrad())
+}
+
+func TestLBFGS_LinearRegression(t *testing.T) {
+	// y = 2*x1 - 3*x2 + 0.5*x3 + 1
+	xs := [][]mat.Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 1, 1}, {-1, 2, 0.5}, {2, -1, 3}}
+	model := linear.New(3, 1)
+	o := New(model, func(proc nn.Model) ag.Node {
+		g := proc.Graph()
+		var sum ag.Node
+		for _, x := range xs {
+			y := proc.(*linear.Model).Forward(g.NewVariable(mat.NewVecDense(x), false))[0]
+			target := g.NewScalar(2*x[0] - 3*x[1] + 0.5*x[2] + 1)
+			sum = g.Add(sum, losses.MSE(g, y, target, false))
+		}
+		return sum
+	}, NewDefaultConfig())
+	r := o.Optimize()
+
+	assert.True(t, r.Converged)
+	assert.Less(t, r.Iterations, 20)
+	assert.InDeltaSlice(t, []mat.Float{2, -3, 0.5}, model.W.Value().Data(), 1e-3)
+	assert.InDeltaSlice(t, []mat.Float{1}, model.B.Value().Data(), 1e-3)
+
+	// already at the minimum
+	r = o.Optimize()
+	assert.True(t, r.Converged)
+	assert.LessOrEqual(t, r.Iterations, 1)
+}
+
+func TestLBFGS_LogisticRegression_FrozenParams(t *testing.T) {
+	// a logistic classifier, with a frozen bias
+	xs := [][]mat.Float{{1, 2}, {2, 1}, {-1, -2}, {-2, -1}, {1, -1}, {-1, 1}, {0.5, 0.2}, {-0.3, -0.6}}
+	labels := []mat.Float{1, 1, 0, 0, 1, 0, 0, 1}
+	model := linear.New(2, 1)
+	model.B.Value().SetData([]mat.Float{0.1})
+	model.B.SetRequiresGrad(false)
+
+	const l2 = 0.1
+	o := New(model, func(proc nn.Model) ag.Node {
+		g := proc.Graph()
+		m := proc.(*linear.Model)
+		loss := g.ProdScalar(g.ReduceSum(g.Square(m.W)), g.NewScalar(l2))
+		for i, x := range xs {
+			p := g.Sigmoid(m.Forward(g.NewVariable(mat.NewVecDense(x), false))[0])
+			var logLikelihood ag.Node
+			if labels[i] == 1 {
+				logLikelihood = g.Log(p)
+			} else {
+				logLikelihood = g.Log(g.ReverseSub(p, g.NewScalar(1)))
+			}
+			loss = g.Sub(loss, logLikelihood)
+		}
+		return loss
+	}, NewDefaultConfig())
+	r := o.Optimize()
+
+	require.True(t, r.Converged)
+	assert.Less(t, float64(r.GradMax), 1e-4)
+	assert.Equal(t, mat.Float(0.1), model.B.Value().Scalar())
+	assert.Greater(t, float64(model.W.Value().AtVec(0)), 0.0)
+}
+
+func TestCubicMinimizer(t *testing.T) {
+	// f(t) = (t - 2)^2
+	assert.InDelta(t, 2, cubicMinimizer(0, 4, -4, 3, 1, 2, 0, 3), 1e-6)
+	// clamped
+	assert.InDelta(t, 1.5, cubicMinimizer(0, 4, -4, 3, 1, 2, 0, 1.5), 1e-6)
+	// no minimizer: the midpoint
+	assert.InDelta(t, 1, cubicMinimizer(0, 0, 1, 1, 0.9, 1, 0, 2), 1e-6)
+}
+
+func TestNew(t *testing.T) {
+	config := NewDefaultConfig()
+	config.HistorySize = 0
+	assert.Panics(t, func() { New(linear.New(1, 1), nil, config) })
+}
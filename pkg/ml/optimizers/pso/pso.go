@@ -0,0 +1,285 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pso
+
+import (
+	"math"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+)
+
+// ParticleSwarm implements the particle swarm optimization, a derivative-free
+// method for the optimization of functions over continuous spaces. Each
+// particle of the swarm moves through the search space, attracted by the best
+// position it has found and by the best position found by its neighbors.
+//
+// To optimize the params of a model (neuroevolution), use the vector of
+// nn.DumpParamsVector as the initial position, and load the solutions to
+// evaluate with nn.LoadParamsVector.
+//
+// References:
+//     "Particle swarm optimization" (Kennedy & Eberhart, 1995)
+//     "The particle swarm - explosion, stability, and convergence in a
+//     multidimensional complex space" (Clerc & Kennedy, 2002)
+type ParticleSwarm struct {
+	// The initial configuration
+	Config
+	// The swarm of particles
+	swarm *Swarm
+	// The fitness function to minimize
+	fitnessFunc func(solution mat.Matrix) mat.Float
+	// Method to call after finding a new best solution (can be nil)
+	onNewBest func(solution *ScoredVector)
+	// The current best solution (can be nil)
+	bestSolution *ScoredVector
+	// Optimization state
+	state  *State
+	rndGen *rand.LockedRand
+}
+
+// State represents a status of the optimization process.
+type State struct {
+	// The current generation
+	CurGeneration int
+	// The number of evaluations of the fitness function
+	Evaluations int
+}
+
+// Config provides configuration settings for a ParticleSwarm optimizer.
+type Config struct {
+	// The number of particles of the swarm
+	PopulationSize int
+	// The size of the dense vector
+	VectorSize int
+	// The maximum number of generations, i.e. moves of the swarm
+	MaxGenerations int
+	// The weight of the previous velocity (e.g. 0.7298)
+	Inertia mat.Float
+	// The acceleration towards the best position of the particle (e.g. 1.49618)
+	Cognitive mat.Float
+	// The acceleration towards the best position of the neighbors (e.g. 1.49618)
+	Social mat.Float
+	// The number of neighbors on each side of a particle, in a ring topology.
+	// If 0, all the particles are neighbors (global topology).
+	NeighborhoodSize int
+	// The (positive) bound of the positions. If 0, the positions are not bounded.
+	Bound mat.Float
+	// The maximum absolute value of each component of the velocities. If 0,
+	// the velocities are not clamped.
+	MaxVelocity mat.Float
+	// The center of the initial positions (e.g. the params of a model). If
+	// nil, the zero vector is used.
+	InitialPosition mat.Matrix
+	// The initial positions are sampled uniformly within this distance, on
+	// each dimension, from the center; the initial velocities within this
+	// distance from zero.
+	InitialRadius mat.Float
+	// The random seed
+	Seed uint64
+}
+
+// ScoredVector is a pair which associates a Score to a Vector corresponding to a specific solution.
+type ScoredVector struct {
+	Vector mat.Matrix
+	Score  mat.Float
+}
+
+// Particle is a particle of the swarm.
+type Particle struct {
+	// The current position
+	Position mat.Matrix
+	// The current velocity
+	Velocity mat.Matrix
+	// The score of the current position
+	Score mat.Float
+	// The best position found by the particle
+	BestPosition mat.Matrix
+	// The score of the best position
+	BestScore mat.Float
+}
+
+// Swarm is the population of particles.
+type Swarm struct {
+	Particles []*Particle
+}
+
+// NewOptimizer returns a new ParticleSwarm ready to optimize your problem.
+func NewOptimizer(
+	config Config,
+	fitness func(solution mat.Matrix) mat.Float,
+	onNewBest func(solution *ScoredVector),
+) *ParticleSwarm {
+	if config.VectorSize < 1 {
+		panic("pso: the vector size must be greater than zero")
+	}
+	if config.PopulationSize < 2 {
+		panic("pso: the population size must be at least 2")
+	}
+	if 2*config.NeighborhoodSize >= config.PopulationSize {
+		config.NeighborhoodSize = 0 // all the particles are neighbors anyway
+	}
+	center := mat.NewEmptyVecDense(config.VectorSize)
+	if config.InitialPosition != nil {
+		if config.InitialPosition.Size() != config.VectorSize {
+			panic("pso: the initial position doesn't match the vector size")
+		}
+		center.SetData(config.InitialPosition.Data())
+	}
+	rndGen := rand.NewLockedRand(config.Seed)
+	return &ParticleSwarm{
+		Config:      config,
+		swarm:       newRandomSwarm(config, center, rndGen),
+		fitnessFunc: fitness,
+		onNewBest:   onNewBest,
+		state:       &State{},
+		rndGen:      rndGen,
+	}
+}
+
+func newRandomSwarm(config Config, center mat.Matrix, rndGen *rand.LockedRand) *Swarm {
+	particles := make([]*Particle, config.PopulationSize)
+	for i := range particles {
+		position := mat.NewEmptyVecDense(config.VectorSize)
+		initializers.Uniform(position, -config.InitialRadius, config.InitialRadius, rndGen)
+		position.AddInPlace(center)
+		if config.Bound > 0 {
+			position.ClipInPlace(-config.Bound, config.Bound)
+		}
+		velocity := mat.NewEmptyVecDense(config.VectorSize)
+		initializers.Uniform(velocity, -config.InitialRadius, config.InitialRadius, rndGen)
+		particles[i] = &Particle{
+			Position:     position,
+			Velocity:     velocity,
+			Score:        mat.Inf(1),
+			BestPosition: position.Clone(),
+			BestScore:    mat.Inf(1),
+		}
+	}
+	return &Swarm{Particles: particles}
+}
+
+// Optimize performs the particle swarm optimization process.
+func (o *ParticleSwarm) Optimize() {
+	for g := 0; g < o.MaxGenerations; g++ {
+		o.Step()
+	}
+}
+
+// Step evaluates the particles, the first time, and then moves them and
+// evaluates their new positions.
+func (o *ParticleSwarm) Step() {
+	if o.state.Evaluations > 0 {
+		o.moveParticles()
+	}
+	o.evaluateParticles()
+	o.checkForBetterSolution()
+	o.state.CurGeneration++
+}
+
+// Best returns the best solution found so far, or nil.
+func (o *ParticleSwarm) Best() *ScoredVector {
+	return o.bestSolution
+}
+
+// Swarm returns the swarm of particles.
+func (o *ParticleSwarm) Swarm() *Swarm {
+	return o.swarm
+}
+
+// State returns the state of the optimization process.
+func (o *ParticleSwarm) State() *State {
+	return o.state
+}
+
+// moveParticles updates the velocity and the position of each particle.
+func (o *ParticleSwarm) moveParticles() {
+	particles := o.swarm.Particles
+	// the best neighbors are found before moving, so that the update is synchronous
+	bestNeighbors := make([]mat.Matrix, len(particles))
+	for i := range particles {
+		bestNeighbors[i] = particles[o.swarm.FindBestNeighbor(i, o.NeighborhoodSize)].BestPosition
+	}
+	for i, p := range particles {
+		for j := 0; j < o.VectorSize; j++ {
+			x := p.Position.AtVec(j)
+			r1, r2 := mat.Float(o.rndGen.Float()), mat.Float(o.rndGen.Float())
+			v := o.Inertia*p.Velocity.AtVec(j) +
+				o.Cognitive*r1*(p.BestPosition.AtVec(j)-x) +
+				o.Social*r2*(bestNeighbors[i].AtVec(j)-x)
+			if o.MaxVelocity > 0 && v > o.MaxVelocity {
+				v = o.MaxVelocity
+			} else if o.MaxVelocity > 0 && v < -o.MaxVelocity {
+				v = -o.MaxVelocity
+			}
+			p.Velocity.SetVec(j, v)
+		}
+		p.Position.AddInPlace(p.Velocity)
+		if o.Bound > 0 {
+			p.Position.ClipInPlace(-o.Bound, o.Bound)
+		}
+	}
+}
+
+// evaluateParticles evaluates the fitness of the current position of each
+// particle, updating its best position if better.
+func (o *ParticleSwarm) evaluateParticles() {
+	for _, p := range o.swarm.Particles {
+		p.Score = o.fitnessFunc(p.Position)
+		if math.IsNaN(float64(p.Score)) {
+			p.Score = mat.Inf(1)
+		}
+		o.state.Evaluations++
+		if p.Score < p.BestScore {
+			p.BestScore = p.Score
+			p.BestPosition = p.Position.Clone()
+		}
+	}
+}
+
+// checkForBetterSolution compares the overall best solution with the best positions of the particles, updating it if better.
+func (o *ParticleSwarm) checkForBetterSolution() {
+	best := o.swarm.Particles[o.swarm.FindBest()]
+	if o.bestSolution == nil || best.BestScore < o.bestSolution.Score {
+		o.bestSolution = &ScoredVector{
+			Vector: best.BestPosition.Clone(),
+			Score:  best.BestScore,
+		}
+		if o.onNewBest != nil {
+			o.onNewBest(o.bestSolution)
+		}
+	}
+}
+
+// FindBest returns the index of the particle with the best BestScore.
+func (s *Swarm) FindBest() int {
+	best := 0
+	for i, p := range s.Particles {
+		if p.BestScore < s.Particles[best].BestScore {
+			best = i
+		}
+	}
+	return best
+}
+
+// FindBestNeighbor returns the index of the particle with the best BestScore
+// among the windowSize neighbors on each side of the given particle, in a
+// ring topology, and the particle itself. If windowSize is 0, all the
+// particles are neighbors.
+func (s *Swarm) FindBestNeighbor(index, windowSize int) int {
+	if windowSize == 0 {
+		return s.FindBest()
+	}
+	size := len(s.Particles)
+	best := index
+	for k := -windowSize; k <= windowSize; k++ {
+		i := ((index+k)%size + size) % size
+		if s.Particles[i].BestScore < s.Particles[best].BestScore {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pso
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() Config {
+	return Config{
+		PopulationSize: 20,
+		VectorSize:     3,
+		MaxGenerations: 200,
+		Inertia:        0.7298,
+		Cognitive:      1.49618,
+		Social:         1.49618,
+		Bound:          5,
+		MaxVelocity:    2,
+		InitialRadius:  5,
+		Seed:           1,
+	}
+}
+
+// sphere has the minimum in (1, -2, 3, ...).
+func sphere(x mat.Matrix) mat.Float {
+	sum := mat.Float(0)
+	for i, v := range x.Data() {
+		target := mat.Float(i + 1)
+		if i%2 == 1 {
+			target = -target
+		}
+		sum += (v - target) * (v - target)
+	}
+	return sum
+}
+
+func TestParticleSwarm(t *testing.T) {
+	for _, neighbors := range []int{0, 2} {
+		config := newTestConfig()
+		config.NeighborhoodSize = neighbors
+		var scores []mat.Float
+		o := NewOptimizer(config, sphere, func(solution *ScoredVector) {
+			scores = append(scores, solution.Score)
+		})
+		o.Optimize()
+
+		require.NotNil(t, o.Best())
+		assert.InDeltaSlice(t, []mat.Float{1, -2, 3}, o.Best().Vector.Data(), 1e-2, "neighbors: %d", neighbors)
+		assert.Equal(t, 200, o.State().CurGeneration)
+		assert.Equal(t, 200*20, o.State().Evaluations)
+		for i := 1; i < len(scores); i++ {
+			assert.Less(t, float64(scores[i]), float64(scores[i-1]))
+		}
+		for _, p := range o.Swarm().Particles {
+			assert.GreaterOrEqual(t, float64(p.Score), float64(p.BestScore))
+			assert.GreaterOrEqual(t, float64(p.BestScore), float64(o.Best().Score))
+			for _, v := range p.Velocity.Data() {
+				assert.LessOrEqual(t, float64(mat.Abs(v)), 2.0)
+			}
+			for _, x := range p.Position.Data() {
+				assert.LessOrEqual(t, float64(mat.Abs(x)), 5.0)
+			}
+		}
+	}
+}
+
+func TestParticleSwarm_Neuroevolution(t *testing.T) {
+	// y = 2*x1 - 3*x2 + 1
+	xs := [][]mat.Float{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {-1, 2}, {2, -1}}
+	model := linear.New(2, 1)
+	loss := func(solution mat.Matrix) mat.Float {
+		nn.LoadParamsVector(model, solution)
+		sum := mat.Float(0)
+		for _, x := range xs {
+			y := model.W.Value().Mul(mat.NewVecDense(x)).Add(model.B.Value()).Scalar()
+			diff := y - (2*x[0] - 3*x[1] + 1)
+			sum += diff * diff
+		}
+		return sum
+	}
+
+	initial := nn.DumpParamsVector(model)
+	config := newTestConfig()
+	config.VectorSize = initial.Size()
+	config.InitialPosition = initial
+	o := NewOptimizer(config, loss, nil)
+	o.Optimize()
+
+	nn.LoadParamsVector(model, o.Best().Vector)
+	assert.InDeltaSlice(t, []mat.Float{2, -3}, model.W.Value().Data(), 1e-2)
+	assert.InDeltaSlice(t, []mat.Float{1}, model.B.Value().Data(), 1e-2)
+}
+
+func TestSwarm_FindBestNeighbor(t *testing.T) {
+	s := &Swarm{}
+	for _, score := range []mat.Float{5, 1, 4, 3, 6, 2} {
+		s.Particles = append(s.Particles, &Particle{BestScore: score})
+	}
+	assert.Equal(t, 1, s.FindBest())
+	assert.Equal(t, 1, s.FindBestNeighbor(0, 0))
+	assert.Equal(t, 1, s.FindBestNeighbor(0, 1))
+	assert.Equal(t, 5, s.FindBestNeighbor(4, 1))
+	assert.Equal(t, 1, s.FindBestNeighbor(5, 2)) // wraps around
+	assert.Equal(t, 3, s.FindBestNeighbor(3, 1))
+}
+
+func TestNewOptimizer(t *testing.T) {
+	config := newTestConfig()
+	config.InitialPosition = mat.NewVecDense([]mat.Float{1, 2, 3})
+	config.InitialRadius = 0.5
+	config.NeighborhoodSize = 10 // the whole swarm
+	o := NewOptimizer(config, sphere, nil)
+	assert.Equal(t, 0, o.NeighborhoodSize)
+	require.Len(t, o.Swarm().Particles, 20)
+	for _, p := range o.Swarm().Particles {
+		for j, x := range p.Position.Data() {
+			assert.InDelta(t, float64(j+1), float64(x), 0.5)
+		}
+	}
+
+	assert.Panics(t, func() { NewOptimizer(Config{VectorSize: 0, PopulationSize: 2}, sphere, nil) })
+	assert.Panics(t, func() { NewOptimizer(Config{VectorSize: 2, PopulationSize: 1}, sphere, nil) })
+	config.InitialPosition = mat.NewVecDense([]mat.Float{1, 2})
+	assert.Panics(t, func() { NewOptimizer(config, sphere, nil) })
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package paramserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/paramserver/grpcapi"
+	"google.golang.org/grpc"
+)
+
+// ErrStaleGradients is returned by Client.Push if the gradients have been
+// discarded by the server, being computed on too old params.
+var ErrStaleGradients = errors.New("paramserver: stale gradients")
+
+// Client synchronizes the model of a worker with a Server.
+type Client struct {
+	client    grpcapi.ParameterServerClient
+	worker    int
+	model     nn.Model
+	version   int
+	mode      Mode
+	staleness int
+}
+
+// NewClient returns a new Client of the given worker, synchronizing the
+// model m. Call Pull before the first training step.
+func NewClient(cc grpc.ClientConnInterface, worker int, m nn.Model) *Client {
+	return &Client{
+		client: grpcapi.NewParameterServerClient(cc),
+		worker: worker,
+		model:  m,
+	}
+}
+
+// Version returns the version of the params of the model.
+func (c *Client) Version() int {
+	return c.version
+}
+
+// Pull replaces the params of the model with the ones of the server.
+func (c *Client) Pull(ctx context.Context) error {
+	stream, err := c.client.Pull(ctx, &grpcapi.PullRequest{Worker: int32(c.worker)})
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	var last *grpcapi.PullReply
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		buf.Write(reply.Chunk)
+		last = reply
+	}
+	if last == nil {
+		return errors.New("paramserver: empty pull reply")
+	}
+	entries, err := nn.ReadStateDict(buf)
+	if err != nil {
+		return err
+	}
+	if _, err := nn.LoadStateDictEntries(c.model, entries, true); err != nil {
+		return err
+	}
+	c.version = int(last.Version)
+	c.mode = modeFromProto(last.Mode)
+	c.staleness = int(last.Staleness)
+	return nil
+}
+
+// Push sends the gradients of the params of the model to the server, and
+// zeros them. Then, it pulls the params if they are out of date: always in
+// Synchronous mode, once the update of all the workers is done; if they
+// are more than Staleness updates behind the server, in BoundedStaleness mode.
+// If the server discarded the gradients, it returns ErrStaleGradients, after
+// pulling the params.
+func (c *Client) Push(ctx context.Context) error {
+	stream, err := c.client.Push(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&grpcapi.PushRequest{Worker: int32(c.worker), Version: int64(c.version)}); err != nil {
+		return err
+	}
+	nn.ForEachNamedParam(c.model, func(name string, param nn.Param) {
+		if err != nil || !param.HasGrad() {
+			return
+		}
+		buf := new(bytes.Buffer)
+		if err = mat.MarshalBinaryMatrix(param.Grad(), buf); err != nil {
+			return
+		}
+		for _, chunk := range chunks(buf.Bytes()) {
+			err = stream.Send(&grpcapi.PushRequest{
+				Worker:  int32(c.worker),
+				Version: int64(c.version),
+				Name:    name,
+				Chunk:   chunk,
+			})
+			if err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	nn.ForEachParam(c.model, func(param nn.Param) {
+		param.ZeroGrad()
+	})
+	if c.mode == Synchronous || int(reply.Version)-c.version > c.staleness || !reply.Applied {
+		if err := c.Pull(ctx); err != nil {
+			return err
+		}
+	}
+	if !reply.Applied {
+		return ErrStaleGradients
+	}
+	return nil
+}
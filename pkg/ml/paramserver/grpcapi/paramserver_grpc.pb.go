@@ -0,0 +1,202 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ParameterServerClient is the client API for ParameterServer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ParameterServerClient interface {
+	// Sends a request of the params of the model, streamed in chunks.
+	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (ParameterServer_PullClient, error)
+	// Streams the gradients computed by a worker, in chunks, and waits for the outcome.
+	Push(ctx context.Context, opts ...grpc.CallOption) (ParameterServer_PushClient, error)
+}
+
+type parameterServerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewParameterServerClient(cc grpc.ClientConnInterface) ParameterServerClient {
+	return &parameterServerClient{cc}
+}
+
+func (c *parameterServerClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (ParameterServer_PullClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ParameterServer_ServiceDesc.Streams[0], "/paramserver.grpcapi.ParameterServer/Pull", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &parameterServerPullClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ParameterServer_PullClient interface {
+	Recv() (*PullReply, error)
+	grpc.ClientStream
+}
+
+type parameterServerPullClient struct {
+	grpc.ClientStream
+}
+
+func (x *parameterServerPullClient) Recv() (*PullReply, error) {
+	m := new(PullReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *parameterServerClient) Push(ctx context.Context, opts ...grpc.CallOption) (ParameterServer_PushClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ParameterServer_ServiceDesc.Streams[1], "/paramserver.grpcapi.ParameterServer/Push", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &parameterServerPushClient{stream}
+	return x, nil
+}
+
+type ParameterServer_PushClient interface {
+	Send(*PushRequest) error
+	CloseAndRecv() (*PushReply, error)
+	grpc.ClientStream
+}
+
+type parameterServerPushClient struct {
+	grpc.ClientStream
+}
+
+func (x *parameterServerPushClient) Send(m *PushRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *parameterServerPushClient) CloseAndRecv() (*PushReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PushReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParameterServerServer is the server API for ParameterServer service.
+// All implementations must embed UnimplementedParameterServerServer
+// for forward compatibility
+type ParameterServerServer interface {
+	// Sends a request of the params of the model, streamed in chunks.
+	Pull(*PullRequest, ParameterServer_PullServer) error
+	// Streams the gradients computed by a worker, in chunks, and waits for the outcome.
+	Push(ParameterServer_PushServer) error
+	mustEmbedUnimplementedParameterServerServer()
+}
+
+// UnimplementedParameterServerServer must be embedded to have forward compatible implementations.
+type UnimplementedParameterServerServer struct {
+}
+
+func (UnimplementedParameterServerServer) Pull(*PullRequest, ParameterServer_PullServer) error {
+	return status.Errorf(codes.Unimplemented, "method Pull not implemented")
+}
+func (UnimplementedParameterServerServer) Push(ParameterServer_PushServer) error {
+	return status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedParameterServerServer) mustEmbedUnimplementedParameterServerServer() {}
+
+// UnsafeParameterServerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ParameterServerServer will
+// result in compilation errors.
+type UnsafeParameterServerServer interface {
+	mustEmbedUnimplementedParameterServerServer()
+}
+
+func RegisterParameterServerServer(s grpc.ServiceRegistrar, srv ParameterServerServer) {
+	s.RegisterService(&ParameterServer_ServiceDesc, srv)
+}
+
+func _ParameterServer_Pull_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ParameterServerServer).Pull(m, &parameterServerPullServer{stream})
+}
+
+type ParameterServer_PullServer interface {
+	Send(*PullReply) error
+	grpc.ServerStream
+}
+
+type parameterServerPullServer struct {
+	grpc.ServerStream
+}
+
+func (x *parameterServerPullServer) Send(m *PullReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ParameterServer_Push_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ParameterServerServer).Push(&parameterServerPushServer{stream})
+}
+
+type ParameterServer_PushServer interface {
+	SendAndClose(*PushReply) error
+	Recv() (*PushRequest, error)
+	grpc.ServerStream
+}
+
+type parameterServerPushServer struct {
+	grpc.ServerStream
+}
+
+func (x *parameterServerPushServer) SendAndClose(m *PushReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *parameterServerPushServer) Recv() (*PushRequest, error) {
+	m := new(PushRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParameterServer_ServiceDesc is the grpc.ServiceDesc for ParameterServer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ParameterServer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "paramserver.grpcapi.ParameterServer",
+	HandlerType: (*ParameterServerServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Pull",
+			Handler:       _ParameterServer_Pull_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Push",
+			Handler:       _ParameterServer_Push_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "paramserver.proto",
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package paramserver implements a parameter server, to train a model across
+// several processes (or machines) with data parallelism.
+//
+// The Server owns the reference copy of the model and its optimizer. Each
+// worker process has its own copy of the model, managed by a Client: it
+// pulls the params, computes the gradients of its share of the data, and
+// pushes them to the server, which applies the updates.
+//
+// Two modes are supported:
+//
+//   - Synchronous: the server waits for the gradients of all the workers,
+//     averages them (in the order of the workers, so that the result is
+//     deterministic) and applies a single update. Push blocks until the update
+//     is done, then the client pulls the new params.
+//   - BoundedStaleness: the server applies the gradients of each worker as
+//     soon as they arrive. A worker keeps computing on its copy of the params
+//     until it is more than Staleness updates behind the server; gradients
+//     computed on older params are discarded.
+//
+// The service is served over gRPC (see the grpcapi package). The params and
+// the gradients are streamed in chunks, so that the messages stay within
+// the default size limit of gRPC, whatever the size of the model.
+package paramserver
+
+import (
+	"github.com/nlpodyssey/spago/pkg/ml/paramserver/grpcapi"
+)
+
+// Mode is the synchronization mode of the workers.
+type Mode int
+
+const (
+	// Synchronous mode: each update averages the gradients of all the workers.
+	Synchronous Mode = iota
+	// BoundedStaleness mode: the gradients of each worker are applied as soon
+	// as they arrive, if the params they were computed on are recent enough.
+	BoundedStaleness
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case Synchronous:
+		return "synchronous"
+	case BoundedStaleness:
+		return "bounded-staleness"
+	default:
+		return "unknown"
+	}
+}
+
+// chunkSize is the maximum size of the params and gradients sent in a
+// single message, well below the default limit of 4 MB of gRPC.
+const chunkSize = 1 << 20
+
+// modeToProto returns the grpcapi value of a Mode.
+func modeToProto(m Mode) grpcapi.Mode {
+	if m == BoundedStaleness {
+		return grpcapi.Mode_BOUNDED_STALENESS
+	}
+	return grpcapi.Mode_SYNCHRONOUS
+}
+
+// modeFromProto returns the Mode of a grpcapi value.
+func modeFromProto(m grpcapi.Mode) Mode {
+	if m == grpcapi.Mode_BOUNDED_STALENESS {
+		return BoundedStaleness
+	}
+	return Synchronous
+}
+
+// chunks splits the data into chunks of at most chunkSize bytes. Empty data
+// is a single empty chunk.
+func chunks(data []byte) [][]byte {
+	out := make([][]byte, 0, len(data)/chunkSize+1)
+	for len(data) > chunkSize {
+		out = append(out, data[:chunkSize])
+		data = data[chunkSize:]
+	}
+	return append(out, data)
+}
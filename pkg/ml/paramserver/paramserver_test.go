@@ -0,0 +1,308 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package paramserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/nlpodyssey/spago/pkg/ml/paramserver/grpcapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+func newTestModel() *testModel {
+	return &testModel{
+		W: nn.NewParam(mat.NewVecDense([]mat.Float{0.5, -0.2, 0.1})),
+		B: nn.NewParam(mat.NewScalar(0.3)),
+	}
+}
+
+var (
+	xs = [][]mat.Float{{1, 2, 3}, {0, 1, 0}, {-1, 0.5, 2}, {2, 2, 2}, {0.3, -1, 0}, {1, 0, -1}}
+	ys = []mat.Float{1, 0, -1, 2, 0.5, 0}
+)
+
+const (
+	testWorkers = 2
+	testSteps   = 3
+)
+
+// backward accumulates the gradients of the examples of the given worker.
+func backward(m *testModel, worker int) {
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForTraining(m, g).(*testModel)
+	var loss ag.Node
+	for i := worker; i < len(xs); i += testWorkers {
+		y := g.Add(g.Dot(proc.W, g.NewVariable(mat.NewVecDense(xs[i]), false)), proc.B)
+		loss = g.Add(loss, losses.MSE(g, y, g.NewScalar(ys[i]), false))
+	}
+	g.Backward(loss)
+}
+
+func newTestServer(t *testing.T, m nn.Model, config Config) (*Server, string) {
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0, false)), nn.NewDefaultParamsIterator(m))
+	return newTestServerWithOptimizer(t, m, optimizer, config)
+}
+
+func newTestServerWithOptimizer(t *testing.T, m nn.Model, optimizer gd.Optimizer, config Config) (*Server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewServer(m, optimizer, config)
+	g := grpc.NewServer()
+	server.Register(g)
+	go g.Serve(listener)
+	t.Cleanup(g.Stop)
+	return server, listener.Addr().String()
+}
+
+func newTestClient(t *testing.T, addr string, worker int, m nn.Model) *Client {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn, worker, m)
+}
+
+// TestHelperProcess is not a real test: it runs a worker in a separate
+// process, started by TestServer_MultiProcess.
+func TestHelperProcess(t *testing.T) {
+	addr := os.Getenv("PARAMSERVER_TEST_ADDR")
+	if addr == "" {
+		return
+	}
+	worker, _ := strconv.Atoi(os.Getenv("PARAMSERVER_TEST_WORKER"))
+	m := &testModel{W: nn.NewParam(mat.NewEmptyVecDense(3)), B: nn.NewParam(mat.NewScalar(0))}
+	client := newTestClient(t, addr, worker, m)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := client.Pull(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for step := 0; step < testSteps; step++ {
+		backward(m, worker)
+		if err := client.Push(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestServer_MultiProcess(t *testing.T) {
+	m := newTestModel()
+	server, addr := newTestServer(t, m, Config{Workers: testWorkers, Mode: Synchronous})
+
+	cmds := make([]*exec.Cmd, testWorkers)
+	for i := range cmds {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmds[i].Env = append(os.Environ(),
+			"PARAMSERVER_TEST_ADDR="+addr,
+			fmt.Sprintf("PARAMSERVER_TEST_WORKER=%d", i),
+		)
+		cmds[i].Stderr = os.Stderr
+		require.NoError(t, cmds[i].Start())
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Wait())
+	}
+	assert.Equal(t, testSteps, server.Version())
+
+	// the same training in a single process, averaging the gradients of the workers
+	reference := newTestModel()
+	for step := 0; step < testSteps; step++ {
+		for worker := 0; worker < testWorkers; worker++ {
+			backward(reference, worker)
+		}
+		nn.ForEachParam(reference, func(param nn.Param) {
+			param.ApplyDelta(param.Grad().ProdScalar(0.1 / testWorkers))
+			param.ZeroGrad()
+		})
+	}
+	assert.InDeltaSlice(t, reference.W.Value().Data(), m.W.Value().Data(), 1e-5)
+	assert.InDeltaSlice(t, reference.B.Value().Data(), m.B.Value().Data(), 1e-5)
+}
+
+func TestServer_BoundedStaleness(t *testing.T) {
+	server, addr := newTestServer(t, newTestModel(), Config{Workers: 2, Mode: BoundedStaleness, Staleness: 1})
+	ctx := context.Background()
+	a, b := newTestModel(), newTestModel()
+	clientA, clientB := newTestClient(t, addr, 0, a), newTestClient(t, addr, 1, b)
+	require.NoError(t, clientA.Pull(ctx))
+	require.NoError(t, clientB.Pull(ctx))
+
+	backward(a, 0)
+	require.NoError(t, clientA.Push(ctx))
+	assert.Equal(t, 0, clientA.Version()) // one update behind, still within the bound
+	backward(b, 1)
+	require.NoError(t, clientB.Push(ctx))
+	assert.Equal(t, 2, server.Version())
+
+	backward(a, 0)
+	assert.Equal(t, ErrStaleGradients, clientA.Push(ctx))
+	assert.Equal(t, 2, server.Version())
+	assert.Equal(t, 2, clientA.Version())
+	assert.False(t, a.W.HasGrad())
+}
+
+func TestServer_Errors(t *testing.T) {
+	_, addr := newTestServer(t, newTestModel(), Config{Workers: 2})
+	ctx := context.Background()
+	assert.Error(t, newTestClient(t, addr, 2, newTestModel()).Pull(ctx))
+
+	other := &testModel{W: nn.NewParam(mat.NewEmptyVecDense(2)), B: nn.NewParam(mat.NewScalar(0))}
+	client := newTestClient(t, addr, 0, other)
+	assert.Error(t, client.Pull(ctx))
+	other.W.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1}))
+	assert.Error(t, client.Push(ctx))
+
+	assert.Panics(t, func() { NewServer(newTestModel(), nil, Config{}) })
+}
+
+func TestServer_MalformedGradients(t *testing.T) {
+	server, addr := newTestServer(t, newTestModel(), Config{Workers: 1})
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := grpcapi.NewParameterServerClient(conn)
+
+	encode := func(m mat.Matrix) []byte {
+		buf := new(bytes.Buffer)
+		require.NoError(t, mat.MarshalBinaryMatrix(m, buf))
+		return buf.Bytes()
+	}
+	valid := encode(mat.NewVecDense([]mat.Float{1, 2, 3}))
+	wrongLength := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(wrongLength[1:], 1<<30)
+	shortValues := append([]byte{}, valid[:len(valid)-2]...)
+	binary.LittleEndian.PutUint32(shortValues[1:], uint32(len(shortValues)-5))
+
+	for name, data := range map[string][]byte{
+		"nil":            encode(nil),
+		"empty":          {},
+		"truncated":      valid[:7],
+		"wrong length":   wrongLength,
+		"short values":   shortValues,
+		"wrong dims":     encode(mat.NewVecDense([]mat.Float{1, 2})),
+		"sparse":         encode(mat.NewSparse(3, 1, []mat.Float{1, 0, 3})),
+		"unknown matrix": append([]byte{9}, valid[1:]...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			stream, err := client.Push(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, stream.Send(&grpcapi.PushRequest{Worker: 0}))
+			require.NoError(t, stream.Send(&grpcapi.PushRequest{Worker: 0, Name: "w", Chunk: data}))
+			_, err = stream.CloseAndRecv()
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+	assert.Equal(t, 0, server.Version())
+
+	stream, err := client.Push(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&grpcapi.PushRequest{Worker: 0}))
+	require.NoError(t, stream.Send(&grpcapi.PushRequest{Worker: 0, Name: "w", Chunk: valid}))
+	_, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.Version())
+}
+
+func TestServer_RetryPush(t *testing.T) {
+	server, addr := newTestServer(t, newTestModel(), Config{Workers: 2})
+	ctx := context.Background()
+	a, b := newTestModel(), newTestModel()
+	clientA, clientB := newTestClient(t, addr, 0, a), newTestClient(t, addr, 1, b)
+	require.NoError(t, clientA.Pull(ctx))
+	require.NoError(t, clientB.Pull(ctx))
+
+	// the push of A is canceled while waiting for B
+	backward(a, 0)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(clientA.Push(timeoutCtx)))
+	assert.True(t, a.W.HasGrad())
+
+	retried := make(chan error)
+	go func() { retried <- clientA.Push(ctx) }()
+	backward(b, 1)
+	require.NoError(t, clientB.Push(ctx))
+	require.NoError(t, <-retried)
+	assert.Equal(t, 1, server.Version())
+	assert.Equal(t, 1, clientA.Version())
+	assert.Equal(t, b.W.Value().Data(), a.W.Value().Data())
+
+	// a push of the last round, whose reply was lost, succeeds at once
+	clientA.version = 0
+	require.NoError(t, clientA.Push(ctx))
+	assert.Equal(t, 1, server.Version())
+	assert.Equal(t, 1, clientA.Version())
+}
+
+func TestServer_LargeParams(t *testing.T) {
+	size := chunkSize/4 + 100 // more than a chunk, whatever the size of mat.Float
+	newLargeModel := func() *testModel {
+		return &testModel{W: nn.NewParam(mat.NewEmptyVecDense(size)), B: nn.NewParam(mat.NewScalar(0))}
+	}
+	m := newLargeModel()
+	m.W.Value().Data()[size-1] = 1
+	server, addr := newTestServer(t, m, Config{Workers: 1})
+	ctx := context.Background()
+
+	worker := newLargeModel()
+	client := newTestClient(t, addr, 0, worker)
+	require.NoError(t, client.Pull(ctx))
+	assert.Equal(t, mat.Float(1), worker.W.Value().Data()[size-1])
+
+	worker.W.PropagateGrad(mat.NewInitVecDense(size, 1))
+	require.NoError(t, client.Push(ctx))
+	assert.Equal(t, 1, server.Version())
+	assert.InDelta(t, -0.1, worker.W.Value().Data()[0], 1e-6)
+	assert.InDelta(t, 0.9, worker.W.Value().Data()[size-1], 1e-6)
+}
+
+func TestServer_TimeSteps(t *testing.T) {
+	model := newTestModel()
+	optimizer := gd.NewOptimizer(adam.New(adam.NewDefaultConfig()), nn.NewDefaultParamsIterator(model))
+	server, addr := newTestServerWithOptimizer(t, model, optimizer, Config{Workers: 1})
+	ctx := context.Background()
+	m := newTestModel()
+	client := newTestClient(t, addr, 0, m)
+	require.NoError(t, client.Pull(ctx))
+	initial := optimizer.TimeSteps()[0]
+	for i := 0; i < 2; i++ {
+		backward(m, 0)
+		require.NoError(t, client.Push(ctx))
+	}
+	assert.Equal(t, initial+2, optimizer.TimeSteps()[0])
+
+	// a push without gradients is still identified
+	require.NoError(t, client.Push(ctx))
+	assert.Equal(t, 3, server.Version())
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package paramserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/paramserver/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config provides configuration settings for a Server.
+type Config struct {
+	// Workers is the number of workers, identified by the numbers from 0 to
+	// Workers-1.
+	Workers int
+	// Mode is the synchronization mode of the workers.
+	Mode Mode
+	// Staleness is the maximum number of updates the params of a worker can
+	// be behind the server, in BoundedStaleness mode.
+	Staleness int
+}
+
+var _ grpcapi.ParameterServerServer = &Server{}
+
+// Server is a parameter server.
+type Server struct {
+	grpcapi.UnimplementedParameterServerServer
+	config    Config
+	model     nn.Model
+	optimizer gd.Optimizer
+	params    map[string]nn.Param
+	mu        sync.Mutex
+	version   int
+	// pending are the gradients of the current round, by worker, in
+	// Synchronous mode.
+	pending []map[string]mat.Matrix
+	// updated is closed when the current round is complete, in Synchronous mode.
+	updated chan struct{}
+}
+
+// NewServer returns a new Server for the model m, whose params are updated
+// by the optimizer, e.g. a gd.GradientDescent or one of its averaging
+// wrappers (see package averaging). Each update is a new batch and a new
+// example for the optimizer (see gd.GradientDescent.IncBatch and IncExample),
+// so that the time steps and the schedules of its methods advance.
+func NewServer(m nn.Model, optimizer gd.Optimizer, config Config) *Server {
+	if config.Workers < 1 {
+		panic("paramserver: the number of workers must be greater than zero")
+	}
+	if config.Staleness < 0 {
+		panic("paramserver: the staleness must be positive")
+	}
+	params := make(map[string]nn.Param)
+	nn.ForEachNamedParam(m, func(name string, param nn.Param) {
+		params[name] = param
+	})
+	return &Server{
+		config:    config,
+		model:     m,
+		optimizer: optimizer,
+		params:    params,
+		pending:   make([]map[string]mat.Matrix, config.Workers),
+		updated:   make(chan struct{}),
+	}
+}
+
+// Register registers the service on the gRPC server.
+func (s *Server) Register(g *grpc.Server) {
+	grpcapi.RegisterParameterServerServer(g, s)
+}
+
+// Version returns the number of updates applied to the params.
+func (s *Server) Version() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// Pull streams the current params, in the state dict format.
+func (s *Server) Pull(req *grpcapi.PullRequest, stream grpcapi.ParameterServer_PullServer) error {
+	if err := s.checkWorker(int(req.Worker)); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	buf := new(bytes.Buffer)
+	err := nn.WriteStateDict(s.model, buf)
+	version := s.version
+	s.mu.Unlock()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	for _, chunk := range chunks(buf.Bytes()) {
+		err := stream.Send(&grpcapi.PullReply{
+			Version:   int64(version),
+			Mode:      modeToProto(s.config.Mode),
+			Staleness: int32(s.config.Staleness),
+			Chunk:     chunk,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Push receives the gradients of a worker.
+func (s *Server) Push(stream grpcapi.ParameterServer_PushServer) error {
+	worker, version, encoded, err := receiveGrads(stream)
+	if err != nil {
+		return err
+	}
+	if err := s.checkWorker(worker); err != nil {
+		return err
+	}
+	grads, err := s.decodeGrads(encoded)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var reply *grpcapi.PushReply
+	if s.config.Mode == BoundedStaleness {
+		reply = s.pushAsync(version, grads)
+	} else if reply, err = s.pushSync(stream.Context(), worker, version, grads); err != nil {
+		return err
+	}
+	return stream.SendAndClose(reply)
+}
+
+// receiveGrads receives the chunks of the gradients pushed by a worker,
+// returning the worker, the version of the params, and the encoded gradients
+// by param name.
+func receiveGrads(stream grpcapi.ParameterServer_PushServer) (worker, version int, grads map[string][]byte, err error) {
+	header, err := stream.Recv()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if header.Name != "" {
+		return 0, 0, nil, status.Error(codes.InvalidArgument, "paramserver: missing push header")
+	}
+	worker, version = int(header.Worker), int(header.Version)
+	grads = make(map[string][]byte)
+	last := ""
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return worker, version, grads, nil
+		}
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if _, ok := grads[req.Name]; ok && req.Name != last {
+			return 0, 0, nil, status.Errorf(codes.InvalidArgument,
+				"paramserver: the chunks of the gradients of %q are not consecutive", req.Name)
+		}
+		grads[req.Name] = append(grads[req.Name], req.Chunk...)
+		last = req.Name
+	}
+}
+
+// pushAsync applies the gradients at once, unless they are stale.
+func (s *Server) pushAsync(version int, grads map[string]mat.Matrix) *grpcapi.PushReply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.version-version > s.config.Staleness {
+		return &grpcapi.PushReply{Version: int64(s.version), Applied: false}
+	}
+	s.update([]map[string]mat.Matrix{grads}, 1)
+	return &grpcapi.PushReply{Version: int64(s.version), Applied: true}
+}
+
+// pushSync adds the gradients to the current round, and waits for the round
+// to be complete.
+//
+// A push is idempotent, so that a worker can retry it after an error, e.g. if
+// its context was canceled while waiting: if the worker has already pushed
+// the gradients of the current round, the new ones are ignored and the push
+// waits for the round again; if the round is already complete, it succeeds
+// at once.
+func (s *Server) pushSync(ctx context.Context, worker, version int, grads map[string]mat.Matrix) (*grpcapi.PushReply, error) {
+	s.mu.Lock()
+	if version == s.version-1 {
+		// all the workers, including this one, pushed the gradients of the last round
+		s.mu.Unlock()
+		return &grpcapi.PushReply{Version: int64(version + 1), Applied: true}, nil
+	}
+	if version != s.version {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"paramserver: the gradients of worker %d are for version %d, expected %d", worker, version, s.version)
+	}
+	if s.pending[worker] == nil {
+		s.pending[worker] = grads
+	}
+	updated := s.updated
+	if s.roundComplete() {
+		s.update(s.pending, mat.Float(s.config.Workers))
+		s.pending = make([]map[string]mat.Matrix, s.config.Workers)
+		close(s.updated)
+		s.updated = make(chan struct{})
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-updated:
+		return &grpcapi.PushReply{Version: int64(version + 1), Applied: true}, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (s *Server) roundComplete() bool {
+	for _, grads := range s.pending {
+		if grads == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// update accumulates the gradients in the params, in order, divided by n, and
+// optimizes the model, as a new batch.
+func (s *Server) update(grads []map[string]mat.Matrix, n mat.Float) {
+	for _, workerGrads := range grads {
+		for name, grad := range workerGrads {
+			if n != 1 {
+				grad.ProdScalarInPlace(1 / n)
+			}
+			s.params[name].PropagateGrad(grad)
+		}
+	}
+	s.optimizer.IncBatch()
+	s.optimizer.IncExample()
+	s.optimizer.Optimize()
+	s.version++
+}
+
+func (s *Server) checkWorker(worker int) error {
+	if worker < 0 || worker >= s.config.Workers {
+		return status.Errorf(codes.InvalidArgument, "paramserver: invalid worker %d", worker)
+	}
+	return nil
+}
+
+// decodeGrads decodes the gradients of a push request, checking that they
+// match the params of the model.
+func (s *Server) decodeGrads(encoded map[string][]byte) (map[string]mat.Matrix, error) {
+	grads := make(map[string]mat.Matrix, len(encoded))
+	for name, data := range encoded {
+		param, ok := s.params[name]
+		if !ok {
+			return nil, fmt.Errorf("paramserver: unknown param %q", name)
+		}
+		rows, cols := param.Dims()
+		grad, err := decodeGrad(data, rows, cols)
+		if err != nil {
+			return nil, fmt.Errorf("paramserver: invalid gradients of %q: %w", name, err)
+		}
+		grads[name] = grad
+	}
+	return grads, nil
+}
+
+// decodeGrad decodes a dense matrix of the given dimensions, encoded by
+// mat.MarshalBinaryMatrix, i.e. the type of the matrix (1 byte), the length
+// of the binary data (4 bytes), the rows and the columns (4 bytes each), and
+// the values (4 or 8 bytes each). The header is checked before decoding, so
+// that malformed data can't make the decoding panic or allocate too much.
+func decodeGrad(data []byte, rows, cols int) (*mat.Dense, error) {
+	const headerLen = 1 + 4 + 8
+	if len(data) < headerLen {
+		return nil, fmt.Errorf("truncated data (%d bytes)", len(data))
+	}
+	if binLen := binary.LittleEndian.Uint32(data[1:]); int(binLen) != len(data)-5 {
+		return nil, fmt.Errorf("wrong binary length %d, found %d bytes", binLen, len(data)-5)
+	}
+	r, c := binary.LittleEndian.Uint32(data[5:]), binary.LittleEndian.Uint32(data[9:])
+	if int(r) != rows || int(c) != cols {
+		return nil, fmt.Errorf("wrong dimensions %dx%d, expected %dx%d", r, c, rows, cols)
+	}
+	if n := len(data) - headerLen; n != rows*cols*4 && n != rows*cols*8 {
+		return nil, fmt.Errorf("wrong length %d of the values of a %dx%d matrix", n, rows, cols)
+	}
+	m, err := mat.UnmarshalBinaryMatrix(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	grad, ok := m.(*mat.Dense)
+	if !ok {
+		return nil, fmt.Errorf("unexpected matrix type %T", m)
+	}
+	return grad, nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package quantization implements the post-training int8 quantization of the
+// linear layers of a model (see linear.Int8Weights).
+//
+// The clip ratio of each row of the weights is calibrated on the inputs
+// observed while running the model on some sample data, choosing the ratio
+// that minimizes the error of the output of the layer.
+package quantization
+
+import (
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/mat/rand/reservoir"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+)
+
+// DefaultClipRatios are the clip ratios evaluated by default during the calibration.
+var DefaultClipRatios = []mat.Float{1, 0.99, 0.98, 0.95, 0.9, 0.85, 0.8}
+
+// DefaultMaxSamples is the default maximum number of input vectors kept for each layer.
+const DefaultMaxSamples = 128
+
+// Config provides configuration settings for a Quantizer.
+type Config struct {
+	// Filter selects the linear layers to quantize, identified by their path
+	// (see nn.ForEachModel). If nil, all the linear layers are quantized.
+	Filter func(path string, layer *linear.Model) bool
+	// ClipRatios are the candidate clip ratios for each row of the weights.
+	// If empty, DefaultClipRatios is used.
+	ClipRatios []mat.Float
+	// MaxSamples is the maximum number of input vectors kept for each layer,
+	// selected with reservoir sampling. If zero, DefaultMaxSamples is used.
+	MaxSamples int
+	// MaxRelativeError, if greater than zero, is the maximum relative error of
+	// the output of a quantized layer: layers exceeding it are left in float.
+	MaxRelativeError mat.Float
+	// Seed is the seed of the random generator used for sampling the inputs.
+	Seed uint64
+}
+
+// Report describes the quantization of a linear layer.
+type Report struct {
+	// Path identifies the layer within the model.
+	Path string
+	// Rows and Columns are the dimensions of the weights.
+	Rows, Columns int
+	// Samples is the number of input vectors used for the calibration.
+	// If zero, the layer was never observed and no clipping is performed.
+	Samples int
+	// MeanClipRatio is the average of the clip ratios chosen for each row.
+	MeanClipRatio mat.Float
+	// RelativeError is the relative error (Frobenius norm) of the output of the
+	// layer on the observed inputs, or of the weights if there are none.
+	RelativeError mat.Float
+	// Skipped is true if the layer was left in float because of MaxRelativeError.
+	Skipped bool
+}
+
+// Quantizer performs the calibration and the quantization of the linear
+// layers of a model.
+type Quantizer struct {
+	config Config
+	layers []*layer
+	// byWeights maps the value of the weights to the layer, to recognize its
+	// multiplications in a graph.
+	byWeights map[mat.Matrix]*layer
+	mu        sync.Mutex
+}
+
+type layer struct {
+	path    string
+	model   *linear.Model
+	samples *reservoir.Reservoir
+}
+
+// New returns a new Quantizer for the linear layers of m.
+// The weights of the selected layers are converted to mat.DTypeFloat.
+func New(m nn.Model, config Config) *Quantizer {
+	if len(config.ClipRatios) == 0 {
+		config.ClipRatios = DefaultClipRatios
+	}
+	if config.MaxSamples == 0 {
+		config.MaxSamples = DefaultMaxSamples
+	}
+	generator := rand.NewLockedRand(config.Seed)
+
+	q := &Quantizer{
+		config:    config,
+		byWeights: make(map[mat.Matrix]*layer),
+	}
+	nn.ForEachModel(m, func(path string, model nn.Model) {
+		lm, ok := model.(*linear.Model)
+		if !ok || lm.Int8 != nil || lm.W.Value() == nil {
+			return
+		}
+		if _, exists := q.byWeights[lm.W.Value()]; exists {
+			return // already visited (e.g. embedded)
+		}
+		if config.Filter != nil && !config.Filter(path, lm) {
+			return
+		}
+		lm.W.SetDType(mat.DTypeFloat) // the value must be shared among graphs to be recognized
+		l := &layer{
+			path:    path,
+			model:   lm,
+			samples: reservoir.New(config.MaxSamples, generator),
+		}
+		q.layers = append(q.layers, l)
+		q.byWeights[lm.W.Value()] = l
+	})
+	return q
+}
+
+// Layers returns the paths of the linear layers to quantize.
+func (q *Quantizer) Layers() []string {
+	paths := make([]string, len(q.layers))
+	for i, l := range q.layers {
+		paths[i] = l.path
+	}
+	return paths
+}
+
+// Observe collects the inputs of the linear layers from the nodes of a graph
+// on which the (reified) model has been run. It must be called before the
+// graph is cleared.
+func (q *Quantizer) Observe(g *ag.Graph) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, node := range g.Nodes() {
+		op, ok := node.(*ag.Operator)
+		if !ok || op.Name() != "Mul" {
+			continue
+		}
+		operands := op.Operands()
+		l, ok := q.byWeights[operands[0].Value()]
+		if !ok {
+			continue
+		}
+		x := operands[1].Value()
+		if x.Columns() == 1 {
+			l.samples.Add(append([]mat.Float(nil), x.Data()...))
+			continue
+		}
+		xt := x.T()
+		for k := 0; k < xt.Rows(); k++ {
+			l.samples.Add(append([]mat.Float(nil), xt.Data()[k*xt.Columns():(k+1)*xt.Columns()]...))
+		}
+		mat.ReleaseMatrix(xt)
+	}
+}
+
+// Quantize calibrates and quantizes the layers, returning a report for each of them.
+func (q *Quantizer) Quantize() []Report {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	reports := make([]Report, len(q.layers))
+	for i, l := range q.layers {
+		reports[i] = q.quantize(l)
+	}
+	return reports
+}
+
+func (q *Quantizer) quantize(l *layer) Report {
+	w := l.model.W.Value()
+	rows, cols := w.Dims()
+	report := Report{
+		Path:    l.path,
+		Rows:    rows,
+		Columns: cols,
+		Samples: len(l.samples.Items()),
+	}
+
+	var ratios []mat.Float
+	if report.Samples == 0 {
+		report.MeanClipRatio = 1
+		report.RelativeError = weightsRelativeError(w, linear.QuantizeInt8(w, nil))
+	} else {
+		x := samplesMatrix(l.samples.Items(), cols)
+		ratios, report.RelativeError = q.calibrate(w, x)
+		mat.ReleaseDense(x)
+		for _, r := range ratios {
+			report.MeanClipRatio += r
+		}
+		report.MeanClipRatio /= mat.Float(rows)
+	}
+
+	if q.config.MaxRelativeError > 0 && report.RelativeError > q.config.MaxRelativeError {
+		report.Skipped = true
+		return report
+	}
+	l.model.Quantize(ratios)
+	return report
+}
+
+// calibrate returns the clip ratio of each row of w which minimizes the
+// squared error of the output on the inputs x, along with the relative error.
+func (q *Quantizer) calibrate(w mat.Matrix, x *mat.Dense) ([]mat.Float, mat.Float) {
+	rows := w.Rows()
+	ratios := make([]mat.Float, rows)
+	bestErrors := make([]mat.Float, rows)
+
+	for c, ratio := range q.config.ClipRatios {
+		errors := rowsOutputErrors(w, linear.QuantizeInt8(w, constantRatios(rows, ratio)), x)
+		for i, e := range errors {
+			if c == 0 || e < bestErrors[i] {
+				bestErrors[i] = e
+				ratios[i] = ratio
+			}
+		}
+	}
+
+	y := w.Mul(x)
+	defer mat.ReleaseMatrix(y)
+	norm := y.Norm(2)
+	totalError := mat.Float(0)
+	for _, e := range bestErrors {
+		totalError += e
+	}
+	if norm == 0 {
+		return ratios, 0
+	}
+	return ratios, mat.Sqrt(totalError) / norm
+}
+
+// rowsOutputErrors returns, for each row i, the squared error of the output
+// of the quantized weights on the inputs x, i.e. ||(w_i - deq(q)_i) x||².
+func rowsOutputErrors(w mat.Matrix, q *linear.Int8Weights, x *mat.Dense) []mat.Float {
+	deq := q.Dequantize()
+	defer mat.ReleaseDense(deq)
+	diff := w.Sub(deq)
+	defer mat.ReleaseMatrix(diff)
+	out := diff.Mul(x)
+	defer mat.ReleaseMatrix(out)
+
+	rows, cols := out.Dims()
+	data := out.Data()
+	errors := make([]mat.Float, rows)
+	for i := range errors {
+		for _, v := range data[i*cols : (i+1)*cols] {
+			errors[i] += v * v
+		}
+	}
+	return errors
+}
+
+func weightsRelativeError(w mat.Matrix, q *linear.Int8Weights) mat.Float {
+	deq := q.Dequantize()
+	defer mat.ReleaseDense(deq)
+	diff := w.Sub(deq)
+	defer mat.ReleaseMatrix(diff)
+	norm := w.Norm(2)
+	if norm == 0 {
+		return 0
+	}
+	return diff.Norm(2) / norm
+}
+
+// samplesMatrix returns a new matrix whose columns are the given samples.
+func samplesMatrix(samples []interface{}, size int) *mat.Dense {
+	out := mat.GetDenseWorkspace(size, len(samples))
+	data := out.Data()
+	n := len(samples)
+	for k, s := range samples {
+		for j, v := range s.([]mat.Float) {
+			data[j*n+k] = v
+		}
+	}
+	return out
+}
+
+func constantRatios(size int, ratio mat.Float) []mat.Float {
+	ratios := make([]mat.Float, size)
+	for i := range ratios {
+		ratios[i] = ratio
+	}
+	return ratios
+}
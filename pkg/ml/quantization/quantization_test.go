@@ -0,0 +1,120 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quantization
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/activation"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantizer(t *testing.T) {
+	model := newTestModel()
+	xs := newTestInputs(20)
+	expected := forward(model, xs, nil)
+
+	q := New(model, Config{})
+	assert.Equal(t, []string{"layers.0", "layers.2"}, q.Layers())
+	forward(model, xs, q)
+
+	reports := q.Quantize()
+	require.Len(t, reports, 2)
+	for _, r := range reports {
+		assert.Equal(t, 20, r.Samples)
+		assert.False(t, r.Skipped)
+		assert.Less(t, r.RelativeError, mat.Float(0.05))
+		assert.True(t, r.MeanClipRatio > 0 && r.MeanClipRatio <= 1)
+	}
+	assert.Equal(t, 8, reports[0].Rows)
+	assert.Equal(t, 6, reports[0].Columns)
+
+	for _, l := range model.Layers {
+		if lm, ok := l.(*linear.Model); ok {
+			assert.NotNil(t, lm.Int8)
+		}
+	}
+
+	actual := forward(model, xs, nil)
+	for i := range expected {
+		assert.InDeltaSlice(t, expected[i], actual[i], 0.05)
+	}
+}
+
+func TestQuantizer_Filter(t *testing.T) {
+	model := newTestModel()
+	q := New(model, Config{
+		Filter: func(path string, _ *linear.Model) bool { return path == "layers.2" },
+	})
+	assert.Equal(t, []string{"layers.2"}, q.Layers())
+
+	reports := q.Quantize()
+	require.Len(t, reports, 1)
+	assert.Equal(t, 0, reports[0].Samples)
+	assert.Nil(t, model.Layers[0].(*linear.Model).Int8)
+	assert.NotNil(t, model.Layers[2].(*linear.Model).Int8)
+}
+
+func TestQuantizer_MaxRelativeError(t *testing.T) {
+	model := newTestModel()
+	xs := newTestInputs(10)
+	q := New(model, Config{MaxRelativeError: 1e-6})
+	forward(model, xs, q)
+
+	for _, r := range q.Quantize() {
+		assert.True(t, r.Skipped)
+	}
+	for _, l := range model.Layers {
+		if lm, ok := l.(*linear.Model); ok {
+			assert.Nil(t, lm.Int8)
+			assert.NotNil(t, lm.W.Value())
+		}
+	}
+}
+
+func newTestModel() *stack.Model {
+	model := stack.New(
+		linear.New(6, 8),
+		activation.New(ag.OpTanh),
+		linear.New(8, 4),
+	)
+	rndGen := rand.NewLockedRand(42)
+	nn.ForEachParam(model, func(param nn.Param) {
+		initializers.Uniform(param.Value(), -1, 1, rndGen)
+	})
+	return model
+}
+
+func newTestInputs(n int) []mat.Matrix {
+	rndGen := rand.NewLockedRand(1)
+	xs := make([]mat.Matrix, n)
+	for i := range xs {
+		xs[i] = mat.NewEmptyVecDense(6)
+		initializers.Uniform(xs[i], -1, 1, rndGen)
+	}
+	return xs
+}
+
+func forward(model *stack.Model, xs []mat.Matrix, q *Quantizer) [][]mat.Float {
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForInference(model, g).(*stack.Model)
+	ys := make([][]mat.Float, len(xs))
+	for i, x := range xs {
+		ys[i] = g.GetCopiedValue(proc.Forward(g.NewVariable(x, false))[0]).Data()
+	}
+	if q != nil {
+		q.Observe(g)
+	}
+	return ys
+}
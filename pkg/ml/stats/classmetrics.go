@@ -5,7 +5,7 @@
 package stats
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 // ClassMetrics provides methods to calculate Precision, Recall, F1Score, Accuracy
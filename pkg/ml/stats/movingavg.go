@@ -4,18 +4,29 @@
 
 package stats
 
-import mat "github.com/nlpodyssey/spago/pkg/mat32"
+import "github.com/nlpodyssey/spago/pkg/mat"
 
 // MovingAvg provides a convenient way to calculate the moving average by adding value incrementally.
 type MovingAvg struct {
 	Mean     mat.Float
 	Variance mat.Float
 	Count    mat.Float // counts the added values
+	// Beta, if not zero, is the smoothing factor of an exponential moving
+	// average (e.g. 0.98), whose Mean is bias-corrected for the first values.
+	Beta mat.Float
+	// biasedMean is the exponential moving average before the bias correction.
+	biasedMean mat.Float
 }
 
 //Add adds the value to the moving average.
 func (m *MovingAvg) Add(value mat.Float) {
 	m.Count++
+	if m.Beta != 0 {
+		m.biasedMean = m.Beta*m.biasedMean + (1-m.Beta)*value
+		m.Mean = m.biasedMean / (1 - mat.Pow(m.Beta, m.Count))
+		m.Variance = m.Beta*m.Variance + (1-m.Beta)*(value-m.Mean)*(value-m.Mean)
+		return
+	}
 	m.Mean += (2.0 / m.Count) * (value - m.Mean)
 	m.Variance += (2.0 / m.Count) * ((value-m.Mean)*(value-m.Mean) - m.Variance)
 }
@@ -0,0 +1,20 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovingAvg_Add_Exponential(t *testing.T) {
+	m := &MovingAvg{Beta: 0.9}
+	m.Add(4)
+	assert.InDelta(t, 4, m.Mean, 1e-6) // bias-corrected
+	m.Add(2)
+	// (0.9 * 0.1 * 4 + 0.1 * 2) / (1 - 0.81)
+	assert.InDelta(t, 0.56/0.19, m.Mean, 1e-5)
+}
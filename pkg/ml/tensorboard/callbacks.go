@@ -0,0 +1,84 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tensorboard
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/trainer"
+)
+
+// GradientsCallback returns a callback for gd.WithGradientsCallback, which
+// writes the global L2 norm of the gradients ("gradients/norm") at each
+// optimization step, before the clipping.
+//
+// The step of the events is the one returned by the step function, e.g. the
+// step of a trainer, so that the norms are aligned with the metrics written
+// by TrainerCallback, also with gradient accumulation:
+//     var tr *trainer.Trainer
+//     callback := w.GradientsCallback(func() int { return tr.State().Step })
+//     optimizer := gd.NewOptimizer(method, params, gd.WithGradientsCallback(callback))
+//     tr = trainer.New(m, loss, dataset, optimizer, config)
+func (w *Writer) GradientsCallback(step func() int) func(params []nn.Param) {
+	return func(params []nn.Param) {
+		sum := mat.Float(0)
+		for _, param := range params {
+			if !param.HasGrad() {
+				continue
+			}
+			for _, g := range param.Grad().Data() {
+				sum += g * g
+			}
+		}
+		_ = w.AddScalar("gradients/norm", step(), mat.Sqrt(sum))
+	}
+}
+
+var (
+	_ trainer.BatchEndCallback   = &TrainerCallback{}
+	_ trainer.EvaluationCallback = &TrainerCallback{}
+	_ trainer.EpochEndCallback   = &TrainerCallback{}
+)
+
+// TrainerCallback writes the metrics of a trainer.Trainer: the loss of each
+// batch ("train/loss"), the learning rate ("train/learning_rate"), the
+// validation score ("validation/score"), and, at the end of each epoch, the
+// mean loss of the epoch ("train/epoch_loss") and the histograms of the
+// params ("params/<name>").
+type TrainerCallback struct {
+	Writer *Writer
+	// Model, if not nil, is the model whose params are written.
+	Model nn.Model
+	// LearningRate, if not nil, is the method whose learning rate is written.
+	LearningRate gd.LearningRateSetter
+}
+
+// OnBatchEnd writes the loss of the batch and the learning rate.
+func (c *TrainerCallback) OnBatchEnd(s *trainer.State) {
+	_ = c.Writer.AddScalar("train/loss", s.Step, s.BatchLoss)
+	if c.LearningRate != nil {
+		_ = c.Writer.AddScalar("train/learning_rate", s.Step, c.LearningRate.LearningRate())
+	}
+}
+
+// OnEvaluation writes the validation score.
+func (c *TrainerCallback) OnEvaluation(s *trainer.State) {
+	_ = c.Writer.AddScalar("validation/score", s.Step, s.Score)
+}
+
+// OnEpochEnd writes the mean loss of the epoch and the histograms of the
+// params, then flushes the events.
+func (c *TrainerCallback) OnEpochEnd(s *trainer.State) {
+	_ = c.Writer.AddScalar("train/epoch_loss", s.Step, s.EpochLoss)
+	if c.Model != nil {
+		nn.ForEachNamedParam(c.Model, func(name string, param nn.Param) {
+			param.ReadValue(func(value mat.Matrix) {
+				_ = c.Writer.AddHistogram("params/"+name, s.Step, value.Data())
+			})
+		})
+	}
+	_ = c.Writer.Flush()
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tensorboard
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+)
+
+// projectorConfigFilename is the configuration file of the embedding projector.
+const projectorConfigFilename = "projector_config.pbtxt"
+
+// embedding is an embedding registered in the projector configuration.
+type embedding struct {
+	tag          string
+	tensorPath   string
+	metadataPath string
+}
+
+// AddEmbeddings writes the vectors of an embedding, with their optional
+// labels, for the embedding projector. The vectors are written in TSV format
+// in a subdirectory of the log directory, named after the tag, and registered
+// in the projector configuration. Adding the same tag again replaces it.
+func (w *Writer) AddEmbeddings(tag string, vectors []mat.Matrix, labels []string) error {
+	if labels != nil && len(labels) != len(vectors) {
+		return fmt.Errorf("tensorboard: %d labels for %d vectors", len(labels), len(vectors))
+	}
+	dirname := strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(tag)
+	if err := os.MkdirAll(filepath.Join(w.logDir, dirname), 0755); err != nil {
+		return err
+	}
+	e := embedding{tag: tag, tensorPath: filepath.Join(dirname, "tensors.tsv")}
+	err := writeLines(filepath.Join(w.logDir, e.tensorPath), len(vectors), func(i int) string {
+		data := vectors[i].Data()
+		fields := make([]string, len(data))
+		for j, v := range data {
+			fields[j] = strconv.FormatFloat(float64(v), 'g', -1, 64)
+		}
+		return strings.Join(fields, "\t")
+	})
+	if err != nil {
+		return err
+	}
+	if labels != nil {
+		e.metadataPath = filepath.Join(dirname, "metadata.tsv")
+		err := writeLines(filepath.Join(w.logDir, e.metadataPath), len(labels), func(i int) string {
+			return strings.NewReplacer("\t", " ", "\n", " ").Replace(labels[i])
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	replaced := false
+	for i, item := range w.embeddings {
+		if item.tag == tag {
+			w.embeddings[i], replaced = e, true
+		}
+	}
+	if !replaced {
+		w.embeddings = append(w.embeddings, e)
+	}
+	return w.writeProjectorConfig()
+}
+
+// writeProjectorConfig writes the projector configuration, in the text
+// format of protocol buffers.
+func (w *Writer) writeProjectorConfig() error {
+	return writeLines(filepath.Join(w.logDir, projectorConfigFilename), len(w.embeddings), func(i int) string {
+		e := w.embeddings[i]
+		var sb strings.Builder
+		sb.WriteString("embeddings {\n")
+		fmt.Fprintf(&sb, "  tensor_name: %s\n", strconv.Quote(e.tag))
+		fmt.Fprintf(&sb, "  tensor_path: %s\n", strconv.Quote(filepath.ToSlash(e.tensorPath)))
+		if e.metadataPath != "" {
+			fmt.Fprintf(&sb, "  metadata_path: %s\n", strconv.Quote(filepath.ToSlash(e.metadataPath)))
+		}
+		sb.WriteString("}")
+		return sb.String()
+	})
+}
+
+// writeLines writes n lines to a file, given by line(i).
+func writeLines(filename string, n int, line func(i int) string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	for i := 0; i < n; i++ {
+		if _, err := fmt.Fprintln(bw, line(i)); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
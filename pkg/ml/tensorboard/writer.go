@@ -0,0 +1,291 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tensorboard writes the metrics of a training in the TensorBoard
+// event file format ("tfevents"), so that it can be monitored with
+// TensorBoard: scalars (e.g. the loss or the learning rate), histograms (e.g.
+// of the params), and embeddings for the projector.
+//
+// The events are protocol buffers messages, encoded directly in the wire
+// format, and framed as TFRecords.
+package tensorboard
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// DefaultHistogramBuckets is the default number of buckets of the histograms.
+const DefaultHistogramBuckets = 30
+
+// Writer writes the events of a run in a log directory.
+// The errors are sticky: after the first one, all the methods return it.
+// It is safe for concurrent use.
+type Writer struct {
+	// HistogramBuckets is the number of buckets of the histograms.
+	HistogramBuckets int
+	logDir           string
+	mu               sync.Mutex
+	file             *os.File
+	w                *bufio.Writer
+	err              error
+	embeddings       []embedding
+}
+
+// NewWriter returns a new Writer, creating the log directory if needed and a
+// new event file in it.
+func NewWriter(logDir string) (*Writer, error) {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	filename := fmt.Sprintf("events.out.tfevents.%d.%s", time.Now().Unix(), hostname)
+	f, err := os.Create(filepath.Join(logDir, filename))
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		HistogramBuckets: DefaultHistogramBuckets,
+		logDir:           logDir,
+		file:             f,
+		w:                bufio.NewWriter(f),
+	}
+	w.writeEvent(0, func(b []byte) []byte {
+		b = protowire.AppendTag(b, eventFileVersion, protowire.BytesType)
+		return protowire.AppendString(b, "brain.Event:2")
+	})
+	if w.err != nil {
+		_ = f.Close()
+		return nil, w.err
+	}
+	return w, nil
+}
+
+// LogDir returns the log directory.
+func (w *Writer) LogDir() string {
+	return w.logDir
+}
+
+// AddScalar writes the value of a scalar at the given step.
+func (w *Writer) AddScalar(tag string, step int, value mat.Float) error {
+	return w.writeSummaryValue(step, tag, func(b []byte) []byte {
+		b = protowire.AppendTag(b, valueSimpleValue, protowire.Fixed32Type)
+		return protowire.AppendFixed32(b, math.Float32bits(float32(value)))
+	})
+}
+
+// AddHistogram writes the histogram of the values at the given step.
+func (w *Writer) AddHistogram(tag string, step int, values []mat.Float) error {
+	if len(values) == 0 {
+		return w.Err()
+	}
+	h := newHistogram(values, w.HistogramBuckets)
+	return w.writeSummaryValue(step, tag, func(b []byte) []byte {
+		b = protowire.AppendTag(b, valueHisto, protowire.BytesType)
+		return protowire.AppendBytes(b, h.appendProto(nil))
+	})
+}
+
+// Flush writes the buffered events to the file.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	return w.err
+}
+
+// Close flushes the events and closes the file.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Err returns the first error occurred, if any.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// The field numbers of the protocol buffers messages (see tensorflow/core/util/event.proto
+// and tensorflow/core/framework/summary.proto).
+const (
+	eventWallTime    protowire.Number = 1
+	eventStep        protowire.Number = 2
+	eventFileVersion protowire.Number = 3
+	eventSummary     protowire.Number = 5
+
+	summaryValue protowire.Number = 1
+
+	valueTag         protowire.Number = 1
+	valueSimpleValue protowire.Number = 2
+	valueHisto       protowire.Number = 5
+
+	histoMin         protowire.Number = 1
+	histoMax         protowire.Number = 2
+	histoNum         protowire.Number = 3
+	histoSum         protowire.Number = 4
+	histoSumSquares  protowire.Number = 5
+	histoBucketLimit protowire.Number = 6
+	histoBucket      protowire.Number = 7
+)
+
+// writeSummaryValue writes an event with a summary of a single value, whose
+// fields after the tag are appended by appendValue.
+func (w *Writer) writeSummaryValue(step int, tag string, appendValue func(b []byte) []byte) error {
+	value := protowire.AppendTag(nil, valueTag, protowire.BytesType)
+	value = protowire.AppendString(value, tag)
+	value = appendValue(value)
+	summary := protowire.AppendTag(nil, summaryValue, protowire.BytesType)
+	summary = protowire.AppendBytes(summary, value)
+	w.writeEvent(step, func(b []byte) []byte {
+		b = protowire.AppendTag(b, eventSummary, protowire.BytesType)
+		return protowire.AppendBytes(b, summary)
+	})
+	return w.Err()
+}
+
+// writeEvent writes an event with the wall time and the step, whose other
+// fields are appended by appendFields.
+func (w *Writer) writeEvent(step int, appendFields func(b []byte) []byte) {
+	now := float64(time.Now().UnixNano()) / 1e9
+	b := protowire.AppendTag(nil, eventWallTime, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(now))
+	b = protowire.AppendTag(b, eventStep, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(step))
+	b = appendFields(b)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = writeRecord(w.w, b)
+	}
+}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC returns the masked CRC32-C of the data, as required by the TFRecord format.
+func maskedCRC(data []byte) uint32 {
+	crc := crc32.Checksum(data, crc32c)
+	return ((crc >> 15) | (crc << 17)) + 0xa282ead8
+}
+
+// writeRecord writes the data as a TFRecord: the length, the CRC of the
+// length, the data and the CRC of the data.
+func writeRecord(w *bufio.Writer, data []byte) error {
+	header := make([]byte, 12)
+	binary.LittleEndian.PutUint64(header, uint64(len(data)))
+	binary.LittleEndian.PutUint32(header[8:], maskedCRC(header[:8]))
+	footer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footer, maskedCRC(data))
+	for _, b := range [][]byte{header, data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// histogram is the histogram of some values, with buckets of the same width.
+type histogram struct {
+	min, max, num, sum, sumSquares float64
+	limits                         []float64
+	counts                         []float64
+}
+
+// newHistogram returns the histogram of the values. The limits, the sum and
+// the sum of squares are computed on the finite values only, so that the
+// histogram of diverging params can still be written: the infinite values are
+// counted in the first or in the last bucket, and the NaN values are skipped.
+func newHistogram(values []mat.Float, buckets int) *histogram {
+	if buckets < 1 {
+		buckets = 1
+	}
+	h := &histogram{min: math.Inf(1), max: math.Inf(-1)}
+	for _, v := range values {
+		x := float64(v)
+		if math.IsNaN(x) {
+			continue
+		}
+		h.num++
+		if math.IsInf(x, 0) {
+			continue
+		}
+		h.min = math.Min(h.min, x)
+		h.max = math.Max(h.max, x)
+		h.sum += x
+		h.sumSquares += x * x
+	}
+	if h.min > h.max { // no finite values
+		h.min, h.max = 0, 0
+	}
+	if h.min == h.max {
+		buckets = 1
+	}
+	width := (h.max - h.min) / float64(buckets)
+	h.limits = make([]float64, buckets)
+	h.counts = make([]float64, buckets)
+	for i := range h.limits {
+		h.limits[i] = h.min + width*float64(i+1)
+	}
+	h.limits[buckets-1] = h.max
+	for _, v := range values {
+		x := float64(v)
+		i := buckets - 1
+		switch {
+		case math.IsNaN(x):
+			continue
+		case math.IsInf(x, -1):
+			i = 0
+		case math.IsInf(x, 1):
+		case width > 0:
+			i = int((x - h.min) / width)
+			if i >= buckets {
+				i = buckets - 1
+			}
+		}
+		h.counts[i]++
+	}
+	return h
+}
+
+// appendProto appends the HistogramProto message.
+func (h *histogram) appendProto(b []byte) []byte {
+	for _, field := range []struct {
+		num   protowire.Number
+		value float64
+	}{{histoMin, h.min}, {histoMax, h.max}, {histoNum, h.num}, {histoSum, h.sum}, {histoSumSquares, h.sumSquares}} {
+		b = protowire.AppendTag(b, field.num, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(field.value))
+	}
+	b = appendPackedDoubles(b, histoBucketLimit, h.limits)
+	return appendPackedDoubles(b, histoBucket, h.counts)
+}
+
+func appendPackedDoubles(b []byte, num protowire.Number, values []float64) []byte {
+	packed := make([]byte, 0, 8*len(values))
+	for _, v := range values {
+		packed = protowire.AppendFixed64(packed, math.Float64bits(v))
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
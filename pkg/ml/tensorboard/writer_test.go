@@ -0,0 +1,283 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tensorboard
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/nlpodyssey/spago/pkg/ml/trainer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testEvent is a decoded event, with a summary of at most one value.
+type testEvent struct {
+	step        int
+	fileVersion string
+	tag         string
+	simpleValue float32
+	histo       map[protowire.Number][]float64
+}
+
+// readEvents reads the events of the event file in dir, checking the records.
+func readEvents(t *testing.T, dir string) []testEvent {
+	files, err := filepath.Glob(filepath.Join(dir, "events.out.tfevents.*"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	var events []testEvent
+	for len(data) > 0 {
+		length := binary.LittleEndian.Uint64(data)
+		require.Equal(t, maskedCRC(data[:8]), binary.LittleEndian.Uint32(data[8:]))
+		record := data[12 : 12+length]
+		require.Equal(t, maskedCRC(record), binary.LittleEndian.Uint32(data[12+length:]))
+		data = data[16+length:]
+		events = append(events, decodeEvent(t, record))
+	}
+	return events
+}
+
+// consumeFields calls fn for each field of a message.
+func consumeFields(t *testing.T, b []byte, fn func(num protowire.Number, typ protowire.Type, b []byte) int) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.Greater(t, n, 0)
+		b = b[n:]
+		m := fn(num, typ, b)
+		if m == 0 {
+			m = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		require.Greater(t, m, 0)
+		b = b[m:]
+	}
+}
+
+func decodeEvent(t *testing.T, b []byte) testEvent {
+	var e testEvent
+	consumeFields(t, b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch num {
+		case eventStep:
+			v, n := protowire.ConsumeVarint(b)
+			e.step = int(v)
+			return n
+		case eventFileVersion:
+			v, n := protowire.ConsumeString(b)
+			e.fileVersion = v
+			return n
+		case eventSummary:
+			summary, n := protowire.ConsumeBytes(b)
+			consumeFields(t, summary, func(num protowire.Number, typ protowire.Type, b []byte) int {
+				value, n := protowire.ConsumeBytes(b)
+				decodeValue(t, value, &e)
+				return n
+			})
+			return n
+		}
+		return 0
+	})
+	return e
+}
+
+func decodeValue(t *testing.T, b []byte, e *testEvent) {
+	consumeFields(t, b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch num {
+		case valueTag:
+			v, n := protowire.ConsumeString(b)
+			e.tag = v
+			return n
+		case valueSimpleValue:
+			v, n := protowire.ConsumeFixed32(b)
+			e.simpleValue = math.Float32frombits(v)
+			return n
+		case valueHisto:
+			histo, n := protowire.ConsumeBytes(b)
+			e.histo = make(map[protowire.Number][]float64)
+			consumeFields(t, histo, func(num protowire.Number, typ protowire.Type, b []byte) int {
+				if typ == protowire.Fixed64Type {
+					v, n := protowire.ConsumeFixed64(b)
+					e.histo[num] = []float64{math.Float64frombits(v)}
+					return n
+				}
+				packed, n := protowire.ConsumeBytes(b)
+				for ; len(packed) > 0; packed = packed[8:] {
+					v, _ := protowire.ConsumeFixed64(packed)
+					e.histo[num] = append(e.histo[num], math.Float64frombits(v))
+				}
+				return n
+			})
+			return n
+		}
+		return 0
+	})
+}
+
+func TestWriter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	w, err := NewWriter(dir)
+	require.NoError(t, err)
+	w.HistogramBuckets = 2
+	require.NoError(t, w.AddScalar("loss", 3, 0.25))
+	require.NoError(t, w.AddHistogram("w", 4, []mat.Float{1, 2, 3, 4, 5}))
+	require.NoError(t, w.AddHistogram("empty", 4, nil))
+	require.NoError(t, w.Close())
+
+	events := readEvents(t, dir)
+	require.Len(t, events, 3)
+	assert.Equal(t, "brain.Event:2", events[0].fileVersion)
+	assert.Equal(t, testEvent{step: 3, tag: "loss", simpleValue: 0.25}, events[1])
+	assert.Equal(t, "w", events[2].tag)
+	assert.Equal(t, 4, events[2].step)
+	assert.Equal(t, map[protowire.Number][]float64{
+		histoMin:         {1},
+		histoMax:         {5},
+		histoNum:         {5},
+		histoSum:         {15},
+		histoSumSquares:  {55},
+		histoBucketLimit: {3, 5},
+		histoBucket:      {2, 3},
+	}, events[2].histo)
+}
+
+func TestNewHistogram_Constant(t *testing.T) {
+	h := newHistogram([]mat.Float{2, 2, 2}, 10)
+	assert.Equal(t, []float64{2}, h.limits)
+	assert.Equal(t, []float64{3}, h.counts)
+}
+
+func TestNewHistogram_NonFinite(t *testing.T) {
+	inf, nan := mat.Inf(1), mat.Float(math.NaN())
+	h := newHistogram([]mat.Float{1, 2, inf, -inf, nan, 3}, 2)
+	assert.Equal(t, 1.0, h.min)
+	assert.Equal(t, 3.0, h.max)
+	assert.Equal(t, 5.0, h.num)
+	assert.Equal(t, 6.0, h.sum)
+	assert.Equal(t, 14.0, h.sumSquares)
+	assert.Equal(t, []float64{2, 3}, h.limits)
+	assert.Equal(t, []float64{2, 3}, h.counts)
+
+	h = newHistogram([]mat.Float{1, 2, inf}, 30)
+	assert.Equal(t, 3.0, h.num)
+	assert.Equal(t, 2.0, h.limits[29])
+	assert.Equal(t, 2.0, h.counts[29])
+
+	h = newHistogram([]mat.Float{nan, inf}, 10)
+	assert.Equal(t, []float64{0}, h.limits)
+	assert.Equal(t, []float64{1}, h.counts)
+
+	h = newHistogram(nil, 10)
+	assert.Equal(t, []float64{0}, h.counts)
+}
+
+func TestWriter_AddEmbeddings(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir)
+	require.NoError(t, err)
+	defer w.Close()
+	vectors := []mat.Matrix{mat.NewVecDense([]mat.Float{1, 0.5}), mat.NewVecDense([]mat.Float{-1, 2})}
+	require.NoError(t, w.AddEmbeddings("words/emb", vectors, []string{"foo", "bar\tbaz"}))
+	require.NoError(t, w.AddEmbeddings("chars", vectors, nil))
+	assert.Error(t, w.AddEmbeddings("chars", vectors, []string{"a"}))
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "1\t0.5\n-1\t2\n", read("words_emb/tensors.tsv"))
+	assert.Equal(t, "foo\nbar baz\n", read("words_emb/metadata.tsv"))
+	assert.Equal(t, `embeddings {
+  tensor_name: "words/emb"
+  tensor_path: "words_emb/tensors.tsv"
+  metadata_path: "words_emb/metadata.tsv"
+}
+embeddings {
+  tensor_name: "chars"
+  tensor_path: "chars/tensors.tsv"
+}
+`, read(projectorConfigFilename))
+}
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+}
+
+func TestTrainerCallback(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir)
+	require.NoError(t, err)
+
+	tr := newTestTrainer(w, 2, 1)
+	require.NoError(t, tr.Train())
+	require.NoError(t, w.Close())
+
+	var tags []string
+	for _, e := range readEvents(t, dir)[1:] {
+		tags = append(tags, e.tag)
+	}
+	assert.Equal(t, []string{
+		"gradients/norm", "train/loss", "train/learning_rate",
+		"gradients/norm", "train/loss", "train/learning_rate",
+		"validation/score", "train/epoch_loss", "params/w",
+	}, tags)
+}
+
+func TestWriter_GradientsCallback(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir)
+	require.NoError(t, err)
+
+	// the gradients are accumulated over 2 batches
+	tr := newTestTrainer(w, 5, 2)
+	require.NoError(t, tr.Train())
+	require.NoError(t, w.Close())
+
+	var steps []int
+	for _, e := range readEvents(t, dir)[1:] {
+		if e.tag == "gradients/norm" {
+			steps = append(steps, e.step)
+		}
+	}
+	assert.Equal(t, []int{2, 4, 5}, steps)
+}
+
+// newTestTrainer returns a trainer of a testModel over n examples, writing
+// the metrics and the norm of the gradients to w.
+func newTestTrainer(w *Writer, n, microBatches int) *trainer.Trainer {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0))}
+	method := sgd.New(sgd.NewConfig(0.1, 0, false))
+	var tr *trainer.Trainer
+	optimizer := gd.NewOptimizer(method, nn.NewDefaultParamsIterator(m),
+		gd.WithGradientsCallback(w.GradientsCallback(func() int { return tr.State().Step })),
+		gd.WithGradientAccumulation(gd.AccumulationConfig{MicroBatches: microBatches}),
+	)
+	loss := func(proc nn.Model, batch []int) ag.Node {
+		g := proc.Graph()
+		return losses.MSE(g, proc.(*testModel).W, g.NewScalar(1), false)
+	}
+	tr = trainer.New(m, loss, dataset(n), optimizer, trainer.Config{
+		Epochs:    1,
+		BatchSize: 1,
+		Callbacks: []trainer.Callback{&TrainerCallback{Writer: w, Model: m, LearningRate: method}},
+	})
+	return tr
+}
+
+type dataset int
+
+func (d dataset) Len() int { return int(d) }
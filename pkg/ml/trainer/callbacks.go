@@ -0,0 +1,84 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trainer
+
+// Callback is implemented by any value that has at least one of the methods
+// of EpochBeginCallback, EpochEndCallback, BatchEndCallback and
+// EvaluationCallback. A callback can stop the training with State.Stop.
+type Callback interface{}
+
+// EpochBeginCallback is implemented by the callbacks of the beginning of an epoch.
+type EpochBeginCallback interface {
+	// OnEpochBegin is called at the beginning of each epoch.
+	OnEpochBegin(s *State)
+}
+
+// EpochEndCallback is implemented by the callbacks of the end of an epoch.
+type EpochEndCallback interface {
+	// OnEpochEnd is called at the end of each epoch.
+	OnEpochEnd(s *State)
+}
+
+// BatchEndCallback is implemented by the callbacks of the end of a batch.
+type BatchEndCallback interface {
+	// OnBatchEnd is called after the processing of each batch.
+	OnBatchEnd(s *State)
+}
+
+// EvaluationCallback is implemented by the callbacks of an evaluation.
+type EvaluationCallback interface {
+	// OnEvaluation is called after each evaluation of the model.
+	OnEvaluation(s *State)
+}
+
+// BatchEndFunc is a function adapter of BatchEndCallback.
+type BatchEndFunc func(s *State)
+
+// OnBatchEnd calls f(s).
+func (f BatchEndFunc) OnBatchEnd(s *State) { f(s) }
+
+// EpochEndFunc is a function adapter of EpochEndCallback.
+type EpochEndFunc func(s *State)
+
+// OnEpochEnd calls f(s).
+func (f EpochEndFunc) OnEpochEnd(s *State) { f(s) }
+
+// EvaluationFunc is a function adapter of EvaluationCallback.
+type EvaluationFunc func(s *State)
+
+// OnEvaluation calls f(s).
+func (f EvaluationFunc) OnEvaluation(s *State) { f(s) }
+
+func (t *Trainer) callEpochBegin() {
+	for _, c := range t.Callbacks {
+		if c, ok := c.(EpochBeginCallback); ok {
+			c.OnEpochBegin(&t.state)
+		}
+	}
+}
+
+func (t *Trainer) callEpochEnd() {
+	for _, c := range t.Callbacks {
+		if c, ok := c.(EpochEndCallback); ok {
+			c.OnEpochEnd(&t.state)
+		}
+	}
+}
+
+func (t *Trainer) callBatchEnd() {
+	for _, c := range t.Callbacks {
+		if c, ok := c.(BatchEndCallback); ok {
+			c.OnBatchEnd(&t.state)
+		}
+	}
+}
+
+func (t *Trainer) callEvaluation() {
+	for _, c := range t.Callbacks {
+		if c, ok := c.(EvaluationCallback); ok {
+			c.OnEvaluation(&t.state)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package trainer implements a generic training loop: it iterates the
+// examples of a dataset by epochs and batches, optimizes the model, and
+// periodically evaluates it, saving the best model and stopping early when
+// it doesn't improve anymore. The loop can be customized with callbacks.
+package trainer
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/gosuri/uiprogress"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/utils"
+	"github.com/nlpodyssey/spago/pkg/utils/data"
+)
+
+// Dataset is implemented by any dataset whose examples can be accessed by
+// index, from 0 to Len()-1.
+type Dataset interface {
+	// Len returns the number of examples.
+	Len() int
+}
+
+// LossFunc returns the loss of a batch, given by the indices of its examples
+// in the dataset. The processor is reified for training on a new graph.
+// It can return nil if there is nothing to learn from the batch.
+type LossFunc func(proc nn.Model, batch []int) ag.Node
+
+// EvaluateFunc returns the score of the model on the validation data.
+type EvaluateFunc func() mat.Float
+
+// Config provides configuration settings for a Trainer.
+type Config struct {
+	// Epochs is the number of epochs.
+	Epochs int
+	// BatchSize is the number of examples of each batch.
+	BatchSize int
+	// Shuffle shuffles the examples at the beginning of each epoch.
+	Shuffle bool
+	// Seed is the seed of the random generator, used to shuffle the examples
+	// and by the graphs (e.g. for the dropout).
+	Seed uint64
+	// Evaluate, if not nil, returns the validation score of the model. If nil,
+	// the score is the mean loss of the epoch.
+	Evaluate EvaluateFunc
+	// EvaluationInterval is the number of batches between two evaluations.
+	// If 0, the model is evaluated at the end of each epoch.
+	EvaluationInterval int
+	// Maximize is true if the higher the validation score, the better
+	// (e.g. the accuracy), false if the lower, the better (e.g. the loss).
+	Maximize bool
+	// Patience is the number of evaluations without improvement after which
+	// the training stops. If 0, the training never stops early.
+	Patience int
+	// MinDelta is the minimum change of the score which counts as improvement.
+	MinDelta mat.Float
+	// ModelPath, if not empty, is the file where the model is serialized
+	// whenever it gets its best score.
+	ModelPath string
+	// ShowProgress shows the progress of each epoch on the standard output.
+	ShowProgress bool
+	// ConcurrentComputations is the number of concurrent computations of the
+	// graphs (see ag.ConcurrentComputations). If 0, runtime.NumCPU() is used.
+	ConcurrentComputations int
+	// Callbacks are called during the training (see Callback).
+	Callbacks []Callback
+}
+
+// State is the state of a training.
+type State struct {
+	// Epoch is the current epoch, starting from 1.
+	Epoch int
+	// Step is the number of batches processed since the beginning of the training.
+	Step int
+	// BatchLoss is the loss of the last batch.
+	BatchLoss mat.Float
+	// EpochLoss is the mean loss of the batches of the current epoch.
+	EpochLoss mat.Float
+	// Score is the last validation score.
+	Score mat.Float
+	// BestScore is the best validation score.
+	BestScore mat.Float
+	// BestStep is the step of the best validation score (0 if there isn't any).
+	BestStep int
+	// Evaluations is the number of evaluations.
+	Evaluations int
+	// Stopped is true if the training was stopped (see Stop).
+	Stopped bool
+
+	epochBatches int
+	// badEvaluations is the number of consecutive evaluations without improvement.
+	badEvaluations int
+}
+
+// Stop stops the training at the end of the current batch.
+func (s *State) Stop() {
+	s.Stopped = true
+}
+
+// Trainer implements a generic training process.
+type Trainer struct {
+	Config
+	model     nn.Model
+	loss      LossFunc
+	dataset   Dataset
+	optimizer gd.Optimizer
+	rndGen    *rand.LockedRand
+	state     State
+}
+
+// New returns a new Trainer, which trains the model m on the dataset,
+// minimizing the loss with the optimizer, e.g. a gd.GradientDescent or one
+// of its averaging wrappers (see package averaging).
+func New(m nn.Model, loss LossFunc, dataset Dataset, optimizer gd.Optimizer, config Config) *Trainer {
+	if config.BatchSize < 1 {
+		panic("trainer: the batch size must be greater than zero")
+	}
+	if config.ConcurrentComputations == 0 {
+		config.ConcurrentComputations = runtime.NumCPU()
+	}
+	return &Trainer{
+		Config:    config,
+		model:     m,
+		loss:      loss,
+		dataset:   dataset,
+		optimizer: optimizer,
+		rndGen:    rand.NewLockedRand(config.Seed),
+	}
+}
+
+// State returns the state of the training.
+func (t *Trainer) State() *State {
+	return &t.state
+}
+
+// Train executes the training process, until the last epoch or an early
+// stop. It returns an error only if the model can't be serialized.
+func (t *Trainer) Train() error {
+	indices := utils.MakeIndices(t.dataset.Len())
+	for t.state.Epoch = 1; t.state.Epoch <= t.Epochs && !t.state.Stopped; t.state.Epoch++ {
+		if t.Shuffle {
+			rand.ShuffleInPlace(indices, t.rndGen)
+		}
+		if err := t.trainEpoch(indices); err != nil {
+			return err
+		}
+		t.optimizer.IncEpoch()
+	}
+	t.state.Epoch--
+	return nil
+}
+
+func (t *Trainer) trainEpoch(indices []int) (err error) {
+	t.state.EpochLoss = 0
+	t.state.epochBatches = 0
+	t.callEpochBegin()
+
+	var bar *uiprogress.Bar
+	if t.ShowProgress {
+		uip := uiprogress.New()
+		bar = uip.AddBar((len(indices) + t.BatchSize - 1) / t.BatchSize)
+		bar.PrependFunc(func(b *uiprogress.Bar) string {
+			return fmt.Sprintf("Epoch %d/%d", t.state.Epoch, t.Epochs)
+		})
+		bar.AppendCompleted().PrependElapsed()
+		bar.AppendFunc(func(b *uiprogress.Bar) string {
+			return fmt.Sprintf("loss: %.6f", t.state.EpochLoss)
+		})
+		uip.Start()
+		defer uip.Stop()
+	}
+
+	data.ForEachBatch(len(indices), t.BatchSize, func(start, end int) {
+		if err != nil || t.state.Stopped {
+			return
+		}
+		t.trainBatch(indices[start:end])
+		if bar != nil {
+			bar.Incr()
+		}
+		if t.EvaluationInterval > 0 && t.state.Step%t.EvaluationInterval == 0 {
+			err = t.evaluate()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if t.optimizer.MicroBatches() > 0 {
+		t.optimize() // the last incomplete accumulation
+	}
+	if t.EvaluationInterval == 0 && !t.state.Stopped {
+		if err := t.evaluate(); err != nil {
+			return err
+		}
+	}
+	t.callEpochEnd()
+	return nil
+}
+
+func (t *Trainer) trainBatch(batch []int) {
+	g := ag.NewGraph(ag.Rand(t.rndGen), ag.ConcurrentComputations(t.ConcurrentComputations))
+	defer g.Clear()
+	proc := nn.ReifyForTraining(t.model, g)
+
+	t.state.Step++
+	t.state.BatchLoss = 0
+	if loss := t.loss(proc, batch); loss != nil {
+		t.optimizer.Backward(g, loss)
+		t.state.BatchLoss = loss.ScalarValue()
+		if t.optimizer.Ready() {
+			t.optimize()
+		}
+	}
+	t.state.epochBatches++
+	t.state.EpochLoss += (t.state.BatchLoss - t.state.EpochLoss) / mat.Float(t.state.epochBatches)
+	t.callBatchEnd()
+}
+
+func (t *Trainer) optimize() {
+	t.optimizer.IncBatch()
+	t.optimizer.IncExample()
+	t.optimizer.Optimize()
+}
+
+// evaluate computes the validation score, saves the model if it is the best
+// so far, and stops the training if it isn't improving anymore.
+func (t *Trainer) evaluate() error {
+	score := t.state.EpochLoss
+	if t.Evaluate != nil {
+		score = t.Evaluate()
+	}
+	t.state.Score = score
+	t.state.Evaluations++
+	if t.improved(score) {
+		t.state.BestScore = score
+		t.state.BestStep = t.state.Step
+		t.state.badEvaluations = 0
+		if t.ModelPath != "" {
+			if err := utils.SerializeToFile(t.ModelPath, t.model); err != nil {
+				return fmt.Errorf("trainer: error during model serialization: %w", err)
+			}
+		}
+	} else {
+		t.state.badEvaluations++
+	}
+	t.callEvaluation()
+	if t.Patience > 0 && t.state.badEvaluations >= t.Patience {
+		t.state.Stop()
+	}
+	return nil
+}
+
+// improved reports whether the score is better than the best one.
+func (t *Trainer) improved(score mat.Float) bool {
+	if t.state.BestStep == 0 {
+		return true
+	}
+	if t.Maximize && t.Evaluate != nil {
+		return score > t.state.BestScore+t.MinDelta
+	}
+	return score < t.state.BestScore-t.MinDelta
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trainer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/averaging"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModel struct {
+	nn.BaseModel
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+// testDataset contains the examples of y = 2x - 1.
+type testDataset struct {
+	xs []mat.Float
+}
+
+func (d *testDataset) Len() int { return len(d.xs) }
+
+func newTestDataset() *testDataset {
+	return &testDataset{xs: []mat.Float{-1, -0.5, 0, 0.25, 0.5, 1, 1.5}}
+}
+
+func newTestTrainer(config Config) (*Trainer, *testModel, *[]int) {
+	return newWrappedTestTrainer(config, func(_ *testModel, o *gd.GradientDescent) gd.Optimizer { return o })
+}
+
+// newWrappedTestTrainer returns a new test Trainer, whose optimizer is the
+// one returned by wrap.
+func newWrappedTestTrainer(config Config, wrap func(m *testModel, o *gd.GradientDescent) gd.Optimizer) (*Trainer, *testModel, *[]int) {
+	m := &testModel{W: nn.NewParam(mat.NewScalar(0)), B: nn.NewParam(mat.NewScalar(0))}
+	dataset := newTestDataset()
+	var seen []int
+	loss := func(proc nn.Model, batch []int) ag.Node {
+		p := proc.(*testModel)
+		g := p.Graph()
+		var loss ag.Node
+		for _, i := range batch {
+			seen = append(seen, i)
+			x := dataset.xs[i]
+			y := g.Add(g.Prod(p.W, g.NewScalar(x)), p.B)
+			loss = g.Add(loss, losses.MSE(g, y, g.NewScalar(2*x-1), false))
+		}
+		return g.DivScalar(loss, g.NewScalar(mat.Float(len(batch))))
+	}
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.2, 0, false)), nn.NewDefaultParamsIterator(m))
+	return New(m, loss, dataset, wrap(m, optimizer), config), m, &seen
+}
+
+func TestTrainer_Train(t *testing.T) {
+	modelPath := filepath.Join(t.TempDir(), "model.bin")
+	var batches, epochs, evaluations int
+	trainer, m, seen := newTestTrainer(Config{
+		Epochs:    30,
+		BatchSize: 3,
+		Shuffle:   true,
+		Seed:      1,
+		ModelPath: modelPath,
+		Callbacks: []Callback{
+			BatchEndFunc(func(s *State) { batches++ }),
+			EpochEndFunc(func(s *State) { epochs++ }),
+			EvaluationFunc(func(s *State) { evaluations++ }),
+		},
+	})
+	require.NoError(t, trainer.Train())
+
+	s := trainer.State()
+	assert.Equal(t, 30, s.Epoch)
+	assert.Equal(t, 90, s.Step)
+	assert.Equal(t, 90, batches)
+	assert.Equal(t, 30, epochs)
+	assert.Equal(t, 30, evaluations)
+	assert.Len(t, *seen, 30*7) // all the examples, in each epoch
+	assert.Less(t, float64(s.BestScore), 1e-3)
+	assert.InDelta(t, 2, m.W.ScalarValue(), 0.05)
+	assert.InDelta(t, -1, m.B.ScalarValue(), 0.05)
+	assert.FileExists(t, modelPath)
+}
+
+func TestTrainer_EarlyStopping(t *testing.T) {
+	scores := []mat.Float{0.5, 0.7, 0.6, 0.65, 0.9}
+	var stoppedAt int
+	trainer, _, _ := newTestTrainer(Config{
+		Epochs:             10,
+		BatchSize:          2,
+		EvaluationInterval: 3,
+		Maximize:           true,
+		Patience:           2,
+		Evaluate: func() mat.Float {
+			score := scores[0]
+			scores = scores[1:]
+			return score
+		},
+		Callbacks: []Callback{
+			EvaluationFunc(func(s *State) { stoppedAt = s.Step }),
+		},
+	})
+	require.NoError(t, trainer.Train())
+
+	s := trainer.State()
+	assert.True(t, s.Stopped)
+	assert.Equal(t, 4, s.Evaluations)
+	assert.Equal(t, mat.Float(0.7), s.BestScore)
+	assert.Equal(t, 6, s.BestStep)
+	assert.Equal(t, 12, stoppedAt)
+	assert.Equal(t, 12, s.Step) // the training stops right after the evaluation
+	assert.Equal(t, 3, s.Epoch)
+}
+
+type stopAtEpoch int
+
+func (e stopAtEpoch) OnEpochBegin(s *State) {
+	if s.Epoch == int(e) {
+		s.Stop()
+	}
+}
+
+func TestTrainer_Stop(t *testing.T) {
+	trainer, _, seen := newTestTrainer(Config{Epochs: 5, BatchSize: 7, Callbacks: []Callback{stopAtEpoch(3)}})
+	require.NoError(t, trainer.Train())
+	assert.Equal(t, 3, trainer.State().Epoch)
+	assert.Equal(t, 2, trainer.State().Step)
+	assert.Len(t, *seen, 14)
+}
+
+func TestTrainer_Averaging(t *testing.T) {
+	var ema *averaging.EMA
+	trainer, _, _ := newWrappedTestTrainer(Config{Epochs: 2, BatchSize: 3}, func(m *testModel, o *gd.GradientDescent) gd.Optimizer {
+		ema = averaging.NewEMA(o, m, 0.9)
+		return ema
+	})
+	require.NoError(t, trainer.Train())
+	assert.Equal(t, 6, trainer.State().Step)
+	assert.Equal(t, 6, ema.Steps, "the optimization goes through the wrapper")
+}
@@ -6,15 +6,17 @@ package charlm
 
 import (
 	"fmt"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
-	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/checkpoint"
 	"github.com/nlpodyssey/spago/pkg/ml/losses"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/gdmbuilder"
 	"github.com/nlpodyssey/spago/pkg/nlp/corpora"
 	"github.com/nlpodyssey/spago/pkg/utils"
+	"log"
 	"runtime"
 )
 
@@ -28,6 +30,10 @@ type TrainingConfig struct {
 	SerializationInterval int
 	UpdateMethod          gd.MethodConfig
 	ModelPath             string
+	// CheckpointPath, if not empty, is the file where the state of the
+	// training is saved along with the model. If the file exists, the
+	// training resumes from it.
+	CheckpointPath string
 }
 
 // Trainer implements the training process for a Character-level Language Model.
@@ -58,7 +64,11 @@ func NewTrainer(config TrainingConfig, corpus corpora.TextCorpusIterator, model
 
 // Train executes the training process.
 func (t *Trainer) Train() {
+	lastLine := t.resume()
 	t.corpus.ForEachLine(func(i int, line string) {
+		if i <= lastLine {
+			return // already trained
+		}
 		t.trainPassage(i, line)
 		// TODO: save the model only if it is better against a validation criterion (yet to be defined)
 		if i > 0 && i%t.SerializationInterval == 0 {
@@ -67,10 +77,37 @@ func (t *Trainer) Train() {
 			if err != nil {
 				panic("charlm: error during model serialization.")
 			}
+			t.saveCheckpoint(i)
 		}
 	})
 }
 
+// resume restores the state of the training from the checkpoint, if any, and
+// returns the index of the last trained line.
+func (t *Trainer) resume() int {
+	c, err := checkpoint.ResumeTraining(t.CheckpointPath, t.model, t.optimizer, t.randGen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if c == nil {
+		return 0
+	}
+	fmt.Printf("=== RESUMING FROM LINE %d\n", c.Counters["line"])
+	return c.Counters["line"]
+}
+
+// saveCheckpoint saves the state of the training, up to the given line.
+func (t *Trainer) saveCheckpoint(line int) {
+	if t.CheckpointPath == "" {
+		return
+	}
+	counters := map[string]int{"line": line}
+	err := checkpoint.SaveTraining(t.CheckpointPath, t.model, t.optimizer, t.randGen, counters)
+	if err != nil {
+		panic(fmt.Sprintf("charlm: error during checkpoint serialization: %v", err))
+	}
+}
+
 func (t *Trainer) trainPassage(index int, text string) {
 	// This is a particular case where computing the forward after the graph definition can be more efficient.
 	g := ag.NewGraph(
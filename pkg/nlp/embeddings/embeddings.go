@@ -7,7 +7,7 @@ package embeddings
 import (
 	"bytes"
 	"encoding/gob"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/nlp/embeddings/syncmap"
@@ -44,6 +44,9 @@ type Config struct {
 	ReadOnly bool
 	// Whether to force the deletion of any existing DB to start with an empty embeddings map.
 	ForceNewDB bool
+	// The numeric type used to store new embeddings (the default is mat.Float).
+	// Half-precision types halve the size of the DB.
+	DType mat.DType
 }
 
 func init() {
@@ -121,7 +124,7 @@ func (m *Model) SetEmbedding(word string, value mat.Matrix) {
 		log.Fatal("embedding: set operation not permitted in read-only mode")
 	}
 
-	embedding := nn.NewParam(value)
+	embedding := nn.NewParam(value, nn.StorageDType(m.DType))
 	embedding.SetPayload(nn.NewPayload())
 
 	buf := new(bytes.Buffer)
@@ -7,8 +7,8 @@ package embeddings
 import (
 	"bufio"
 	"github.com/gosuri/uiprogress"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
-	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/floatutils"
 	"github.com/nlpodyssey/spago/pkg/utils"
 	"log"
 	"os"
@@ -8,7 +8,7 @@ import (
 	"fmt"
 	"github.com/nlpodyssey/gopickle/pytorch"
 	"github.com/nlpodyssey/gopickle/types"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
 	"github.com/nlpodyssey/spago/pkg/nlp/embeddings"
@@ -36,6 +36,13 @@ const defaultHuggingFaceModelFile = "pytorch_model.bin"
 // ConvertHuggingFacePreTrained converts a HuggingFace pre-trained BART
 // transformer model to a corresponding spaGO model.
 func ConvertHuggingFacePreTrained(modelPath string) error {
+	return ConvertHuggingFacePreTrainedWithDType(modelPath, mat.DTypeFloat)
+}
+
+// ConvertHuggingFacePreTrainedWithDType converts a HuggingFace pre-trained BART
+// transformer model to a corresponding spaGO model, whose parameters
+// (including the embeddings) are stored with the given numeric type.
+func ConvertHuggingFacePreTrainedWithDType(modelPath string, dtype mat.DType) error {
 	configFilename, err := exists(path.Join(modelPath, pkgconfig.DefaultConfigurationFile))
 	if err != nil {
 		return err
@@ -71,6 +78,7 @@ func ConvertHuggingFacePreTrained(modelPath string) error {
 		classificationHead:   classification,
 		generationHead:       linear.New(config.DModel, config.VocabSize),
 		modelMapping:         make(map[string]*mappedParam), // lazy initialization
+		dtype:                dtype,
 	}
 	err = handler.convert()
 	if err != nil {
@@ -89,6 +97,7 @@ type huggingFacePreTrainedConverter struct {
 	classificationHead   *sequenceclassification.Classifier
 	generationHead       *linear.Model
 	modelMapping         map[string]*mappedParam
+	dtype                mat.DType
 }
 
 type mappedParam struct {
@@ -109,6 +118,7 @@ func (c *huggingFacePreTrainedConverter) convert() error {
 	pyTorchParams := c.extractHuggingFaceParams()
 
 	log.Printf("Convert embeddings... ")
+	c.model.Embeddings.DType = c.dtype
 	dumpWordEmbeddings(pyTorchParams["model.shared.weight"], c.model.Embeddings, c.model.Config.VocabSize)
 	log.Printf("Ok\n")
 
@@ -195,6 +205,7 @@ func (c *huggingFacePreTrainedConverter) serializeModel() error {
 		}
 	}
 
+	nn.SetParamsDType(model, c.dtype)
 	err := utils.SerializeToFile(c.modelFilename, model)
 	if err != nil {
 		return fmt.Errorf("bert: error during model serialization: %w", err)
@@ -327,7 +338,7 @@ func (c *huggingFacePreTrainedConverter) extractHuggingFaceParams() map[string][
 		paramName := normalizeParamName(key.(string))
 		fmt.Printf("Reading %s.... ", paramName)
 		switch t.Source.(type) {
-		case *pytorch.FloatStorage:
+		case *pytorch.FloatStorage, *pytorch.HalfStorage:
 			paramsMap[paramName] = gopickleutils.GetData(t)
 			fmt.Println("ok")
 		default:
@@ -5,13 +5,13 @@
 package bert
 
 import (
-	matsort "github.com/nlpodyssey/spago/pkg/mat32/sort"
+	matsort "github.com/nlpodyssey/spago/pkg/mat/sort"
 	"runtime"
 	"sort"
 	"strings"
 
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
-	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/floatutils"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
@@ -8,7 +8,7 @@ import (
 	"fmt"
 	"github.com/nlpodyssey/gopickle/pytorch"
 	"github.com/nlpodyssey/gopickle/types"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/normalization/layernorm"
@@ -29,6 +29,13 @@ const defaultHuggingFaceModelFile = "pytorch_model.bin"
 // ConvertHuggingFacePreTrained converts a HuggingFace pre-trained BERT
 // transformer model to a corresponding spaGO model.
 func ConvertHuggingFacePreTrained(modelPath string) error {
+	return ConvertHuggingFacePreTrainedWithDType(modelPath, mat.DTypeFloat)
+}
+
+// ConvertHuggingFacePreTrainedWithDType converts a HuggingFace pre-trained BERT
+// transformer model to a corresponding spaGO model, whose parameters
+// (including the embeddings) are stored with the given numeric type.
+func ConvertHuggingFacePreTrainedWithDType(modelPath string, dtype mat.DType) error {
 	configFilename, err := exists(path.Join(modelPath, DefaultConfigurationFile))
 	if err != nil {
 		return err
@@ -64,6 +71,7 @@ func ConvertHuggingFacePreTrained(modelPath string) error {
 		modelFilename:        path.Join(modelPath, DefaultModelFile),
 		model:                model,
 		modelMapping:         make(map[string]*mappedParam), // lazy initialization
+		dtype:                dtype,
 	}
 	err = handler.convert()
 	if err != nil {
@@ -81,6 +89,7 @@ type huggingFacePreTrainedConverter struct {
 	modelFilename        string
 	model                *Model
 	modelMapping         map[string]*mappedParam
+	dtype                mat.DType
 }
 
 type mappedParam struct {
@@ -101,6 +110,7 @@ func (c *huggingFacePreTrainedConverter) convert() error {
 	pyTorchParams := c.extractHuggingFaceParams()
 
 	log.Printf("Convert word/positional/type embeddings...")
+	c.model.Embeddings.Words.DType = c.dtype
 	c.convertEmbeddings(pyTorchParams)
 
 	log.Printf("Create model mapping...")
@@ -140,6 +150,7 @@ func (c *huggingFacePreTrainedConverter) convert() error {
 }
 
 func (c *huggingFacePreTrainedConverter) serializeModel() error {
+	nn.SetParamsDType(c.model, c.dtype)
 	err := utils.SerializeToFile(c.modelFilename, c.model)
 	if err != nil {
 		return fmt.Errorf("bert: error during model serialization: %w", err)
@@ -160,7 +171,7 @@ func (c *huggingFacePreTrainedConverter) extractHuggingFaceParams() map[string][
 		paramName := normalizeParamName(key.(string))
 		fmt.Printf("Reading %s.... ", paramName)
 		switch t.Source.(type) {
-		case *pytorch.FloatStorage:
+		case *pytorch.FloatStorage, *pytorch.HalfStorage:
 			paramsMap[paramName] = gopickleutils.GetData(t)
 			fmt.Println("ok")
 		default:
@@ -9,9 +9,10 @@ import (
 	"bufio"
 	"compress/gzip"
 	"fmt"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
-	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/checkpoint"
 	"github.com/nlpodyssey/spago/pkg/ml/losses"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
@@ -33,6 +34,10 @@ type TrainingConfig struct {
 	UpdateMethod     gd.MethodConfig
 	CorpusPath       string
 	ModelPath        string
+	// CheckpointPath, if not empty, is the file where the state of the
+	// training is saved along with the model. If the file exists, the
+	// training resumes from it.
+	CheckpointPath string
 }
 
 // Trainer implements the training process for a BERT Model.
@@ -62,7 +67,11 @@ func NewTrainer(model *Model, config TrainingConfig) *Trainer {
 
 // Train executes the training process.
 func (t *Trainer) Train() {
+	lastLine := t.resume()
 	t.forEachLine(func(i int, text string) {
+		if i <= lastLine {
+			return // already trained
+		}
 		t.trainPassage(text)
 		t.optimizer.IncBatch()
 		t.optimizer.IncExample()
@@ -74,12 +83,40 @@ func (t *Trainer) Train() {
 			if err != nil {
 				panic("bert: error during model serialization.")
 			}
+			t.saveCheckpoint(i)
 		}
 
 		t.countLine++
 	})
 }
 
+// resume restores the state of the training from the checkpoint, if any, and
+// returns the index of the last trained line.
+func (t *Trainer) resume() int {
+	c, err := checkpoint.ResumeTraining(t.CheckpointPath, t.model, t.optimizer, t.randGen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if c == nil {
+		return 0
+	}
+	t.countLine = c.Counters["count"]
+	fmt.Printf("=== RESUMING FROM LINE %d\n", c.Counters["line"])
+	return c.Counters["line"]
+}
+
+// saveCheckpoint saves the state of the training, up to the given line.
+func (t *Trainer) saveCheckpoint(line int) {
+	if t.CheckpointPath == "" {
+		return
+	}
+	counters := map[string]int{"line": line, "count": t.countLine + 1}
+	err := checkpoint.SaveTraining(t.CheckpointPath, t.model, t.optimizer, t.randGen, counters)
+	if err != nil {
+		panic(fmt.Sprintf("bert: error during checkpoint serialization: %v", err))
+	}
+}
+
 func (t *Trainer) tokenize(text string) []string {
 	tokenizer := wordpiecetokenizer.New(t.model.Vocabulary)
 	tokenized := append(tokenizers.GetStrings(tokenizer.Tokenize(text)), wordpiecetokenizer.DefaultSequenceSeparator)
@@ -6,6 +6,7 @@ package huggingface
 
 import (
 	"fmt"
+	"github.com/nlpodyssey/spago/pkg/mat"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/converter"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
 	"path"
@@ -23,16 +24,26 @@ type Converter struct {
 	modelName string
 	// Full path of the model configuration file.
 	configFilename string
+	// The numeric type used to store the parameters of the converted model.
+	dtype mat.DType
 }
 
 // NewConverter creates a new Converter.
 func NewConverter(modelsPath, modelName string) *Converter {
+	return NewConverterWithDType(modelsPath, modelName, mat.DTypeFloat)
+}
+
+// NewConverterWithDType creates a new Converter, which stores the parameters
+// of the converted model with the given numeric type. Half-precision types
+// (mat.DTypeFloat16 and mat.DTypeBFloat16) halve the size of the model.
+func NewConverterWithDType(modelsPath, modelName string, dtype mat.DType) *Converter {
 	modelPath := filepath.Join(modelsPath, modelName)
 	return &Converter{
 		modelsPath:     modelsPath,
 		modelPath:      modelPath,
 		modelName:      modelName,
 		configFilename: path.Join(modelPath, ModelConfigFilename),
+		dtype:          dtype,
 	}
 }
 
@@ -45,12 +56,12 @@ func (c *Converter) Convert() error {
 
 	switch config.ModelType {
 	case "bart", "marian":
-		return converter.ConvertHuggingFacePreTrained(c.modelPath)
+		return converter.ConvertHuggingFacePreTrainedWithDType(c.modelPath, c.dtype)
 	case "bert", "electra":
-		return bert.ConvertHuggingFacePreTrained(c.modelPath)
+		return bert.ConvertHuggingFacePreTrainedWithDType(c.modelPath, c.dtype)
 	case "":
 		fmt.Println("model type empty; assuming it is BERT.")
-		return bert.ConvertHuggingFacePreTrained(c.modelPath)
+		return bert.ConvertHuggingFacePreTrainedWithDType(c.modelPath, c.dtype)
 	default:
 		return fmt.Errorf("unsupported model type: `%s`", config.ModelType)
 	}
@@ -5,8 +5,8 @@
 package data
 
 import (
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
-	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/mat"
+	"github.com/nlpodyssey/spago/pkg/mat/rand"
 	"github.com/nlpodyssey/spago/pkg/utils"
 )
 
@@ -47,10 +47,11 @@ func GenerateBatches(size, batchFactor int, class func(i int) int) [][]int {
 }
 
 // ForEachBatch divides the dataset into batches, returning the start-end of each batch with a callback.
+// The end is exclusive, so that the batch is the range [start, end).
 // This function assumes that the dataset has already been shuffled.
 func ForEachBatch(datasetSize, batchSize int, callback func(start, end int)) {
 	for start := 0; start < datasetSize; start += batchSize {
-		end := utils.MinInt(start+batchSize, datasetSize-1)
+		end := utils.MinInt(start+batchSize, datasetSize)
 		callback(start, end)
 	}
 }
@@ -5,11 +5,13 @@
 package gopickleutils
 
 import (
+	"fmt"
 	"github.com/nlpodyssey/gopickle/pytorch"
-	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat"
 )
 
 // GetData returns the data of a PyTorch tensor as a mat.Float slice.
+// Both single and half-precision float storages are supported.
 // It returns the data using the row-major representation, possibly converting column-major order to row-major order.
 func GetData(t *pytorch.Tensor) []mat.Float {
 	if len(t.Size) == 0 || len(t.Size) > 2 {
@@ -19,7 +21,15 @@ func GetData(t *pytorch.Tensor) []mat.Float {
 	if len(t.Size) > 1 {
 		size *= t.Size[1]
 	}
-	orig := t.Source.(*pytorch.FloatStorage).Data[t.StorageOffset : t.StorageOffset+size]
+	var orig []float32
+	switch source := t.Source.(type) {
+	case *pytorch.FloatStorage:
+		orig = source.Data[t.StorageOffset : t.StorageOffset+size]
+	case *pytorch.HalfStorage:
+		orig = source.Data[t.StorageOffset : t.StorageOffset+size]
+	default:
+		panic(fmt.Sprintf("gopickleutils: unsupported storage type %T", source))
+	}
 	data := make([]mat.Float, len(orig))
 
 	if len(t.Size) == 1 || t.Size[0] == 1 || t.Size[1] == 1 || t.Stride[1] == 1 {